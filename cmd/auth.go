@@ -0,0 +1,69 @@
+package cmd
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"os/signal"
+	"syscall"
+
+	"github.com/jjenkins/usds/internal/auth/oidctest"
+	"github.com/spf13/cobra"
+)
+
+var mockProviderPort string
+var mockProviderClientID string
+var mockProviderClientSecret string
+var mockProviderUser oidctest.User
+
+var authCmd = &cobra.Command{
+	Use:   "auth",
+	Short: "Authentication tools",
+}
+
+var mockProviderCmd = &cobra.Command{
+	Use:   "mock-provider",
+	Short: "Run a local mock OIDC provider for development",
+	Long: `Mock-provider runs a minimal OIDC provider that signs every login in as
+the user given by the flags, without a login page. Point "usds serve" at it to
+try sign in and each role locally.
+
+Examples:
+  # Sign in as an admin
+  ./usds auth mock-provider --roles admin
+
+  # In another terminal, using the environment the provider prints
+  OIDC_ISSUER_URL=http://127.0.0.1:9998 OIDC_CLIENT_ID=usds OIDC_CLIENT_SECRET=secret \
+  OIDC_REDIRECT_URL=http://localhost:8080/auth/callback \
+  SESSION_SECRET=0123456789abcdef0123456789abcdef ./usds serve`,
+	Args: cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		provider, err := oidctest.NewProvider("127.0.0.1:"+mockProviderPort, mockProviderClientID, mockProviderClientSecret, mockProviderUser)
+		if err != nil {
+			log.Fatalf("Failed to start mock provider: %v", err)
+		}
+		defer provider.Close()
+
+		fmt.Printf("Mock OIDC provider signing in %q with roles %v\n\n", mockProviderUser.Subject, mockProviderUser.Roles)
+		fmt.Printf("OIDC_ISSUER_URL=%s\n", provider.Issuer())
+		fmt.Printf("OIDC_CLIENT_ID=%s\n", mockProviderClientID)
+		fmt.Printf("OIDC_CLIENT_SECRET=%s\n", mockProviderClientSecret)
+
+		sigChan := make(chan os.Signal, 1)
+		signal.Notify(sigChan, syscall.SIGINT, syscall.SIGTERM)
+		<-sigChan
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(authCmd)
+	authCmd.AddCommand(mockProviderCmd)
+
+	mockProviderCmd.Flags().StringVarP(&mockProviderPort, "port", "p", "9998", "Port to listen on")
+	mockProviderCmd.Flags().StringVar(&mockProviderClientID, "client-id", "usds", "Client ID to accept")
+	mockProviderCmd.Flags().StringVar(&mockProviderClientSecret, "client-secret", "secret", "Client secret to accept")
+	mockProviderCmd.Flags().StringVar(&mockProviderUser.Subject, "sub", "dev-user", "Subject of the signed-in user")
+	mockProviderCmd.Flags().StringVar(&mockProviderUser.Email, "email", "dev@example.gov", "Email of the signed-in user")
+	mockProviderCmd.Flags().StringVar(&mockProviderUser.Name, "name", "Dev User", "Name of the signed-in user")
+	mockProviderCmd.Flags().StringSliceVar(&mockProviderUser.Roles, "roles", []string{"viewer"}, "Roles claim of the signed-in user")
+}
@@ -0,0 +1,178 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"os"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/jjenkins/usds/internal/service"
+	"github.com/jjenkins/usds/internal/store"
+	"github.com/spf13/cobra"
+)
+
+var compareAgencies string
+var compareAsOf string
+
+var compareCmd = &cobra.Command{
+	Use:   "compare [slug...]",
+	Short: "Compare several agencies side by side",
+	Long: `Compare prints the same side-by-side view as /compare in the web UI:
+word count, regulation count, density score, readability, word count by
+snapshot, overlapping titles and the chapters each agency owns.
+
+Examples:
+  # Compare EPA and DOT
+  ./usds compare environmental-protection-agency transportation-department
+
+  # Same, using the web UI's comma-separated form
+  ./usds compare --agencies environmental-protection-agency,transportation-department
+
+  # Compare agencies as they stood on a past date
+  ./usds compare --as-of 2020-01-01 environmental-protection-agency transportation-department`,
+	Run: runCompare,
+}
+
+func init() {
+	rootCmd.AddCommand(compareCmd)
+
+	compareCmd.Flags().StringVar(&compareAgencies, "agencies", "", "Comma-separated agency slugs to compare")
+	compareCmd.Flags().StringVar(&compareAsOf, "as-of", "", "Compare using the latest snapshots on or before this date (YYYY-MM-DD)")
+}
+
+func runCompare(cmd *cobra.Command, args []string) {
+	dbURL := cfg.Database.URL
+	if dbURL == "" {
+		log.Fatal("DATABASE_URL environment variable or database.url setting is required")
+	}
+
+	slugs := service.ParseAgencySlugs(append([]string{compareAgencies}, args...)...)
+	if len(slugs) == 0 {
+		log.Fatal("At least one agency slug is required")
+	}
+
+	var asOf time.Time
+	if compareAsOf != "" {
+		var err error
+		asOf, err = time.Parse("2006-01-02", compareAsOf)
+		if err != nil {
+			log.Fatalf("Invalid --as-of date: %v", err)
+		}
+	}
+
+	db, err := store.NewDB(dbURL)
+	if err != nil {
+		log.Fatalf("Failed to connect to database: %v", err)
+	}
+	defer db.Close()
+
+	agencyStore := store.NewAgencyStore(db).AsOf(asOf)
+
+	comparison, err := service.CompareAgencies(context.Background(), agencyStore, slugs)
+	if err != nil {
+		log.Fatalf("Comparison failed: %v", err)
+	}
+
+	for _, slug := range comparison.NotFound {
+		log.Printf("Agency %s not found", slug)
+	}
+	if len(comparison.Agencies) == 0 {
+		os.Exit(1)
+	}
+
+	printComparison(comparison)
+}
+
+func printComparison(comparison *service.AgencyComparison) {
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+
+	header := []string{"METRIC"}
+	for _, a := range comparison.Agencies {
+		header = append(header, a.Agency.Slug)
+	}
+	fmt.Fprintln(w, strings.Join(header, "\t"))
+
+	row := func(label string, value func(service.ComparedAgency) string) {
+		cells := []string{label}
+		for _, a := range comparison.Agencies {
+			cells = append(cells, value(a))
+		}
+		fmt.Fprintln(w, strings.Join(cells, "\t"))
+	}
+
+	row("Word count", func(a service.ComparedAgency) string {
+		return fmt.Sprintf("%d", a.Agency.TotalWordCount)
+	})
+	row("Regulation count", func(a service.ComparedAgency) string {
+		return fmt.Sprintf("%d", a.Agency.RegulationCount)
+	})
+	row("Words/title", func(a service.ComparedAgency) string {
+		if a.Agency.RegulationCount == 0 {
+			return "--"
+		}
+		return fmt.Sprintf("%.0f", a.WordsPerTitle())
+	})
+	row("Density score", func(a service.ComparedAgency) string {
+		if !a.HasDensity {
+			return "--"
+		}
+		return fmt.Sprintf("%.2f", a.DensityScore)
+	})
+	row("Readability", func(a service.ComparedAgency) string {
+		if !a.Readability.Valid {
+			return "--"
+		}
+		return fmt.Sprintf("%.1f", a.Readability.Float64)
+	})
+	row("Change since first", func(a service.ComparedAgency) string {
+		change, ok := a.WordCountChange()
+		if !ok {
+			return "--"
+		}
+		return fmt.Sprintf("%+d", change)
+	})
+
+	if len(comparison.SnapshotDates) > 0 {
+		fmt.Fprintln(w)
+		dateHeader := []string{"SNAPSHOT"}
+		for _, a := range comparison.Agencies {
+			dateHeader = append(dateHeader, a.Agency.Slug)
+		}
+		fmt.Fprintln(w, strings.Join(dateHeader, "\t"))
+		for _, date := range comparison.SnapshotDates {
+			row(date.Format("2006-01-02"), func(a service.ComparedAgency) string {
+				snap, ok := a.SnapshotOn(date)
+				if !ok {
+					return "--"
+				}
+				return fmt.Sprintf("%d", snap.TotalWordCount)
+			})
+		}
+	}
+	w.Flush()
+
+	fmt.Println()
+	fmt.Println("Overlapping titles:")
+	if len(comparison.SharedTitles) == 0 {
+		fmt.Println("  none")
+	}
+	for _, shared := range comparison.SharedTitles {
+		fmt.Printf("  Title %d %s: %s\n", shared.TitleNumber, shared.TitleName, strings.Join(shared.Slugs, ", "))
+	}
+
+	fmt.Println()
+	fmt.Println("Chapters owned:")
+	for _, a := range comparison.Agencies {
+		var chapters []string
+		for _, ref := range a.Chapters {
+			chapters = append(chapters, fmt.Sprintf("%d/%s", ref.Title, ref.Chapter))
+		}
+		if len(chapters) == 0 {
+			chapters = []string{"none"}
+		}
+		fmt.Printf("  %s: %s\n", a.Agency.Slug, strings.Join(chapters, ", "))
+	}
+}
@@ -0,0 +1,137 @@
+package cmd
+
+import (
+	"context"
+	"log"
+	"log/slog"
+	"net/http"
+	"os"
+	"os/signal"
+	"syscall"
+
+	"github.com/jjenkins/usds/internal/service"
+	"github.com/jjenkins/usds/internal/store"
+	"github.com/jjenkins/usds/internal/telemetry"
+	"github.com/spf13/cobra"
+)
+
+var daemonSchedule string
+var daemonRunNow bool
+var daemonFull bool
+var daemonMetricsAddr string
+
+var daemonCmd = &cobra.Command{
+	Use:   "daemon",
+	Short: "Run imports on a schedule",
+	Long: `Daemon runs imports on a cron schedule until stopped, replacing an external
+cron wrapper around "usds import".
+
+Each run imports today's titles and agencies, recalculates system metrics and
+delivers webhooks. Runs are incremental by default: titles the eCFR lists as
+not amended since the last import are skipped. Every import takes the same
+database lock, so only one replica imports at a time; the others skip that
+run, as does a replica that finds "usds import" or an admin page importing.
+
+On SIGINT or SIGTERM a running import stops after the title in progress and
+the lock is released. A second signal exits immediately.
+
+The schedule is a standard five-field cron expression (minute hour day month
+weekday) or a descriptor such as @daily or @every 6h, and may start with
+CRON_TZ=<zone>. It defaults to the import.schedule setting or IMPORT_SCHEDULE.
+
+Examples:
+  # Import every day at 06:00 UTC
+  ./usds daemon --schedule "0 6 * * *"
+
+  # Import every 6 hours in US Eastern time, starting with an import now
+  ./usds daemon --schedule "CRON_TZ=America/New_York 0 */6 * * *" --run-now
+
+  # Re-fetch every title on each run
+  ./usds daemon --full
+
+  # Expose eCFR fetch metrics for Prometheus at http://localhost:9090/metrics
+  ./usds daemon --metrics-addr :9090`,
+	Args: cobra.NoArgs,
+	Run:  runDaemon,
+}
+
+func init() {
+	rootCmd.AddCommand(daemonCmd)
+
+	daemonCmd.Flags().StringVarP(&daemonSchedule, "schedule", "s", "0 6 * * *", "Cron expression for import runs")
+	bindFlag(daemonCmd.Flags(), "schedule", "import.schedule")
+	daemonCmd.Flags().BoolVar(&daemonRunNow, "run-now", false, "Run an import at startup as well as on schedule")
+	daemonCmd.Flags().BoolVar(&daemonFull, "full", false, "Re-fetch every title instead of only amended ones")
+	daemonCmd.Flags().StringVar(&daemonMetricsAddr, "metrics-addr", "", "Address to serve Prometheus metrics on (disabled if empty)")
+}
+
+func runDaemon(cmd *cobra.Command, args []string) {
+	dbURL := cfg.Database.URL
+	if dbURL == "" {
+		log.Fatal("DATABASE_URL environment variable or database.url setting is required")
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	// First signal stops gracefully, second exits at once
+	sigChan := make(chan os.Signal, 2)
+	signal.Notify(sigChan, syscall.SIGINT, syscall.SIGTERM)
+	go func() {
+		<-sigChan
+		slog.Info("Received shutdown signal, stopping after the current title")
+		cancel()
+		<-sigChan
+		slog.Warn("Received second signal, exiting immediately")
+		os.Exit(1)
+	}()
+
+	db, err := store.NewDB(dbURL)
+	if err != nil {
+		log.Fatalf("Failed to connect to database: %v", err)
+	}
+	defer db.Close()
+
+	importer := service.NewImporter(
+		service.NewECFRClient(cfg.ECFR),
+		service.NewParser(),
+		store.NewTitleStore(db),
+		store.NewAgencyStore(db),
+		store.NewSectionStore(db),
+		store.NewQuarantineStore(db),
+	)
+	importer.SetMaxWordDrop(cfg.Import.MaxWordDrop)
+	dispatcher := service.NewWebhookDispatcher(store.NewWebhookStore(db), cfg.Server.PublicURL)
+
+	job := service.NewImportJob(db, importer, service.NewMetricsService(db), dispatcher, store.NewImportRunStore(db), store.NewDataVersionStore(db))
+
+	scheduler, err := service.NewImportScheduler(job, cfg.Import.Schedule, !daemonFull)
+	if err != nil {
+		log.Fatalf("Failed to start daemon: %v", err)
+	}
+
+	if daemonMetricsAddr != "" {
+		telemetry.RegisterDB(db)
+		telemetry.RegisterImports(store.NewImportRunStore(db), store.NewTitleStore(db))
+
+		mux := http.NewServeMux()
+		mux.Handle("/metrics", telemetry.HTTPHandler())
+		go func() {
+			slog.Info("Serving metrics", "addr", daemonMetricsAddr, "path", "/metrics")
+			if err := http.ListenAndServe(daemonMetricsAddr, mux); err != nil {
+				log.Fatalf("Failed to serve metrics: %v", err)
+			}
+		}()
+	}
+
+	slog.Info("Import daemon started", "schedule", cfg.Import.Schedule)
+
+	if daemonRunNow {
+		if err := scheduler.RunOnce(ctx); err != nil && ctx.Err() == nil {
+			slog.Error("Import failed", "error", err)
+		}
+	}
+
+	scheduler.Run(ctx)
+	slog.Info("Import daemon stopped")
+}
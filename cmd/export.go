@@ -0,0 +1,153 @@
+package cmd
+
+import (
+	"context"
+	"io"
+	"log"
+	"os"
+
+	"github.com/jjenkins/usds/internal/export"
+	"github.com/jjenkins/usds/internal/store"
+	"github.com/spf13/cobra"
+)
+
+var exportTable string
+var exportFormat string
+var exportSort string
+var exportOrder string
+var exportTitleNumber int
+var exportAgencySlug string
+var exportOutput string
+
+var exportCmd = &cobra.Command{
+	Use:   "export",
+	Short: "Export titles, agencies or snapshot history to CSV, XLSX or Parquet",
+	Long: `Export writes the same files as the ?format= download links in the web UI.
+
+Tables:
+  titles            All titles with density scores
+  agencies          All agencies with density scores
+  title-snapshots   Snapshot history for one title (requires --title)
+  agency-snapshots  Snapshot history for one agency (requires --agency)
+
+Examples:
+  # Export titles sorted by word count as a spreadsheet
+  ./usds export --table titles --format xlsx --sort word_count --order desc
+
+  # Export an agency's history as Parquet
+  ./usds export --table agency-snapshots --agency environmental-protection-agency --format parquet
+
+  # Write CSV to stdout
+  ./usds export --table agencies -o -`,
+	Run: runExport,
+}
+
+func init() {
+	rootCmd.AddCommand(exportCmd)
+
+	exportCmd.Flags().StringVar(&exportTable, "table", "titles", "Table to export (titles, agencies, title-snapshots, agency-snapshots)")
+	exportCmd.Flags().StringVarP(&exportFormat, "format", "f", "csv", "Output format (csv, xlsx, parquet)")
+	exportCmd.Flags().StringVar(&exportSort, "sort", "", "Sort column, as in the web UI (defaults to number for titles, name for agencies)")
+	exportCmd.Flags().StringVar(&exportOrder, "order", "asc", "Sort order (asc, desc)")
+	exportCmd.Flags().IntVarP(&exportTitleNumber, "title", "t", 0, "Title number for title-snapshots")
+	exportCmd.Flags().StringVar(&exportAgencySlug, "agency", "", "Agency slug for agency-snapshots")
+	exportCmd.Flags().StringVarP(&exportOutput, "output", "o", "", "Output file, or - for stdout (defaults to <table>.<format>)")
+}
+
+func runExport(cmd *cobra.Command, args []string) {
+	dbURL := cfg.Database.URL
+	if dbURL == "" {
+		log.Fatal("DATABASE_URL environment variable or database.url setting is required")
+	}
+
+	format, err := export.ParseFormat(exportFormat)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	db, err := store.NewDB(dbURL)
+	if err != nil {
+		log.Fatalf("Failed to connect to database: %v", err)
+	}
+	defer db.Close()
+
+	ctx := context.Background()
+	titleStore := store.NewTitleStore(db)
+	agencyStore := store.NewAgencyStore(db)
+
+	var table *export.Table
+	switch exportTable {
+	case "titles":
+		if exportSort == "" {
+			exportSort = "number"
+		}
+		titles, err := titleStore.GetAllSortedWithDensity(ctx, exportSort, exportOrder)
+		if err != nil {
+			log.Fatalf("Failed to load titles: %v", err)
+		}
+		table = export.Titles(titles)
+
+	case "agencies":
+		if exportSort == "" {
+			exportSort = "name"
+		}
+		agencies, err := agencyStore.GetAllSorted(ctx, exportSort, exportOrder)
+		if err != nil {
+			log.Fatalf("Failed to load agencies: %v", err)
+		}
+		table = export.Agencies(agencies)
+
+	case "title-snapshots":
+		if exportTitleNumber <= 0 {
+			log.Fatal("--title is required for title-snapshots")
+		}
+		snapshots, err := titleStore.GetSnapshots(ctx, exportTitleNumber)
+		if err != nil {
+			log.Fatalf("Failed to load snapshots: %v", err)
+		}
+		table = export.TitleSnapshots(exportTitleNumber, snapshots)
+
+	case "agency-snapshots":
+		if exportAgencySlug == "" {
+			log.Fatal("--agency is required for agency-snapshots")
+		}
+		agency, err := agencyStore.GetBySlug(ctx, exportAgencySlug)
+		if err != nil {
+			log.Fatalf("Failed to load agency: %v", err)
+		}
+		if agency == nil {
+			log.Fatalf("Agency %s not found", exportAgencySlug)
+		}
+		snapshots, err := agencyStore.GetSnapshotsForAgency(ctx, agency.ID)
+		if err != nil {
+			log.Fatalf("Failed to load snapshots: %v", err)
+		}
+		table = export.AgencySnapshots(agency.Slug, snapshots)
+
+	default:
+		log.Fatalf("Unknown table %q", exportTable)
+	}
+
+	output := exportOutput
+	if output == "" {
+		output = table.Filename(format)
+	}
+
+	var w io.Writer = os.Stdout
+	if output != "-" {
+		f, err := os.Create(output)
+		if err != nil {
+			log.Fatalf("Failed to create %s: %v", output, err)
+		}
+		defer f.Close()
+		w = f
+	}
+
+	if err := export.Write(w, format, table); err != nil {
+		log.Fatalf("Export failed: %v", err)
+	}
+
+	if output != "-" {
+		log.Printf("Wrote %d rows to %s", len(table.Rows), output)
+	}
+}
@@ -2,12 +2,16 @@ package cmd
 
 import (
 	"context"
+	"fmt"
 	"log"
+	"log/slog"
 	"os"
 	"os/signal"
 	"syscall"
+	"text/tabwriter"
 	"time"
 
+	"github.com/jjenkins/usds/internal/model"
 	"github.com/jjenkins/usds/internal/service"
 	"github.com/jjenkins/usds/internal/store"
 	"github.com/spf13/cobra"
@@ -16,6 +20,15 @@ import (
 var importDate string
 var importAllHistory bool
 var importTitleNumber int
+var importIncremental bool
+var importReindex bool
+var importFrom string
+var importTo string
+var historyLimit int
+var historySource string
+var historyStatus string
+var historyFailures bool
+var historyTitleNumber int
 
 var importCmd = &cobra.Command{
 	Use:   "import",
@@ -36,11 +49,50 @@ Examples:
   # Import a single title
   ./usds import --title 40 --date 2020-01-01
 
+  # Import only titles amended since the last import
+  ./usds import --incremental
+
+  # Import every version published in a date range, optionally for one title
+  ./usds import --from 2024-01-01 --to 2024-06-30 --title 40
+
   # Import all historical versions (WARNING: this takes a long time!)
-  ./usds import --all-history`,
+  ./usds import --all-history
+
+  # Re-fetch and store the sections of latest snapshots that have none, such
+  # as those whose sections failed to save
+  ./usds import --reindex
+
+Re-importing a date or range recounts its existing snapshots, so after an
+upgrade changes how words are counted, --all-history (or --from/--to for
+the range charted) brings older snapshots onto the new rules.
+
+Each run and its failures are recorded; list them with "usds import history".`,
 	Run: runImport,
 }
 
+var importHistoryCmd = &cobra.Command{
+	Use:   "history [run-id]",
+	Short: "List past import runs and their failures",
+	Long: `History lists recorded import runs from the command line, the admin pages
+and the daemon, newest first. Given a run ID it shows that run's counts and
+every title, version or agency it failed to import.
+
+Examples:
+  # List the last 20 runs
+  ./usds import history
+
+  # List failed daemon runs
+  ./usds import history --source daemon --status failed
+
+  # Show run 42 and its failures
+  ./usds import history 42
+
+  # List recent failures for Title 40 across runs
+  ./usds import history --failures --title 40`,
+	Args: cobra.MaximumNArgs(1),
+	Run:  runImportHistory,
+}
+
 func init() {
 	rootCmd.AddCommand(importCmd)
 
@@ -48,13 +100,24 @@ func init() {
 	importCmd.Flags().StringVarP(&importDate, "date", "d", today, "Date to import data for (YYYY-MM-DD)")
 	importCmd.Flags().IntVarP(&importTitleNumber, "title", "t", 0, "Import only a specific title number (1-50)")
 	importCmd.Flags().BoolVar(&importAllHistory, "all-history", false, "Import all historical versions for all titles")
+	importCmd.Flags().BoolVar(&importIncremental, "incremental", false, "Import only titles amended since the last import")
+	importCmd.Flags().BoolVar(&importReindex, "reindex", false, "Store sections for latest snapshots that have none")
+	importCmd.Flags().StringVar(&importFrom, "from", "", "Import versions published on or after this date (YYYY-MM-DD)")
+	importCmd.Flags().StringVar(&importTo, "to", "", "Import versions published on or before this date (YYYY-MM-DD)")
+
+	importCmd.AddCommand(importHistoryCmd)
+	importHistoryCmd.Flags().IntVarP(&historyLimit, "limit", "n", 20, "Maximum number of runs or failures to list")
+	importHistoryCmd.Flags().StringVar(&historySource, "source", "", "Only list runs from this source (cli, web or daemon)")
+	importHistoryCmd.Flags().StringVar(&historyStatus, "status", "", "Only list runs with this status (running, succeeded, failed or cancelled)")
+	importHistoryCmd.Flags().BoolVar(&historyFailures, "failures", false, "List failures across runs instead of runs")
+	importHistoryCmd.Flags().IntVarP(&historyTitleNumber, "title", "t", 0, "Only list failures for this title number")
 }
 
 func runImport(cmd *cobra.Command, args []string) {
 	// Get database URL from environment
-	dbURL := os.Getenv("DATABASE_URL")
+	dbURL := cfg.Database.URL
 	if dbURL == "" {
-		log.Fatal("DATABASE_URL environment variable is required")
+		log.Fatal("DATABASE_URL environment variable or database.url setting is required")
 	}
 
 	// Set up context with cancellation
@@ -66,12 +129,12 @@ func runImport(cmd *cobra.Command, args []string) {
 	signal.Notify(sigChan, syscall.SIGINT, syscall.SIGTERM)
 	go func() {
 		<-sigChan
-		log.Println("\nReceived interrupt signal, shutting down...")
+		slog.Info("Received interrupt signal, shutting down")
 		cancel()
 	}()
 
 	// Connect to database
-	log.Println("Connecting to database...")
+	slog.Info("Connecting to database")
 	db, err := store.NewDB(dbURL)
 	if err != nil {
 		log.Fatalf("Failed to connect to database: %v", err)
@@ -79,103 +142,189 @@ func runImport(cmd *cobra.Command, args []string) {
 	defer db.Close()
 
 	// Create dependencies
-	client := service.NewECFRClient()
+	client := service.NewECFRClient(cfg.ECFR)
 	parser := service.NewParser()
 	titleStore := store.NewTitleStore(db)
 	agencyStore := store.NewAgencyStore(db)
-	importer := service.NewImporter(client, parser, titleStore, agencyStore)
+	sectionStore := store.NewSectionStore(db)
+	importer := service.NewImporter(client, parser, titleStore, agencyStore, sectionStore, store.NewQuarantineStore(db))
+	importer.SetMaxWordDrop(cfg.Import.MaxWordDrop)
+	dispatcher := service.NewWebhookDispatcher(store.NewWebhookStore(db), cfg.Server.PublicURL)
+	job := service.NewImportJob(db, importer, service.NewMetricsService(db), dispatcher, store.NewImportRunStore(db), store.NewDataVersionStore(db))
+
+	req, err := importRequestFromFlags()
+	if err != nil {
+		log.Fatalf("Invalid import: %v", err)
+	}
+
+	if req.Mode == model.ImportModeHistory {
+		slog.Warn("Importing every historical version of every title, this will take a very long time (potentially hours)")
+	}
+
+	run, err := job.Start(ctx, req, model.ImportSourceCLI, importStartedBy())
+	if err != nil {
+		log.Fatalf("Failed to start import: %v", err)
+	}
+
+	if err := job.Run(ctx, run, req, nil); err != nil && ctx.Err() != nil {
+		slog.Info("Import cancelled")
+	}
+
+	// Exit with error code if there were failures
+	if run.Status != model.ImportSucceeded {
+		os.Exit(1)
+	}
+}
+
+// importRequestFromFlags builds the import request the flags describe
+func importRequestFromFlags() (service.ImportRequest, error) {
+	if importReindex {
+		return service.ImportRequest{Mode: model.ImportModeReindex, TitleNumber: importTitleNumber}, nil
+	}
 
-	// Handle --all-history flag
 	if importAllHistory {
-		log.Println("Starting historical import for ALL versions...")
-		log.Println("WARNING: This will take a very long time (potentially hours)")
-		log.Println("")
+		return service.ImportRequest{Mode: model.ImportModeHistory, TitleNumber: importTitleNumber}, nil
+	}
 
-		histStats, err := importer.ImportAllHistory(ctx)
+	if importFrom != "" || importTo != "" {
+		from, err := time.Parse("2006-01-02", importFrom)
 		if err != nil {
-			if ctx.Err() != nil {
-				log.Println("Import cancelled")
-				importer.PrintHistoricalSummary(histStats)
-				os.Exit(1)
-			}
-			log.Fatalf("Historical import failed: %v", err)
+			return service.ImportRequest{}, fmt.Errorf("invalid from date: %w", err)
 		}
-		importer.PrintHistoricalSummary(histStats)
-
-		if histStats.Failed > 0 {
-			os.Exit(1)
+		to, err := time.Parse("2006-01-02", importTo)
+		if err != nil {
+			return service.ImportRequest{}, fmt.Errorf("invalid to date: %w", err)
 		}
-		return
+		return service.ImportRequest{Mode: model.ImportModeRange, From: from, To: to, TitleNumber: importTitleNumber}, nil
 	}
 
-	// Parse snapshot date
 	snapshotDate, err := time.Parse("2006-01-02", importDate)
 	if err != nil {
-		log.Fatalf("Invalid date format: %v", err)
+		return service.ImportRequest{}, fmt.Errorf("invalid date format: %w", err)
 	}
 
-	// Handle single title import
-	if importTitleNumber > 0 {
-		log.Printf("Starting import for title %d on date: %s", importTitleNumber, importDate)
-		stats, err := importer.ImportSingleTitle(ctx, importTitleNumber, importDate, snapshotDate)
-		if err != nil {
-			if ctx.Err() != nil {
-				log.Println("Import cancelled")
-				os.Exit(1)
-			}
-			log.Fatalf("Import failed: %v", err)
-		}
-		importer.PrintSummary(stats)
-		if stats.Failed > 0 {
-			os.Exit(1)
-		}
-		return
+	req := service.ImportRequest{Mode: model.ImportModeFull, Date: snapshotDate}
+	switch {
+	case importTitleNumber > 0:
+		req.Mode = model.ImportModeTitle
+		req.TitleNumber = importTitleNumber
+	case importIncremental:
+		req.Mode = model.ImportModeIncremental
 	}
+	return req, nil
+}
+
+// importStartedBy names whoever ran the command for the run history
+func importStartedBy() string {
+	if user := os.Getenv("USER"); user != "" {
+		return user
+	}
+	return "cli"
+}
 
-	// Run title import
-	log.Printf("Starting import for date: %s", importDate)
-	stats, err := importer.Import(ctx, importDate)
+func runImportHistory(cmd *cobra.Command, args []string) {
+	dbURL := cfg.Database.URL
+	if dbURL == "" {
+		log.Fatal("DATABASE_URL environment variable or database.url setting is required")
+	}
+
+	db, err := store.NewDB(dbURL)
 	if err != nil {
-		if ctx.Err() != nil {
-			log.Println("Import cancelled")
-			os.Exit(1)
+		log.Fatalf("Failed to connect to database: %v", err)
+	}
+	defer db.Close()
+
+	ctx := context.Background()
+	runStore := store.NewImportRunStore(db)
+
+	switch {
+	case len(args) == 1:
+		err = printImportRun(ctx, runStore, parseID(args[0]))
+	case historyFailures:
+		var failures []model.ImportFailure
+		failures, err = runStore.ListFailures(ctx, store.FailureFilter{TitleNumber: historyTitleNumber, Limit: historyLimit})
+		if err == nil {
+			err = printImportFailures(failures, true)
+		}
+	default:
+		var runs []model.ImportRun
+		runs, err = runStore.ListRuns(ctx, store.ImportRunFilter{Source: historySource, Status: historyStatus, Limit: historyLimit})
+		if err == nil {
+			err = printImportRuns(runs)
 		}
-		log.Fatalf("Import failed: %v", err)
 	}
-	importer.PrintSummary(stats)
+	if err != nil {
+		log.Fatal(err)
+	}
+}
 
-	// Run agency import
-	log.Println("\nStarting agency import...")
-	agencyStats, err := importer.ImportAgencies(ctx, snapshotDate)
+func printImportRuns(runs []model.ImportRun) error {
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "ID\tSTARTED\tSOURCE\tBY\tIMPORT\tSTATUS\tDURATION\tFAILED")
+	for _, run := range runs {
+		fmt.Fprintf(w, "%d\t%s\t%s\t%s\t%s\t%s\t%s\t%d\n",
+			run.ID,
+			run.StartedAt.Format("2006-01-02 15:04"),
+			run.Source,
+			run.StartedBy,
+			run.Description(),
+			run.Status,
+			run.Duration(),
+			run.Failures(),
+		)
+	}
+	return w.Flush()
+}
+
+func printImportRun(ctx context.Context, runStore *store.ImportRunStore, id int) error {
+	run, err := runStore.GetRun(ctx, id)
 	if err != nil {
-		if ctx.Err() != nil {
-			log.Println("Import cancelled")
-			os.Exit(1)
+		return err
+	}
+	if run == nil {
+		return fmt.Errorf("import run %d not found", id)
+	}
+
+	fmt.Printf("Run %d: %s\n", run.ID, run.Description())
+	fmt.Printf("Started:   %s by %s (%s)\n", run.StartedAt.Format(time.RFC3339), run.StartedBy, run.Source)
+	fmt.Printf("Status:    %s after %s\n", run.Status, run.Duration())
+	if run.Error.Valid {
+		fmt.Printf("Error:     %s\n", run.Error.String)
+	}
+	if run.Mode == model.ImportModeRange || run.Mode == model.ImportModeHistory {
+		fmt.Printf("Titles:    %d processed, %d failed\n", run.TitlesImported, run.TitlesFailed)
+		fmt.Printf("Versions:  %d processed, %d snapshots created\n", run.VersionsProcessed, run.SnapshotsCreated)
+	} else {
+		fmt.Printf("Titles:    %d of %d imported (%d changed, %d unchanged, %d skipped, %d failed)\n",
+			run.TitlesImported, run.TitlesTotal, run.TitlesChanged, run.TitlesUnchanged, run.TitlesSkipped, run.TitlesFailed)
+		if run.ImportsAgencies() {
+			fmt.Printf("Agencies:  %d of %d imported (%d failed)\n", run.AgenciesImported, run.AgenciesTotal, run.AgenciesFailed)
 		}
-		log.Fatalf("Agency import failed: %v", err)
 	}
-	importer.PrintAgencySummary(agencyStats)
 
-	// Calculate and store system metrics
-	log.Println("\nCalculating system metrics...")
-	metricsService := service.NewMetricsService(db)
-	systemMetrics, err := metricsService.CalculateAndStore(ctx)
+	failures, err := runStore.ListFailures(ctx, store.FailureFilter{RunID: id, Limit: historyLimit})
 	if err != nil {
-		log.Printf("Warning: Failed to calculate metrics: %v", err)
-	} else {
-		log.Println("")
-		log.Println("=== System Metrics ===")
-		log.Printf("Total titles:     %d", systemMetrics.TotalTitles)
-		log.Printf("Total words:      %d", systemMetrics.TotalWords)
-		log.Printf("Total sections:   %d", systemMetrics.TotalSections)
-		log.Printf("Total agencies:   %d", systemMetrics.TotalAgencies)
-		log.Printf("Average density:  %.2f words/section", systemMetrics.AverageDensity)
-		log.Printf("Largest title:    %s (%d words)", systemMetrics.LargestTitle, systemMetrics.LargestTitleWords)
-		log.Printf("Top agency:       %s (%d words)", systemMetrics.TopAgency, systemMetrics.TopAgencyWords)
+		return err
+	}
+	if len(failures) == 0 {
+		return nil
 	}
+	fmt.Println()
+	return printImportFailures(failures, false)
+}
 
-	// Exit with error code if there were failures
-	if stats.Failed > 0 || agencyStats.Failed > 0 {
-		os.Exit(1)
+func printImportFailures(failures []model.ImportFailure, withRun bool) error {
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	if withRun {
+		fmt.Fprintln(w, "RUN\tTIME\tFAILED\tERROR")
+	} else {
+		fmt.Fprintln(w, "TIME\tFAILED\tERROR")
+	}
+	for _, f := range failures {
+		if withRun {
+			fmt.Fprintf(w, "%d\t", f.RunID)
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\n", f.CreatedAt.Format("2006-01-02 15:04"), f.Subject(), f.Message)
 	}
+	return w.Flush()
 }
@@ -0,0 +1,153 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/jjenkins/usds/internal/api"
+	"github.com/jjenkins/usds/internal/handlers"
+	"github.com/spf13/cobra"
+)
+
+var openapiCheckURL string
+
+var openapiCmd = &cobra.Command{
+	Use:   "openapi",
+	Short: "Print the JSON API's OpenAPI document or check a server against it",
+	Long: `Print the OpenAPI 3 document served at /api/v1/openapi.json.
+
+With --check, every API route on a running server is requested and each
+response body is validated against the schema the spec declares for its
+status code. The command exits non-zero if any response does not match.
+
+Examples:
+  # Print the spec
+  ./usds openapi > openapi.json
+
+  # Validate a running server against the spec
+  ./usds openapi --check http://localhost:8080`,
+	Run: runOpenAPI,
+}
+
+func init() {
+	rootCmd.AddCommand(openapiCmd)
+	openapiCmd.Flags().StringVar(&openapiCheckURL, "check", "", "Base URL of a running server to validate against the spec")
+}
+
+func runOpenAPI(cmd *cobra.Command, args []string) {
+	spec := handlers.OpenAPISpec()
+
+	if openapiCheckURL == "" {
+		encoder := json.NewEncoder(os.Stdout)
+		encoder.SetIndent("", "  ")
+		if err := encoder.Encode(spec); err != nil {
+			log.Fatalf("Failed to encode spec: %v", err)
+		}
+		return
+	}
+
+	baseURL := strings.TrimRight(openapiCheckURL, "/")
+	client := &http.Client{Timeout: 30 * time.Second}
+
+	get := func(path string) (int, []byte, error) {
+		resp, err := client.Get(baseURL + path)
+		if err != nil {
+			return 0, nil, err
+		}
+		defer resp.Body.Close()
+		body, err := io.ReadAll(resp.Body)
+		return resp.StatusCode, body, err
+	}
+
+	// Pick real identifiers for the parameterized routes
+	params := map[string]string{"{number}": "1", "{slug}": "unknown"}
+	if _, body, err := get(handlers.APIPrefix + "/titles"); err == nil {
+		var titles []api.Title
+		if json.Unmarshal(body, &titles) == nil && len(titles) > 0 {
+			params["{number}"] = strconv.Itoa(titles[0].Number)
+		}
+	}
+	if _, body, err := get(handlers.APIPrefix + "/agencies"); err == nil {
+		var agencies []api.Agency
+		if json.Unmarshal(body, &agencies) == nil && len(agencies) > 0 {
+			params["{slug}"] = agencies[0].Slug
+		}
+	}
+
+	paths := make([]string, 0, len(spec.Paths))
+	for path := range spec.Paths {
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+
+	failures := 0
+	for _, specPath := range paths {
+		path := specPath
+		for placeholder, value := range params {
+			path = strings.ReplaceAll(path, placeholder, value)
+		}
+
+		status, body, err := get(path)
+		if err != nil {
+			log.Printf("FAIL %s: %v", path, err)
+			failures++
+			continue
+		}
+
+		schema, err := spec.ResponseSchema(specPath, strconv.Itoa(status))
+		if err != nil {
+			log.Printf("FAIL %s: %v", path, err)
+			failures++
+			continue
+		}
+
+		errs := spec.ValidateJSON(schema, body)
+		if len(errs) > 0 {
+			log.Printf("FAIL %s (HTTP %d):", path, status)
+			for _, e := range errs {
+				log.Printf("  %v", e)
+			}
+			failures++
+			continue
+		}
+
+		log.Printf("OK   %s (HTTP %d)", path, status)
+	}
+
+	// The served document must match this binary's spec exactly
+	_, served, err := get(handlers.APIPrefix + "/openapi.json")
+	if err != nil {
+		log.Printf("FAIL %s/openapi.json: %v", handlers.APIPrefix, err)
+		failures++
+	} else {
+		expected, _ := json.Marshal(spec)
+		if !jsonEqual(served, expected) {
+			log.Printf("FAIL %s/openapi.json: served spec differs from this build", handlers.APIPrefix)
+			failures++
+		}
+	}
+
+	if failures > 0 {
+		fmt.Fprintf(os.Stderr, "%d route(s) do not match the spec\n", failures)
+		os.Exit(1)
+	}
+}
+
+// jsonEqual compares two JSON documents ignoring formatting and key order
+func jsonEqual(a, b []byte) bool {
+	var va, vb any
+	if json.Unmarshal(a, &va) != nil || json.Unmarshal(b, &vb) != nil {
+		return false
+	}
+	ca, _ := json.Marshal(va)
+	cb, _ := json.Marshal(vb)
+	return string(ca) == string(cb)
+}
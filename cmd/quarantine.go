@@ -0,0 +1,126 @@
+package cmd
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"log"
+	"os"
+	"strconv"
+	"text/tabwriter"
+
+	"github.com/jjenkins/usds/internal/model"
+	"github.com/jjenkins/usds/internal/store"
+	"github.com/spf13/cobra"
+)
+
+var quarantineStatus string
+
+var quarantineCmd = &cobra.Command{
+	Use:   "quarantine",
+	Short: "Review title versions held back by import validation",
+	Long: `Imports check each fetched title before saving it. A version is quarantined
+instead of imported when its XML is malformed or truncated, its root is not
+the requested title, or its word count fell by more than import.max_word_drop
+percent (IMPORT_MAX_WORD_DROP, default 50) since the title's latest snapshot.
+Each quarantined version also fails its import run.
+
+Accepting a version lets the same content import on the next run, for a title
+that really did shrink. Rejecting it only marks it reviewed.
+
+Examples:
+  # List versions awaiting review
+  ./usds quarantine list
+
+  # Accept a genuine drop, then re-import the title
+  ./usds quarantine accept 12
+  ./usds import --title 4`,
+}
+
+var quarantineListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List quarantined versions, newest first",
+	Args:  cobra.NoArgs,
+	Run:   runQuarantineList,
+}
+
+var quarantineAcceptCmd = &cobra.Command{
+	Use:   "accept <quarantine-id>",
+	Short: "Accept a version so its content imports on the next run",
+	Args:  cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		withQuarantineStore(func(ctx context.Context, quarantineStore *store.QuarantineStore) error {
+			return quarantineStore.Review(ctx, parseID(args[0]), model.QuarantineAccepted)
+		})
+	},
+}
+
+var quarantineRejectCmd = &cobra.Command{
+	Use:   "reject <quarantine-id>",
+	Short: "Mark a version reviewed and keep it out",
+	Args:  cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		withQuarantineStore(func(ctx context.Context, quarantineStore *store.QuarantineStore) error {
+			return quarantineStore.Review(ctx, parseID(args[0]), model.QuarantineRejected)
+		})
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(quarantineCmd)
+	quarantineCmd.AddCommand(quarantineListCmd, quarantineAcceptCmd, quarantineRejectCmd)
+
+	quarantineListCmd.Flags().StringVar(&quarantineStatus, "status", model.QuarantinePending, "Only list this status: pending, accepted, rejected, or all")
+}
+
+// withQuarantineStore connects to the database and runs fn, exiting on error
+func withQuarantineStore(fn func(ctx context.Context, quarantineStore *store.QuarantineStore) error) {
+	dbURL := cfg.Database.URL
+	if dbURL == "" {
+		log.Fatal("DATABASE_URL environment variable or database.url setting is required")
+	}
+
+	db, err := store.NewDB(dbURL)
+	if err != nil {
+		log.Fatalf("Failed to connect to database: %v", err)
+	}
+	defer db.Close()
+
+	if err := fn(context.Background(), store.NewQuarantineStore(db)); err != nil {
+		log.Fatal(err)
+	}
+}
+
+func runQuarantineList(cmd *cobra.Command, args []string) {
+	status := quarantineStatus
+	switch status {
+	case model.QuarantinePending, model.QuarantineAccepted, model.QuarantineRejected:
+	case "all":
+		status = ""
+	default:
+		log.Fatalf("Invalid --status %q: use pending, accepted, rejected or all", quarantineStatus)
+	}
+
+	withQuarantineStore(func(ctx context.Context, quarantineStore *store.QuarantineStore) error {
+		versions, err := quarantineStore.List(ctx, status)
+		if err != nil {
+			return err
+		}
+
+		w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(w, "ID\tTITLE\tVERSION\tWORDS\tPREVIOUS\tSTATUS\tREASON")
+		for _, q := range versions {
+			fmt.Fprintf(w, "%d\t%d\t%s\t%s\t%s\t%s\t%s\n", q.ID, q.TitleNumber, q.VersionDate.Format("2006-01-02"),
+				nullCount(q.WordCount), nullCount(q.PreviousWordCount), q.Status, q.Reason)
+		}
+		return w.Flush()
+	})
+}
+
+// nullCount formats a count that may be unknown
+func nullCount(n sql.NullInt64) string {
+	if !n.Valid {
+		return "-"
+	}
+	return strconv.FormatInt(n.Int64, 10)
+}
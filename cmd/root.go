@@ -5,12 +5,30 @@ Copyright © 2025 NAME HERE <EMAIL ADDRESS>
 package cmd
 
 import (
+	"fmt"
 	"os"
 
+	"github.com/jjenkins/usds/internal/config"
+	"github.com/jjenkins/usds/internal/logging"
 	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
 )
 
+var (
+	cfgFile  string
+	logLevel string
 
+	// cfg is the effective configuration, loaded before any command runs
+	cfg *config.Loaded
+)
+
+// configKey marks a flag as overriding the config setting key when given
+const configKey = "config"
+
+// bindFlag makes an explicitly set flag override config setting key
+func bindFlag(flags *pflag.FlagSet, name, key string) {
+	flags.SetAnnotation(name, configKey, []string{key})
+}
 
 // rootCmd represents the base command when called without any subcommands
 var rootCmd = &cobra.Command{
@@ -25,6 +43,35 @@ to quickly create a Cobra application.`,
 	// Uncomment the following line if your bare application
 	// has an action associated with it:
 	// Run: func(cmd *cobra.Command, args []string) { },
+
+	// Every command loads the config and logs JSON lines to stdout
+	PersistentPreRunE: func(cmd *cobra.Command, args []string) error {
+		// Config errors are not usage errors
+		cmd.SilenceUsage = true
+
+		loaded, err := config.Load(cfgFile)
+		if err != nil {
+			return err
+		}
+
+		var flagErr error
+		cmd.Flags().Visit(func(f *pflag.Flag) {
+			keys := f.Annotations[configKey]
+			if flagErr != nil || len(keys) == 0 {
+				return
+			}
+			flagErr = loaded.Set(keys[0], f.Value.String(), config.SourceFlag+" --"+f.Name)
+		})
+		if flagErr != nil {
+			return flagErr
+		}
+		if err := loaded.Validate(); err != nil {
+			return fmt.Errorf("invalid config: %w", err)
+		}
+
+		cfg = loaded
+		return logging.Setup(os.Stdout, cfg.Log.Level)
+	},
 }
 
 // Execute adds all child commands to the root command and sets flags appropriately.
@@ -41,7 +88,10 @@ func init() {
 	// Cobra supports persistent flags, which, if defined here,
 	// will be global for your application.
 
-	// rootCmd.PersistentFlags().StringVar(&cfgFile, "config", "", "config file (default is $HOME/.usds.yaml)")
+	rootCmd.PersistentFlags().StringVar(&cfgFile, "config", os.Getenv("USDS_CONFIG"), "Config file, .yaml or .toml (env USDS_CONFIG)")
+
+	rootCmd.PersistentFlags().StringVar(&logLevel, "log-level", "info", "Log level: debug, info, warn or error")
+	bindFlag(rootCmd.PersistentFlags(), "log-level", "log.level")
 
 	// Cobra also supports local flags, which will only run
 	// when this action is called directly.
@@ -0,0 +1,106 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/jjenkins/usds/internal/store"
+	"github.com/spf13/cobra"
+)
+
+var searchTitleNumber int
+var searchAgencySlug string
+var searchAsOf string
+var searchLimit int
+
+var searchCmd = &cobra.Command{
+	Use:   "search <query>",
+	Short: "Full-text search over regulation section text",
+	Long: `Search ranks sections by relevance to the query, like /search in the web UI.
+Matches in each snippet are wrapped in **asterisks**.
+
+The query uses web search syntax: "quoted phrases", -excluded words and OR.
+
+Examples:
+  # Find every section mentioning lead service lines
+  ./usds search '"lead service lines"'
+
+  # Restrict to Title 40 and EPA's chapters
+  ./usds search --title 40 --agency environmental-protection-agency 'lead service lines'
+
+  # Search the text as it stood on a past date
+  ./usds search --as-of 2020-01-01 'lead service lines'`,
+	Args: cobra.MinimumNArgs(1),
+	Run:  runSearch,
+}
+
+func init() {
+	rootCmd.AddCommand(searchCmd)
+
+	searchCmd.Flags().IntVarP(&searchTitleNumber, "title", "t", 0, "Only search this title number")
+	searchCmd.Flags().StringVar(&searchAgencySlug, "agency", "", "Only search chapters owned by this agency slug (and its sub-agencies)")
+	searchCmd.Flags().StringVar(&searchAsOf, "as-of", "", "Search the latest snapshots on or before this date (YYYY-MM-DD)")
+	searchCmd.Flags().IntVarP(&searchLimit, "limit", "n", 20, "Maximum number of results")
+}
+
+func runSearch(cmd *cobra.Command, args []string) {
+	dbURL := cfg.Database.URL
+	if dbURL == "" {
+		log.Fatal("DATABASE_URL environment variable or database.url setting is required")
+	}
+
+	var asOf time.Time
+	if searchAsOf != "" {
+		var err error
+		asOf, err = time.Parse("2006-01-02", searchAsOf)
+		if err != nil {
+			log.Fatalf("Invalid --as-of date: %v", err)
+		}
+	}
+
+	db, err := store.NewDB(dbURL)
+	if err != nil {
+		log.Fatalf("Failed to connect to database: %v", err)
+	}
+	defer db.Close()
+
+	sectionStore := store.NewSectionStore(db).AsOf(asOf)
+
+	hits, total, err := sectionStore.Search(context.Background(), store.SearchQuery{
+		Query:       strings.Join(args, " "),
+		TitleNumber: searchTitleNumber,
+		AgencySlug:  searchAgencySlug,
+		Limit:       searchLimit,
+	})
+	if err != nil {
+		log.Fatalf("Search failed: %v", err)
+	}
+
+	for _, hit := range hits {
+		heading := hit.Heading
+		if heading == "" {
+			heading = hit.Identifier
+		}
+		location := fmt.Sprintf("Title %d", hit.TitleNumber)
+		if hit.Chapter != "" {
+			location += ", Chapter " + hit.Chapter
+		}
+
+		var snippet strings.Builder
+		for _, part := range hit.Snippet {
+			if part.Match {
+				snippet.WriteString("**" + part.Text + "**")
+			} else {
+				snippet.WriteString(part.Text)
+			}
+		}
+
+		fmt.Printf("%.3f  %s (%s, %s)\n", hit.Rank, heading, location, hit.SnapshotDate.Format("2006-01-02"))
+		fmt.Printf("       %s\n\n", snippet.String())
+	}
+
+	fmt.Printf("Showing %d of %d matching sections\n", len(hits), total)
+}
@@ -0,0 +1,91 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"os"
+	"text/tabwriter"
+
+	"github.com/jjenkins/usds/internal/service"
+	"github.com/jjenkins/usds/internal/store"
+	"github.com/spf13/cobra"
+)
+
+var verifyFetch bool
+
+var verifyCmd = &cobra.Command{
+	Use:   "verify",
+	Short: "Audit stored checksums, agency roll-ups and orphan rows",
+	Long: `Verify re-checks the database's integrity without changing it:
+
+  - each title matches its latest snapshot and the sections stored for it
+  - each title's checksum, word count and section count recomputed from its
+    stored content, or from eCFR as of its latest snapshot with --fetch
+  - each agency's word count, title count and checksum recomputed from the
+    titles linked to it and its sub-agencies, as an import does
+  - no agency_titles rows for missing agencies or titles, and no snapshots
+    or sections for missing titles
+
+It lists each problem found and exits non-zero if there are any. Re-running
+"usds import" for the latest date recomputes titles and roll-ups.
+
+Examples:
+  # Check the database alone
+  ./usds verify
+
+  # Also re-download each title to recompute its checksum
+  ./usds verify --fetch`,
+	Args: cobra.NoArgs,
+	Run:  runVerify,
+}
+
+func init() {
+	rootCmd.AddCommand(verifyCmd)
+
+	verifyCmd.Flags().BoolVar(&verifyFetch, "fetch", false, "Download titles whose content is not stored to recompute their checksums")
+}
+
+func runVerify(cmd *cobra.Command, args []string) {
+	dbURL := cfg.Database.URL
+	if dbURL == "" {
+		log.Fatal("DATABASE_URL environment variable or database.url setting is required")
+	}
+
+	db, err := store.NewDB(dbURL)
+	if err != nil {
+		log.Fatalf("Failed to connect to database: %v", err)
+	}
+	defer db.Close()
+
+	var client *service.ECFRClient
+	if verifyFetch {
+		client = service.NewECFRClient(cfg.ECFR)
+	}
+	verifier := service.NewVerifier(client, service.NewParser(), store.NewTitleStore(db), store.NewAgencyStore(db), store.NewIntegrityStore(db))
+
+	report, err := verifier.Verify(context.Background())
+	if err != nil {
+		log.Fatalf("Verify failed: %v", err)
+	}
+
+	fmt.Printf("Checked %d titles and %d agencies; recomputed %d title checksums from content", report.TitlesChecked, report.AgenciesChecked, report.ContentChecked)
+	if report.ContentSkipped > 0 {
+		fmt.Printf(", %d have no stored content (use --fetch)", report.ContentSkipped)
+	}
+	fmt.Println()
+
+	if len(report.Problems) == 0 {
+		fmt.Println("No problems found")
+		return
+	}
+
+	fmt.Printf("%d problems found:\n", len(report.Problems))
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "CHECK\tPROBLEM")
+	for _, p := range report.Problems {
+		fmt.Fprintf(w, "%s\t%s\n", p.Check, p.Message)
+	}
+	w.Flush()
+	os.Exit(1)
+}
@@ -0,0 +1,268 @@
+package cmd
+
+import (
+	"context"
+	"crypto/rand"
+	"database/sql"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"net/url"
+	"os"
+	"strconv"
+	"text/tabwriter"
+	"time"
+
+	"github.com/jjenkins/usds/internal/model"
+	"github.com/jjenkins/usds/internal/service"
+	"github.com/jjenkins/usds/internal/store"
+	"github.com/spf13/cobra"
+)
+
+var webhookName string
+var webhookSecret string
+var webhookTitleNumber int
+var webhookAgencySlug string
+var webhookReceivePort string
+
+var webhooksCmd = &cobra.Command{
+	Use:   "webhooks",
+	Short: "Manage webhook notifications of regulatory changes",
+	Long: `Webhooks POST a signed JSON message to each subscriber when an import
+records a change to a title or agency. The message's "text" field is ready to
+post, so Slack and Teams incoming webhook URLs work as subscribers.
+
+Each request carries an X-USDS-Signature header of the form
+"t=<unix seconds>,v1=<hex>", where v1 is the HMAC-SHA256 of "<t>.<body>"
+keyed with the subscription's secret. Failed deliveries are retried with
+backoff over about 15 hours, then dead-lettered.
+
+Deliveries are sent by "usds serve", at the end of "usds import", and by
+"usds webhooks deliver". Set PUBLIC_URL to include links in messages.
+
+Examples:
+  # Notify a Slack channel when Title 40 changes
+  ./usds webhooks add https://hooks.slack.com/services/... --name epa-team --title 40
+
+  # Notify on changes to EPA or any of its titles
+  ./usds webhooks add https://example.com/hook --agency environmental-protection-agency
+
+  # Run a local receiver that checks signatures, and subscribe it
+  ./usds webhooks receive --port 9000 --secret test
+  ./usds webhooks add http://localhost:9000 --secret test
+
+  # Requeue a dead-lettered delivery
+  ./usds webhooks retry 42`,
+}
+
+var webhooksAddCmd = &cobra.Command{
+	Use:   "add <url>",
+	Short: "Subscribe a URL to change events",
+	Args:  cobra.ExactArgs(1),
+	Run:   runWebhooksAdd,
+}
+
+var webhooksListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List subscriptions",
+	Args:  cobra.NoArgs,
+	Run:   runWebhooksList,
+}
+
+var webhooksRemoveCmd = &cobra.Command{
+	Use:   "remove <subscription-id>",
+	Short: "Delete a subscription and its delivery log",
+	Args:  cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		withWebhookStore(func(ctx context.Context, webhookStore *store.WebhookStore) error {
+			return webhookStore.DeleteSubscription(ctx, parseID(args[0]))
+		})
+	},
+}
+
+var webhooksPauseCmd = &cobra.Command{
+	Use:   "pause <subscription-id>",
+	Short: "Stop queueing events for a subscription",
+	Args:  cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		withWebhookStore(func(ctx context.Context, webhookStore *store.WebhookStore) error {
+			return webhookStore.SetSubscriptionActive(ctx, parseID(args[0]), false)
+		})
+	},
+}
+
+var webhooksResumeCmd = &cobra.Command{
+	Use:   "resume <subscription-id>",
+	Short: "Resume a paused subscription",
+	Args:  cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		withWebhookStore(func(ctx context.Context, webhookStore *store.WebhookStore) error {
+			return webhookStore.SetSubscriptionActive(ctx, parseID(args[0]), true)
+		})
+	},
+}
+
+var webhooksRetryCmd = &cobra.Command{
+	Use:   "retry <delivery-id>",
+	Short: "Requeue a dead-lettered delivery",
+	Args:  cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		withWebhookStore(func(ctx context.Context, webhookStore *store.WebhookStore) error {
+			return webhookStore.RetryDelivery(ctx, parseID(args[0]))
+		})
+	},
+}
+
+var webhooksDeliverCmd = &cobra.Command{
+	Use:   "deliver",
+	Short: "Send every delivery that is due now",
+	Args:  cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		withWebhookStore(func(ctx context.Context, webhookStore *store.WebhookStore) error {
+			stats, err := service.NewWebhookDispatcher(webhookStore, cfg.Server.PublicURL).DeliverDue(ctx)
+			if err != nil {
+				return err
+			}
+			fmt.Printf("Delivered: %d  Retrying: %d  Dead: %d\n", stats.Delivered, stats.Retrying, stats.Dead)
+			return nil
+		})
+	},
+}
+
+var webhooksReceiveCmd = &cobra.Command{
+	Use:   "receive",
+	Short: "Run a local receiver that verifies signatures and prints deliveries",
+	Args:  cobra.NoArgs,
+	Run:   runWebhooksReceive,
+}
+
+func init() {
+	rootCmd.AddCommand(webhooksCmd)
+	webhooksCmd.AddCommand(webhooksAddCmd, webhooksListCmd, webhooksRemoveCmd, webhooksPauseCmd,
+		webhooksResumeCmd, webhooksRetryCmd, webhooksDeliverCmd, webhooksReceiveCmd)
+
+	webhooksAddCmd.Flags().StringVar(&webhookName, "name", "", "Display name (defaults to the URL's host)")
+	webhooksAddCmd.Flags().StringVar(&webhookSecret, "secret", "", "Signing secret (generated if empty)")
+	webhooksAddCmd.Flags().IntVarP(&webhookTitleNumber, "title", "t", 0, "Only send changes to this title number")
+	webhooksAddCmd.Flags().StringVar(&webhookAgencySlug, "agency", "", "Only send changes to this agency or its titles")
+
+	webhooksReceiveCmd.Flags().StringVarP(&webhookReceivePort, "port", "p", "9000", "Port to listen on")
+	webhooksReceiveCmd.Flags().StringVar(&webhookSecret, "secret", "", "Signing secret to verify against (required)")
+}
+
+// withWebhookStore connects to the database and runs fn, exiting on error
+func withWebhookStore(fn func(ctx context.Context, webhookStore *store.WebhookStore) error) {
+	dbURL := cfg.Database.URL
+	if dbURL == "" {
+		log.Fatal("DATABASE_URL environment variable or database.url setting is required")
+	}
+
+	db, err := store.NewDB(dbURL)
+	if err != nil {
+		log.Fatalf("Failed to connect to database: %v", err)
+	}
+	defer db.Close()
+
+	if err := fn(context.Background(), store.NewWebhookStore(db)); err != nil {
+		log.Fatal(err)
+	}
+}
+
+func parseID(arg string) int {
+	id, err := strconv.Atoi(arg)
+	if err != nil || id <= 0 {
+		log.Fatalf("Invalid ID: %s", arg)
+	}
+	return id
+}
+
+func runWebhooksAdd(cmd *cobra.Command, args []string) {
+	u, err := url.Parse(args[0])
+	if err != nil || u.Host == "" || (u.Scheme != "http" && u.Scheme != "https") {
+		log.Fatalf("Invalid webhook URL: %s", args[0])
+	}
+
+	sub := &model.WebhookSubscription{
+		Name:   webhookName,
+		URL:    args[0],
+		Secret: webhookSecret,
+		Active: true,
+	}
+	if sub.Name == "" {
+		sub.Name = u.Host
+	}
+	if sub.Secret == "" {
+		buf := make([]byte, 32)
+		if _, err := rand.Read(buf); err != nil {
+			log.Fatalf("Failed to generate secret: %v", err)
+		}
+		sub.Secret = hex.EncodeToString(buf)
+	}
+	if webhookTitleNumber > 0 {
+		sub.TitleNumber = sql.NullInt64{Int64: int64(webhookTitleNumber), Valid: true}
+	}
+	if webhookAgencySlug != "" {
+		sub.AgencySlug = sql.NullString{String: webhookAgencySlug, Valid: true}
+	}
+
+	withWebhookStore(func(ctx context.Context, webhookStore *store.WebhookStore) error {
+		return webhookStore.CreateSubscription(ctx, sub)
+	})
+
+	fmt.Printf("Created subscription %d (%s)\n", sub.ID, sub.Name)
+	if webhookSecret == "" {
+		fmt.Printf("Signing secret: %s\n", sub.Secret)
+	}
+}
+
+func runWebhooksList(cmd *cobra.Command, args []string) {
+	withWebhookStore(func(ctx context.Context, webhookStore *store.WebhookStore) error {
+		subs, err := webhookStore.ListSubscriptions(ctx)
+		if err != nil {
+			return err
+		}
+
+		w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(w, "ID\tNAME\tURL\tTITLE\tAGENCY\tACTIVE")
+		for _, sub := range subs {
+			title := "*"
+			if sub.TitleNumber.Valid {
+				title = strconv.FormatInt(sub.TitleNumber.Int64, 10)
+			}
+			agency := "*"
+			if sub.AgencySlug.Valid {
+				agency = sub.AgencySlug.String
+			}
+			fmt.Fprintf(w, "%d\t%s\t%s\t%s\t%s\t%t\n", sub.ID, sub.Name, sub.URL, title, agency, sub.Active)
+		}
+		return w.Flush()
+	})
+}
+
+func runWebhooksReceive(cmd *cobra.Command, args []string) {
+	if webhookSecret == "" {
+		log.Fatal("--secret is required")
+	}
+
+	http.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		body, err := io.ReadAll(r.Body)
+		if err != nil {
+			http.Error(w, "failed to read body", http.StatusBadRequest)
+			return
+		}
+
+		if err := service.VerifyWebhookSignature(webhookSecret, r.Header.Get(service.SignatureHeader), body, 5*time.Minute); err != nil {
+			log.Printf("REJECTED delivery %s: %v", r.Header.Get("X-USDS-Delivery"), err)
+			http.Error(w, err.Error(), http.StatusUnauthorized)
+			return
+		}
+
+		log.Printf("Verified %s delivery %s: %s", r.Header.Get("X-USDS-Event"), r.Header.Get("X-USDS-Delivery"), body)
+		w.WriteHeader(http.StatusNoContent)
+	})
+
+	log.Printf("Listening for webhooks on :%s", webhookReceivePort)
+	log.Fatal(http.ListenAndServe(":"+webhookReceivePort, nil))
+}
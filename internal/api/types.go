@@ -0,0 +1,213 @@
+package api
+
+import (
+	"database/sql"
+	"time"
+
+	"github.com/jjenkins/usds/internal/model"
+	"github.com/jjenkins/usds/internal/store"
+)
+
+// Title is the JSON representation of a CFR title
+type Title struct {
+	Number          int       `json:"number"`
+	Name            string    `json:"name"`
+	WordCount       int       `json:"word_count"`
+	SectionCount    int       `json:"section_count"`
+	Checksum        string    `json:"checksum"`
+	LastAmendedDate *string   `json:"last_amended_date" format:"date"`
+	FetchedAt       time.Time `json:"fetched_at"`
+	DensityScore    *float64  `json:"density_score"`
+}
+
+// TitleSnapshot is the JSON representation of a historical title snapshot
+type TitleSnapshot struct {
+	TitleNumber     int     `json:"title_number"`
+	TitleName       string  `json:"title_name"`
+	WordCount       int     `json:"word_count"`
+	SectionCount    int     `json:"section_count"`
+	Checksum        string  `json:"checksum"`
+	LastAmendedDate *string `json:"last_amended_date" format:"date"`
+	SnapshotDate    string  `json:"snapshot_date" format:"date"`
+}
+
+// Agency is the JSON representation of a federal agency
+type Agency struct {
+	ID              int       `json:"id"`
+	Name            string    `json:"name"`
+	ShortName       *string   `json:"short_name"`
+	Slug            string    `json:"slug"`
+	ParentID        *int      `json:"parent_id"`
+	TotalWordCount  int       `json:"total_word_count"`
+	RegulationCount int       `json:"regulation_count"`
+	Checksum        string    `json:"checksum"`
+	UpdatedAt       time.Time `json:"updated_at"`
+	DensityScore    *float64  `json:"density_score"`
+}
+
+// AgencySnapshot is the JSON representation of a historical agency snapshot
+type AgencySnapshot struct {
+	AgencyID        int    `json:"agency_id"`
+	AgencyName      string `json:"agency_name"`
+	TotalWordCount  int    `json:"total_word_count"`
+	RegulationCount int    `json:"regulation_count"`
+	Checksum        string `json:"checksum"`
+	SnapshotDate    string `json:"snapshot_date" format:"date"`
+}
+
+// TitleDetail is a title together with its linked agencies and history
+type TitleDetail struct {
+	Title     Title           `json:"title"`
+	Agencies  []Agency        `json:"agencies"`
+	Snapshots []TitleSnapshot `json:"snapshots"`
+}
+
+// AgencyDetail is an agency together with its hierarchy, titles and history
+type AgencyDetail struct {
+	Agency    Agency           `json:"agency"`
+	Parent    *Agency          `json:"parent"`
+	Children  []Agency         `json:"children"`
+	Titles    []Title          `json:"titles"`
+	Snapshots []AgencySnapshot `json:"snapshots"`
+}
+
+// History summarizes the snapshot dates and current totals
+type History struct {
+	SnapshotDates []string `json:"snapshot_dates"`
+	TotalTitles   int      `json:"total_titles"`
+	TotalWords    int      `json:"total_words"`
+	TotalAgencies int      `json:"total_agencies"`
+}
+
+// Error is returned with every non-2xx API response
+type Error struct {
+	Error string `json:"error"`
+}
+
+// NewTitle converts a model title; density is omitted when hasDensity is false
+func NewTitle(t model.Title, densityScore float64, hasDensity bool) Title {
+	title := Title{
+		Number:          t.TitleNumber,
+		Name:            t.TitleName,
+		WordCount:       t.WordCount,
+		SectionCount:    t.SectionCount,
+		Checksum:        t.Checksum,
+		LastAmendedDate: nullDate(t.LastAmendedDate),
+		FetchedAt:       t.FetchedAt,
+	}
+	if hasDensity {
+		title.DensityScore = &densityScore
+	}
+	return title
+}
+
+// NewTitles converts titles without density scores
+func NewTitles(titles []model.Title) []Title {
+	result := make([]Title, 0, len(titles))
+	for _, t := range titles {
+		result = append(result, NewTitle(t, 0, false))
+	}
+	return result
+}
+
+// NewTitlesWithDensity converts titles with their precomputed density scores
+func NewTitlesWithDensity(titles []store.TitleWithDensity) []Title {
+	result := make([]Title, 0, len(titles))
+	for _, t := range titles {
+		result = append(result, NewTitle(t.Title, t.DensityScore, t.SectionCount > 0))
+	}
+	return result
+}
+
+// NewTitleSnapshots converts title snapshots
+func NewTitleSnapshots(snapshots []model.TitleSnapshot) []TitleSnapshot {
+	result := make([]TitleSnapshot, 0, len(snapshots))
+	for _, s := range snapshots {
+		result = append(result, TitleSnapshot{
+			TitleNumber:     s.TitleNumber,
+			TitleName:       s.TitleName,
+			WordCount:       s.WordCount,
+			SectionCount:    s.SectionCount,
+			Checksum:        s.Checksum,
+			LastAmendedDate: nullDate(s.LastAmendedDate),
+			SnapshotDate:    s.SnapshotDate.Format("2006-01-02"),
+		})
+	}
+	return result
+}
+
+// NewAgency converts a model agency; density is nil when the agency has no titles
+func NewAgency(a model.Agency, densityScore float64, hasDensity bool) Agency {
+	agency := Agency{
+		ID:              a.ID,
+		Name:            a.AgencyName,
+		Slug:            a.Slug,
+		TotalWordCount:  a.TotalWordCount,
+		RegulationCount: a.RegulationCount,
+		Checksum:        a.Checksum,
+		UpdatedAt:       a.UpdatedAt,
+	}
+	if a.ShortName.Valid && a.ShortName.String != "" {
+		shortName := a.ShortName.String
+		agency.ShortName = &shortName
+	}
+	if a.ParentID.Valid {
+		parentID := int(a.ParentID.Int64)
+		agency.ParentID = &parentID
+	}
+	if hasDensity {
+		agency.DensityScore = &densityScore
+	}
+	return agency
+}
+
+// NewAgencies converts agencies without density scores
+func NewAgencies(agencies []model.Agency) []Agency {
+	result := make([]Agency, 0, len(agencies))
+	for _, a := range agencies {
+		result = append(result, NewAgency(a, 0, false))
+	}
+	return result
+}
+
+// NewAgenciesWithDepth converts agencies with their precomputed density scores
+func NewAgenciesWithDepth(agencies []store.AgencyWithDepth) []Agency {
+	result := make([]Agency, 0, len(agencies))
+	for _, a := range agencies {
+		result = append(result, NewAgency(a.Agency, a.DensityScore, a.TitleCount > 0))
+	}
+	return result
+}
+
+// NewAgencySnapshots converts agency snapshots
+func NewAgencySnapshots(snapshots []model.AgencySnapshot) []AgencySnapshot {
+	result := make([]AgencySnapshot, 0, len(snapshots))
+	for _, s := range snapshots {
+		result = append(result, AgencySnapshot{
+			AgencyID:        s.AgencyID,
+			AgencyName:      s.AgencyName,
+			TotalWordCount:  s.TotalWordCount,
+			RegulationCount: s.RegulationCount,
+			Checksum:        s.Checksum,
+			SnapshotDate:    s.SnapshotDate.Format("2006-01-02"),
+		})
+	}
+	return result
+}
+
+// NewDates formats snapshot dates as YYYY-MM-DD strings
+func NewDates(dates []time.Time) []string {
+	result := make([]string, 0, len(dates))
+	for _, d := range dates {
+		result = append(result, d.Format("2006-01-02"))
+	}
+	return result
+}
+
+func nullDate(t sql.NullTime) *string {
+	if !t.Valid {
+		return nil
+	}
+	s := t.Time.Format("2006-01-02")
+	return &s
+}
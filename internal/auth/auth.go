@@ -0,0 +1,238 @@
+package auth
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/coreos/go-oidc/v3/oidc"
+	"github.com/gofiber/fiber/v2"
+	"github.com/jjenkins/usds/internal/config"
+	"golang.org/x/oauth2"
+)
+
+// Role is a user's access level; each role can do everything the roles
+// below it can
+type Role int
+
+const (
+	RoleNone    Role = iota
+	RoleViewer       // Read every page
+	RoleAnalyst      // Keep a watchlist
+	RoleAdmin        // Manage webhooks and imports
+)
+
+func (r Role) String() string {
+	switch r {
+	case RoleViewer:
+		return "viewer"
+	case RoleAnalyst:
+		return "analyst"
+	case RoleAdmin:
+		return "admin"
+	}
+	return "none"
+}
+
+// ParseRole returns the role with the given name, or RoleNone
+func ParseRole(name string) Role {
+	switch strings.ToLower(strings.TrimSpace(name)) {
+	case "viewer":
+		return RoleViewer
+	case "analyst":
+		return RoleAnalyst
+	case "admin":
+		return RoleAdmin
+	}
+	return RoleNone
+}
+
+// Config configures OIDC login. Login is disabled when IssuerURL is empty.
+type Config struct {
+	IssuerURL     string
+	ClientID      string
+	ClientSecret  string
+	RedirectURL   string        // This server's /auth/callback URL
+	RolesClaim    string        // ID token claim listing role names; dots descend into objects, e.g. "realm_access.roles"
+	DefaultRole   Role          // Role for users whose claim names no known role
+	DisabledRole  Role          // Every visitor's role when login is disabled; admin must be chosen explicitly
+	SessionSecret string        // Key for signing session cookies
+	SessionTTL    time.Duration // Lifetime of a login
+}
+
+// Enabled reports whether OIDC login is configured
+func (c Config) Enabled() bool {
+	return c.IssuerURL != ""
+}
+
+// ConfigFrom maps the auth settings to a Config. The redirect URL defaults
+// to publicURL's /auth/callback.
+func ConfigFrom(c config.Auth, publicURL string) Config {
+	cfg := Config{
+		IssuerURL:     c.IssuerURL,
+		ClientID:      c.ClientID,
+		ClientSecret:  c.ClientSecret,
+		RedirectURL:   c.RedirectURL,
+		RolesClaim:    c.RolesClaim,
+		DefaultRole:   RoleViewer,
+		DisabledRole:  RoleViewer,
+		SessionSecret: c.SessionSecret,
+		SessionTTL:    c.SessionTTL.Duration,
+	}
+	if cfg.RedirectURL == "" && publicURL != "" {
+		cfg.RedirectURL = strings.TrimSuffix(publicURL, "/") + "/auth/callback"
+	}
+	if cfg.RolesClaim == "" {
+		cfg.RolesClaim = "roles"
+	}
+	if role := ParseRole(c.DefaultRole); role != RoleNone {
+		cfg.DefaultRole = role
+	}
+	if role := ParseRole(c.DisabledRole); role != RoleNone {
+		cfg.DisabledRole = role
+	}
+	if cfg.SessionTTL <= 0 {
+		cfg.SessionTTL = 12 * time.Hour
+	}
+	return cfg
+}
+
+// User is the signed-in user carried in the session cookie
+type User struct {
+	Subject string `json:"sub"`
+	Email   string `json:"email,omitempty"`
+	Name    string `json:"name,omitempty"`
+	Role    Role   `json:"role"`
+	Expires int64  `json:"exp"`
+}
+
+// Can reports whether the user has at least the given role
+func (u *User) Can(role Role) bool {
+	return u != nil && u.Role >= role
+}
+
+// DisplayName returns the most readable identifier the provider gave
+func (u *User) DisplayName() string {
+	switch {
+	case u.Name != "":
+		return u.Name
+	case u.Email != "":
+		return u.Email
+	}
+	return u.Subject
+}
+
+type userContextKey struct{}
+
+// UserContextKey is the request context key holding the *User; the
+// middleware sets it and layouts read it to show who is signed in
+var UserContextKey = userContextKey{}
+
+// UserFromContext returns the user stored in ctx, or nil
+func UserFromContext(ctx context.Context) *User {
+	user, _ := ctx.Value(UserContextKey).(*User)
+	return user
+}
+
+// CurrentUser returns the request's user, or nil if nobody is signed in
+func CurrentUser(c *fiber.Ctx) *User {
+	user, _ := c.Locals(UserContextKey).(*User)
+	return user
+}
+
+// Authenticator signs users in with an OIDC provider and enforces roles
+type Authenticator struct {
+	config   Config
+	verifier *oidc.IDTokenVerifier
+	oauth    oauth2.Config
+	sessions *cookieSigner
+	secure   bool  // Mark cookies Secure when served over HTTPS
+	visitor  *User // Every visitor's identity when login is disabled
+}
+
+// New discovers the provider's endpoints and keys. With login disabled it
+// returns an Authenticator that gives every visitor cfg.DisabledRole, or
+// viewer if that is unset, so a local install works without an identity
+// provider but only opens the admin pages when configured to.
+func New(ctx context.Context, cfg Config) (*Authenticator, error) {
+	if !cfg.Enabled() {
+		if cfg.DisabledRole == RoleNone {
+			cfg.DisabledRole = RoleViewer
+		}
+		return &Authenticator{config: cfg, visitor: &User{Role: cfg.DisabledRole}}, nil
+	}
+
+	if cfg.ClientID == "" || cfg.RedirectURL == "" {
+		return nil, fmt.Errorf("OIDC_CLIENT_ID and OIDC_REDIRECT_URL (or PUBLIC_URL) are required when OIDC_ISSUER_URL is set")
+	}
+	if len(cfg.SessionSecret) < 32 {
+		return nil, fmt.Errorf("SESSION_SECRET must be at least 32 characters when OIDC_ISSUER_URL is set")
+	}
+
+	provider, err := oidc.NewProvider(ctx, cfg.IssuerURL)
+	if err != nil {
+		return nil, fmt.Errorf("failed to discover OIDC provider %s: %w", cfg.IssuerURL, err)
+	}
+
+	return &Authenticator{
+		config:   cfg,
+		verifier: provider.Verifier(&oidc.Config{ClientID: cfg.ClientID}),
+		oauth: oauth2.Config{
+			ClientID:     cfg.ClientID,
+			ClientSecret: cfg.ClientSecret,
+			RedirectURL:  cfg.RedirectURL,
+			Endpoint:     provider.Endpoint(),
+			Scopes:       []string{oidc.ScopeOpenID, "profile", "email"},
+		},
+		sessions: newCookieSigner(cfg.SessionSecret),
+		secure:   strings.HasPrefix(cfg.RedirectURL, "https://"),
+	}, nil
+}
+
+// Enabled reports whether users must sign in
+func (a *Authenticator) Enabled() bool {
+	return a.config.Enabled()
+}
+
+// Middleware loads the signed-in user from the session cookie. It never
+// rejects a request; routes opt in to protection with Require.
+func (a *Authenticator) Middleware() fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		if !a.Enabled() {
+			c.Locals(UserContextKey, a.visitor)
+			return c.Next()
+		}
+
+		var user User
+		if err := a.sessions.decode(c.Cookies(sessionCookie), &user); err == nil && time.Now().Unix() < user.Expires {
+			c.Locals(UserContextKey, &user)
+		}
+		return c.Next()
+	}
+}
+
+// Require rejects requests from users without at least the given role.
+// Anonymous browser requests are sent to sign in; anonymous API and HTMX
+// requests get 401 and signed-in users without the role get 403.
+func (a *Authenticator) Require(role Role) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		user := CurrentUser(c)
+		if user.Can(role) {
+			return c.Next()
+		}
+
+		if user == nil {
+			if c.Method() == fiber.MethodGet && c.Get("HX-Request") != "true" && c.Accepts(fiber.MIMETextHTML) != "" {
+				return c.Redirect("/auth/login?return_to="+url.QueryEscape(c.OriginalURL()), fiber.StatusFound)
+			}
+			return c.Status(fiber.StatusUnauthorized).SendString("Sign in required")
+		}
+
+		if !a.Enabled() {
+			return c.Status(fiber.StatusForbidden).SendString(fmt.Sprintf("This page requires the %s role; sign in is disabled, so set auth.disabled_role to allow it", role))
+		}
+		return c.Status(fiber.StatusForbidden).SendString(fmt.Sprintf("This page requires the %s role", role))
+	}
+}
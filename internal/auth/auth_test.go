@@ -0,0 +1,250 @@
+package auth_test
+
+import (
+	"context"
+	"encoding/base64"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/jjenkins/usds/internal/auth"
+	"github.com/jjenkins/usds/internal/auth/oidctest"
+	"github.com/jjenkins/usds/internal/config"
+)
+
+const (
+	clientID      = "usds"
+	clientSecret  = "client-secret"
+	sessionSecret = "a-session-secret-of-at-least-32-characters"
+)
+
+// newApp serves "/" to everyone and "/admin" to admins, signing users in
+// with a mock provider
+func newApp(t *testing.T) (*fiber.App, *oidctest.Provider) {
+	t.Helper()
+
+	provider, err := oidctest.NewProvider("", clientID, clientSecret, oidctest.User{Subject: "nobody"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(provider.Close)
+
+	authenticator, err := auth.New(context.Background(), auth.Config{
+		IssuerURL:     provider.Issuer(),
+		ClientID:      clientID,
+		ClientSecret:  clientSecret,
+		RedirectURL:   "http://usds.test/auth/callback",
+		RolesClaim:    "roles",
+		DefaultRole:   auth.RoleViewer,
+		SessionSecret: sessionSecret,
+		SessionTTL:    time.Hour,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	app := fiber.New()
+	app.Use(authenticator.Middleware())
+	authenticator.RegisterRoutes(app)
+	app.Get("/", func(c *fiber.Ctx) error {
+		if user := auth.CurrentUser(c); user != nil {
+			return c.SendString("Hello " + user.DisplayName())
+		}
+		return c.SendString("Hello")
+	})
+	admin := app.Group("/admin", authenticator.Require(auth.RoleAdmin))
+	admin.Get("/", func(c *fiber.Ctx) error { return c.SendString("Imports") })
+	admin.Post("/imports", func(c *fiber.Ctx) error { return c.SendStatus(fiber.StatusAccepted) })
+
+	return app, provider
+}
+
+// login signs user in through the provider and returns the session cookie
+func login(t *testing.T, app *fiber.App, provider *oidctest.Provider, user oidctest.User) *http.Cookie {
+	t.Helper()
+	provider.SetUser(user)
+
+	// The app sends the browser to the provider
+	resp, err := app.Test(httptest.NewRequest(http.MethodGet, "/auth/login?return_to=/admin", nil))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if resp.StatusCode != http.StatusFound || !strings.HasPrefix(resp.Header.Get("Location"), provider.Issuer()+"/authorize?") {
+		t.Fatalf("GET /auth/login = %d to %q, want a redirect to the provider", resp.StatusCode, resp.Header.Get("Location"))
+	}
+	loginCookie := cookie(t, resp, "usds_login")
+
+	// The provider approves and sends the browser back with a code
+	client := &http.Client{CheckRedirect: func(*http.Request, []*http.Request) error { return http.ErrUseLastResponse }}
+	authorized, err := client.Get(resp.Header.Get("Location"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	authorized.Body.Close()
+	callback, err := url.Parse(authorized.Header.Get("Location"))
+	if err != nil || callback.Path != "/auth/callback" {
+		t.Fatalf("provider redirected to %q, want /auth/callback", authorized.Header.Get("Location"))
+	}
+
+	// The app exchanges the code and starts a session
+	req := httptest.NewRequest(http.MethodGet, callback.RequestURI(), nil)
+	req.AddCookie(loginCookie)
+	resp, err = app.Test(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if resp.StatusCode != http.StatusFound || resp.Header.Get("Location") != "/admin" {
+		t.Fatalf("GET /auth/callback = %d to %q, want a redirect to /admin", resp.StatusCode, resp.Header.Get("Location"))
+	}
+	return cookie(t, resp, "usds_session")
+}
+
+func cookie(t *testing.T, resp *http.Response, name string) *http.Cookie {
+	t.Helper()
+	for _, c := range resp.Cookies() {
+		if c.Name == name && c.Value != "" {
+			return c
+		}
+	}
+	t.Fatalf("response set no %s cookie", name)
+	return nil
+}
+
+// get requests path with the session cookie, if any, and extra headers
+func get(t *testing.T, app *fiber.App, method, path string, session *http.Cookie, headers ...string) *http.Response {
+	t.Helper()
+	req := httptest.NewRequest(method, path, nil)
+	for i := 0; i+1 < len(headers); i += 2 {
+		req.Header.Set(headers[i], headers[i+1])
+	}
+	if session != nil {
+		req.AddCookie(session)
+	}
+	resp, err := app.Test(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return resp
+}
+
+func TestLogin(t *testing.T) {
+	app, provider := newApp(t)
+	const browser = "text/html,application/xhtml+xml,*/*;q=0.8"
+
+	t.Run("admin", func(t *testing.T) {
+		session := login(t, app, provider, oidctest.User{Subject: "ada", Name: "Ada", Roles: []string{"viewer", "admin"}})
+		if resp := get(t, app, http.MethodGet, "/admin", session, "Accept", browser); resp.StatusCode != http.StatusOK {
+			t.Errorf("GET /admin = %d, want 200", resp.StatusCode)
+		}
+		if resp := get(t, app, http.MethodPost, "/admin/imports", session, "HX-Request", "true"); resp.StatusCode != http.StatusAccepted {
+			t.Errorf("POST /admin/imports = %d, want 202", resp.StatusCode)
+		}
+	})
+
+	t.Run("viewer", func(t *testing.T) {
+		for name, roles := range map[string][]string{"viewer role": {"viewer"}, "no known role": {"auditor"}} {
+			session := login(t, app, provider, oidctest.User{Subject: "vic", Email: "vic@example.com", Roles: roles})
+			if resp := get(t, app, http.MethodGet, "/", session); resp.StatusCode != http.StatusOK {
+				t.Errorf("%s: GET / = %d, want 200", name, resp.StatusCode)
+			}
+			if resp := get(t, app, http.MethodGet, "/admin", session, "Accept", browser); resp.StatusCode != http.StatusForbidden {
+				t.Errorf("%s: GET /admin = %d, want 403", name, resp.StatusCode)
+			}
+			if resp := get(t, app, http.MethodPost, "/admin/imports", session, "HX-Request", "true"); resp.StatusCode != http.StatusForbidden {
+				t.Errorf("%s: POST /admin/imports = %d, want 403", name, resp.StatusCode)
+			}
+		}
+	})
+
+	t.Run("anonymous", func(t *testing.T) {
+		if resp := get(t, app, http.MethodGet, "/", nil); resp.StatusCode != http.StatusOK {
+			t.Errorf("GET / = %d, want 200", resp.StatusCode)
+		}
+
+		resp := get(t, app, http.MethodGet, "/admin?tab=runs", nil, "Accept", browser)
+		if want := "/auth/login?return_to=" + url.QueryEscape("/admin?tab=runs"); resp.StatusCode != http.StatusFound || resp.Header.Get("Location") != want {
+			t.Errorf("GET /admin = %d to %q, want a redirect to %q", resp.StatusCode, resp.Header.Get("Location"), want)
+		}
+
+		for name, resp := range map[string]*http.Response{
+			"HTMX GET":  get(t, app, http.MethodGet, "/admin", nil, "Accept", browser, "HX-Request", "true"),
+			"API GET":   get(t, app, http.MethodGet, "/admin", nil, "Accept", "application/json"),
+			"HTMX POST": get(t, app, http.MethodPost, "/admin/imports", nil, "HX-Request", "true"),
+		} {
+			if resp.StatusCode != http.StatusUnauthorized {
+				t.Errorf("%s /admin = %d, want 401", name, resp.StatusCode)
+			}
+		}
+	})
+
+	t.Run("tampered session", func(t *testing.T) {
+		session := login(t, app, provider, oidctest.User{Subject: "vic", Roles: []string{"viewer"}})
+
+		// Promote the viewer to admin without re-signing the cookie
+		payload, sig, _ := strings.Cut(session.Value, ".")
+		data, err := base64.RawURLEncoding.DecodeString(payload)
+		if err != nil {
+			t.Fatal(err)
+		}
+		promoted := strings.Replace(string(data), `"role":1`, `"role":3`, 1)
+		if promoted == string(data) {
+			t.Fatalf("session %s has no viewer role to promote", data)
+		}
+
+		for name, value := range map[string]string{
+			"promoted":  base64.RawURLEncoding.EncodeToString([]byte(promoted)) + "." + sig,
+			"unsigned":  payload,
+			"resigned":  payload + "." + base64.RawURLEncoding.EncodeToString([]byte("forged")),
+			"truncated": session.Value[:len(session.Value)-2],
+		} {
+			tampered := &http.Cookie{Name: session.Name, Value: value}
+			if resp := get(t, app, http.MethodGet, "/admin", tampered, "Accept", "application/json"); resp.StatusCode != http.StatusUnauthorized {
+				t.Errorf("%s cookie: GET /admin = %d, want 401", name, resp.StatusCode)
+			}
+		}
+	})
+}
+
+func TestLoginDisabled(t *testing.T) {
+	for _, tt := range []struct {
+		role  auth.Role
+		admin int // Status of GET /admin
+	}{
+		{auth.RoleNone, http.StatusForbidden}, // Unset means viewer
+		{auth.RoleViewer, http.StatusForbidden},
+		{auth.RoleAnalyst, http.StatusForbidden},
+		{auth.RoleAdmin, http.StatusOK},
+	} {
+		authenticator, err := auth.New(context.Background(), auth.Config{DisabledRole: tt.role})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if authenticator.Enabled() {
+			t.Fatal("login enabled without an issuer")
+		}
+
+		app := fiber.New()
+		app.Use(authenticator.Middleware())
+		app.Get("/", authenticator.Require(auth.RoleViewer), func(c *fiber.Ctx) error { return c.SendString("Titles") })
+		app.Get("/admin", authenticator.Require(auth.RoleAdmin), func(c *fiber.Ctx) error { return c.SendString("Imports") })
+
+		if resp := get(t, app, http.MethodGet, "/", nil); resp.StatusCode != http.StatusOK {
+			t.Errorf("disabled role %s: GET / = %d, want 200", tt.role, resp.StatusCode)
+		}
+		if resp := get(t, app, http.MethodGet, "/admin", nil, "Accept", "text/html"); resp.StatusCode != tt.admin {
+			t.Errorf("disabled role %s: GET /admin = %d, want %d", tt.role, resp.StatusCode, tt.admin)
+		}
+	}
+}
+
+func TestConfigFrom(t *testing.T) {
+	for setting, want := range map[string]auth.Role{"": auth.RoleViewer, "viewer": auth.RoleViewer, "Admin": auth.RoleAdmin} {
+		if got := auth.ConfigFrom(config.Auth{DisabledRole: setting}, "").DisabledRole; got != want {
+			t.Errorf("disabled_role %q = %s, want %s", setting, got, want)
+		}
+	}
+}
@@ -0,0 +1,216 @@
+package auth
+
+import (
+	"crypto/rand"
+	"encoding/base64"
+	"fmt"
+	"log/slog"
+	"strings"
+	"time"
+
+	"github.com/coreos/go-oidc/v3/oidc"
+	"github.com/gofiber/fiber/v2"
+	"golang.org/x/oauth2"
+)
+
+const loginTimeout = 10 * time.Minute
+
+// loginState is kept in the login cookie between redirecting to the
+// provider and its callback
+type loginState struct {
+	State    string `json:"state"`
+	Nonce    string `json:"nonce"`
+	Verifier string `json:"verifier"`
+	ReturnTo string `json:"return_to"`
+	Expires  int64  `json:"exp"`
+}
+
+// RegisterRoutes adds /auth/login, /auth/callback and /auth/logout
+func (a *Authenticator) RegisterRoutes(app *fiber.App) {
+	app.Get("/auth/login", a.loginHandler)
+	app.Get("/auth/callback", a.callbackHandler)
+	app.Get("/auth/logout", a.logoutHandler)
+}
+
+// loginHandler redirects to the provider's authorization endpoint
+func (a *Authenticator) loginHandler(c *fiber.Ctx) error {
+	if !a.Enabled() {
+		return c.Redirect("/", fiber.StatusFound)
+	}
+
+	login := loginState{
+		State:    randomToken(),
+		Nonce:    randomToken(),
+		Verifier: oauth2.GenerateVerifier(),
+		ReturnTo: returnPath(c.Query("return_to")),
+		Expires:  time.Now().Add(loginTimeout).Unix(),
+	}
+	value, err := a.sessions.encode(login)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).SendString("Error starting sign in")
+	}
+	a.setCookie(c, loginCookie, value, loginTimeout)
+
+	authURL := a.oauth.AuthCodeURL(login.State, oidc.Nonce(login.Nonce), oauth2.S256ChallengeOption(login.Verifier))
+	return c.Redirect(authURL, fiber.StatusFound)
+}
+
+// callbackHandler exchanges the authorization code, verifies the ID token
+// and starts a session
+func (a *Authenticator) callbackHandler(c *fiber.Ctx) error {
+	if !a.Enabled() {
+		return c.Redirect("/", fiber.StatusFound)
+	}
+
+	var login loginState
+	if err := a.sessions.decode(c.Cookies(loginCookie), &login); err != nil || time.Now().Unix() > login.Expires {
+		return c.Status(fiber.StatusBadRequest).SendString("Sign in expired, please try again")
+	}
+	a.clearCookie(c, loginCookie)
+
+	if errCode := c.Query("error"); errCode != "" {
+		return c.Status(fiber.StatusUnauthorized).SendString("Sign in failed: " + errCode)
+	}
+	if c.Query("state") != login.State {
+		return c.Status(fiber.StatusBadRequest).SendString("Sign in state mismatch, please try again")
+	}
+
+	ctx := c.UserContext()
+	token, err := a.oauth.Exchange(ctx, c.Query("code"), oauth2.VerifierOption(login.Verifier))
+	if err != nil {
+		slog.WarnContext(ctx, "OIDC code exchange failed", "error", err)
+		return c.Status(fiber.StatusUnauthorized).SendString("Sign in failed")
+	}
+
+	rawIDToken, ok := token.Extra("id_token").(string)
+	if !ok {
+		return c.Status(fiber.StatusUnauthorized).SendString("Sign in failed: no ID token")
+	}
+	idToken, err := a.verifier.Verify(ctx, rawIDToken)
+	if err != nil {
+		slog.WarnContext(ctx, "OIDC ID token verification failed", "error", err)
+		return c.Status(fiber.StatusUnauthorized).SendString("Sign in failed")
+	}
+	if idToken.Nonce != login.Nonce {
+		return c.Status(fiber.StatusUnauthorized).SendString("Sign in failed: nonce mismatch")
+	}
+
+	user, err := a.userFromToken(idToken)
+	if err != nil {
+		slog.WarnContext(ctx, "OIDC claims unreadable", "subject", idToken.Subject, "error", err)
+		return c.Status(fiber.StatusUnauthorized).SendString("Sign in failed")
+	}
+	if user.Role == RoleNone {
+		return c.Status(fiber.StatusForbidden).SendString("Your account has no access to this application")
+	}
+
+	value, err := a.sessions.encode(user)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).SendString("Error starting session")
+	}
+	a.setCookie(c, sessionCookie, value, a.config.SessionTTL)
+
+	slog.InfoContext(ctx, "Signed in", "user", user.DisplayName(), "role", user.Role)
+	return c.Redirect(login.ReturnTo, fiber.StatusFound)
+}
+
+// logoutHandler ends the session
+func (a *Authenticator) logoutHandler(c *fiber.Ctx) error {
+	a.clearCookie(c, sessionCookie)
+	return c.Redirect("/", fiber.StatusFound)
+}
+
+// userFromToken builds the session user, taking the highest role named in
+// the configured roles claim
+func (a *Authenticator) userFromToken(idToken *oidc.IDToken) (*User, error) {
+	var claims map[string]any
+	if err := idToken.Claims(&claims); err != nil {
+		return nil, err
+	}
+
+	user := &User{
+		Subject: idToken.Subject,
+		Role:    a.config.DefaultRole,
+		Expires: time.Now().Add(a.config.SessionTTL).Unix(),
+	}
+	user.Email, _ = claims["email"].(string)
+	user.Name, _ = claims["name"].(string)
+
+	var named Role
+	for _, name := range claimStrings(claims, a.config.RolesClaim) {
+		if role := ParseRole(name); role > named {
+			named = role
+		}
+	}
+	if named != RoleNone {
+		user.Role = named
+	}
+
+	return user, nil
+}
+
+// claimStrings reads a string or list of strings at a dotted claim path
+func claimStrings(claims map[string]any, path string) []string {
+	var value any = claims
+	for _, key := range strings.Split(path, ".") {
+		obj, ok := value.(map[string]any)
+		if !ok {
+			return nil
+		}
+		value = obj[key]
+	}
+
+	switch v := value.(type) {
+	case string:
+		return strings.Fields(v)
+	case []any:
+		var values []string
+		for _, item := range v {
+			if s, ok := item.(string); ok {
+				values = append(values, s)
+			}
+		}
+		return values
+	}
+	return nil
+}
+
+func (a *Authenticator) setCookie(c *fiber.Ctx, name, value string, ttl time.Duration) {
+	c.Cookie(&fiber.Cookie{
+		Name:     name,
+		Value:    value,
+		Path:     "/",
+		Expires:  time.Now().Add(ttl),
+		HTTPOnly: true,
+		Secure:   a.secure,
+		SameSite: fiber.CookieSameSiteLaxMode,
+	})
+}
+
+func (a *Authenticator) clearCookie(c *fiber.Ctx, name string) {
+	c.Cookie(&fiber.Cookie{
+		Name:     name,
+		Value:    "",
+		Path:     "/",
+		Expires:  time.Unix(0, 0),
+		HTTPOnly: true,
+		Secure:   a.secure,
+		SameSite: fiber.CookieSameSiteLaxMode,
+	})
+}
+
+// returnPath only allows local paths as post-login redirects
+func returnPath(path string) string {
+	if !strings.HasPrefix(path, "/") || strings.HasPrefix(path, "//") || strings.HasPrefix(path, "/\\") {
+		return "/"
+	}
+	return path
+}
+
+func randomToken() string {
+	buf := make([]byte, 24)
+	if _, err := rand.Read(buf); err != nil {
+		panic(fmt.Sprintf("crypto/rand failed: %v", err))
+	}
+	return base64.RawURLEncoding.EncodeToString(buf)
+}
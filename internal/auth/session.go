@@ -0,0 +1,56 @@
+package auth
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"strings"
+)
+
+const (
+	sessionCookie = "usds_session"
+	loginCookie   = "usds_login" // State, nonce and PKCE verifier of a login in progress
+)
+
+// cookieSigner encodes values as base64 JSON with an HMAC-SHA256 signature,
+// so cookies can carry sessions without server-side storage
+type cookieSigner struct {
+	key []byte
+}
+
+func newCookieSigner(secret string) *cookieSigner {
+	return &cookieSigner{key: []byte(secret)}
+}
+
+func (s *cookieSigner) encode(v any) (string, error) {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return "", err
+	}
+	payload := base64.RawURLEncoding.EncodeToString(data)
+	return payload + "." + s.sign(payload), nil
+}
+
+func (s *cookieSigner) decode(cookie string, v any) error {
+	payload, sig, ok := strings.Cut(cookie, ".")
+	if !ok {
+		return errors.New("malformed cookie")
+	}
+	if !hmac.Equal([]byte(sig), []byte(s.sign(payload))) {
+		return errors.New("invalid cookie signature")
+	}
+
+	data, err := base64.RawURLEncoding.DecodeString(payload)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(data, v)
+}
+
+func (s *cookieSigner) sign(payload string) string {
+	mac := hmac.New(sha256.New, s.key)
+	mac.Write([]byte(payload))
+	return base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
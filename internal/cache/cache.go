@@ -0,0 +1,103 @@
+package cache
+
+import (
+	"context"
+	"log/slog"
+	"sync"
+	"time"
+
+	"github.com/jjenkins/usds/internal/model"
+)
+
+// maxEntries caps the values kept for one data version; the cache starts
+// over when it fills, which only happens with many distinct as_of dates
+const maxEntries = 1000
+
+// VersionSource returns the current data version
+type VersionSource func(ctx context.Context) (model.DataVersion, error)
+
+// Cache keeps read results for the current data version. Imports, in this
+// process or any other, bump the version in the database; the cache checks it
+// at most once per refresh interval and drops every entry when it changes.
+type Cache struct {
+	source  VersionSource
+	refresh time.Duration
+
+	mu        sync.Mutex
+	version   model.DataVersion
+	checkedAt time.Time
+	entries   map[string]any
+}
+
+// New creates a Cache that trusts a version it has seen for refresh
+func New(source VersionSource, refresh time.Duration) *Cache {
+	return &Cache{
+		source:  source,
+		refresh: refresh,
+		entries: make(map[string]any),
+	}
+}
+
+// Version returns the current data version, checking the source if the last
+// check is older than the refresh interval
+func (c *Cache) Version(ctx context.Context) (model.DataVersion, error) {
+	c.mu.Lock()
+	if !c.checkedAt.IsZero() && time.Since(c.checkedAt) < c.refresh {
+		defer c.mu.Unlock()
+		return c.version, nil
+	}
+	c.mu.Unlock()
+
+	version, err := c.source(ctx)
+	if err != nil {
+		return model.DataVersion{}, err
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if version.Version != c.version.Version {
+		c.entries = make(map[string]any)
+	}
+	c.version = version
+	c.checkedAt = time.Now()
+	return version, nil
+}
+
+// Get returns the value cached under key for the current data version,
+// calling load and keeping its result on a miss. Values are shared between
+// callers, who must not modify them. A nil Cache, or one that cannot read
+// the version, always loads.
+func Get[T any](ctx context.Context, c *Cache, key string, load func() (T, error)) (T, error) {
+	if c == nil {
+		return load()
+	}
+
+	version, err := c.Version(ctx)
+	if err != nil {
+		slog.WarnContext(ctx, "Cache bypassed, failed to check data version", "error", err)
+		return load()
+	}
+
+	c.mu.Lock()
+	value, ok := c.entries[key]
+	c.mu.Unlock()
+	if ok {
+		return value.(T), nil
+	}
+
+	result, err := load()
+	if err != nil {
+		return result, err
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	// Keep the result only if no newer version was seen while loading
+	if c.version.Version == version.Version {
+		if len(c.entries) >= maxEntries {
+			c.entries = make(map[string]any)
+		}
+		c.entries[key] = result
+	}
+	return result, nil
+}
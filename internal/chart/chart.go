@@ -0,0 +1,219 @@
+package chart
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Point is a single value in a time series
+type Point struct {
+	Date  time.Time
+	Value float64
+}
+
+// Tick is an axis label at a position in SVG coordinates
+type Tick struct {
+	Pos   float64
+	Label string
+}
+
+// Marker is a plotted data point with its tooltip text
+type Marker struct {
+	X, Y  float64
+	Label string
+}
+
+// Line is the laid-out geometry of a line chart with axes
+type Line struct {
+	Width, Height            int
+	Left, Right, Top, Bottom float64 // Plot area bounds
+	Path                     string  // SVG path data for the line
+	Area                     string  // SVG path data for the fill under the line
+	Markers                  []Marker
+	XTicks                   []Tick
+	YTicks                   []Tick
+}
+
+// Sparkline is the laid-out geometry of an axis-free inline chart
+type Sparkline struct {
+	Width, Height int
+	Path          string
+	Last          Marker
+}
+
+const (
+	marginLeft   = 56
+	marginRight  = 28
+	marginTop    = 12
+	marginBottom = 28
+	yTickCount   = 4
+	maxXTicks    = 5
+)
+
+// NewLine lays out a line chart of points in a width x height viewBox, with
+// y-axis labels produced by format. It returns nil when there are no points.
+func NewLine(points []Point, width, height int, format func(float64) string) *Line {
+	points = sorted(points)
+	if len(points) == 0 {
+		return nil
+	}
+
+	c := &Line{
+		Width:  width,
+		Height: height,
+		Left:   marginLeft,
+		Right:  float64(width - marginRight),
+		Top:    marginTop,
+		Bottom: float64(height - marginBottom),
+	}
+
+	lo, hi := valueRange(points)
+	lo, hi, step := niceScale(lo, hi, yTickCount)
+	x := timeScale(points, c.Left, c.Right)
+	y := linearScale(lo, hi, c.Bottom, c.Top)
+
+	var path strings.Builder
+	for i, p := range points {
+		px, py := x(p.Date), y(p.Value)
+		if i == 0 {
+			fmt.Fprintf(&path, "M%.1f %.1f", px, py)
+		} else {
+			fmt.Fprintf(&path, " L%.1f %.1f", px, py)
+		}
+		c.Markers = append(c.Markers, Marker{
+			X:     px,
+			Y:     py,
+			Label: fmt.Sprintf("%s: %s", p.Date.Format("Jan 2, 2006"), format(p.Value)),
+		})
+	}
+	c.Path = path.String()
+
+	first, last := c.Markers[0], c.Markers[len(c.Markers)-1]
+	c.Area = fmt.Sprintf("%s L%.1f %.1f L%.1f %.1f Z", c.Path, last.X, c.Bottom, first.X, c.Bottom)
+
+	for v := lo; v <= hi+step/2; v += step {
+		c.YTicks = append(c.YTicks, Tick{Pos: y(v), Label: format(v)})
+	}
+
+	for _, i := range tickIndexes(len(points), maxXTicks) {
+		label := points[i].Date.Format("Jan 2006")
+		if n := len(c.XTicks); n > 0 && c.XTicks[n-1].Label == label {
+			continue
+		}
+		c.XTicks = append(c.XTicks, Tick{Pos: x(points[i].Date), Label: label})
+	}
+
+	return c
+}
+
+// NewSparkline lays out a sparkline of points in a width x height viewBox.
+// It returns nil when there are fewer than two points to draw a trend.
+func NewSparkline(points []Point, width, height int) *Sparkline {
+	points = sorted(points)
+	if len(points) < 2 {
+		return nil
+	}
+
+	const pad = 2
+	lo, hi := valueRange(points)
+	if lo == hi {
+		lo, hi = lo-1, hi+1
+	}
+	x := timeScale(points, pad, float64(width-pad))
+	y := linearScale(lo, hi, float64(height-pad), pad)
+
+	s := &Sparkline{Width: width, Height: height}
+	var path strings.Builder
+	for i, p := range points {
+		if i == 0 {
+			fmt.Fprintf(&path, "M%.1f %.1f", x(p.Date), y(p.Value))
+		} else {
+			fmt.Fprintf(&path, " L%.1f %.1f", x(p.Date), y(p.Value))
+		}
+	}
+	s.Path = path.String()
+
+	last := points[len(points)-1]
+	s.Last = Marker{X: x(last.Date), Y: y(last.Value)}
+
+	return s
+}
+
+// sorted returns a copy of points ordered oldest first
+func sorted(points []Point) []Point {
+	out := make([]Point, len(points))
+	copy(out, points)
+	sort.Slice(out, func(i, j int) bool { return out[i].Date.Before(out[j].Date) })
+	return out
+}
+
+func valueRange(points []Point) (lo, hi float64) {
+	lo, hi = points[0].Value, points[0].Value
+	for _, p := range points[1:] {
+		lo = math.Min(lo, p.Value)
+		hi = math.Max(hi, p.Value)
+	}
+	return lo, hi
+}
+
+// niceScale widens [lo, hi] to round tick boundaries about n steps apart
+func niceScale(lo, hi float64, n int) (float64, float64, float64) {
+	if lo == hi {
+		pad := math.Max(math.Abs(lo)*0.1, 1)
+		lo, hi = lo-pad, hi+pad
+	}
+
+	raw := (hi - lo) / float64(n)
+	magnitude := math.Pow(10, math.Floor(math.Log10(raw)))
+	step := magnitude
+	for _, m := range []float64{1, 2, 2.5, 5, 10} {
+		step = m * magnitude
+		if step >= raw {
+			break
+		}
+	}
+
+	return math.Floor(lo/step) * step, math.Ceil(hi/step) * step, step
+}
+
+// timeScale maps dates onto [left, right]; a single date sits in the middle
+func timeScale(points []Point, left, right float64) func(time.Time) float64 {
+	start, end := points[0].Date, points[len(points)-1].Date
+	span := end.Sub(start).Seconds()
+	return func(t time.Time) float64 {
+		if span == 0 {
+			return (left + right) / 2
+		}
+		return left + t.Sub(start).Seconds()/span*(right-left)
+	}
+}
+
+// linearScale maps [lo, hi] onto [from, to]
+func linearScale(lo, hi, from, to float64) func(float64) float64 {
+	return func(v float64) float64 {
+		if hi == lo {
+			return (from + to) / 2
+		}
+		return from + (v-lo)/(hi-lo)*(to-from)
+	}
+}
+
+// tickIndexes picks up to max evenly spaced indexes including both ends
+func tickIndexes(n, max int) []int {
+	if n <= max {
+		idx := make([]int, n)
+		for i := range idx {
+			idx[i] = i
+		}
+		return idx
+	}
+
+	idx := make([]int, max)
+	for i := range idx {
+		idx[i] = i * (n - 1) / (max - 1)
+	}
+	return idx
+}
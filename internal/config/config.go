@@ -0,0 +1,372 @@
+package config
+
+import (
+	"bytes"
+	"encoding"
+	"errors"
+	"fmt"
+	"io"
+	"net/url"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/pelletier/go-toml/v2"
+	"gopkg.in/yaml.v3"
+)
+
+// Config holds the settings shared by every command. Each value comes from,
+// in increasing precedence: the defaults below, the config file, its
+// environment variable, then its command-line flag.
+type Config struct {
+	Database Database `yaml:"database" toml:"database"`
+	Server   Server   `yaml:"server" toml:"server"`
+	Log      Log      `yaml:"log" toml:"log"`
+	ECFR     ECFR     `yaml:"ecfr" toml:"ecfr"`
+	Import   Import   `yaml:"import" toml:"import"`
+	Auth     Auth     `yaml:"auth" toml:"auth"`
+}
+
+// Database configures the Postgres connection, or a SQLite file with a
+// sqlite: URL
+type Database struct {
+	URL string `yaml:"url" toml:"url" env:"DATABASE_URL" secret:"url"`
+}
+
+// Server configures "usds serve"
+type Server struct {
+	Port       string   `yaml:"port" toml:"port" env:"PORT"`
+	PublicURL  string   `yaml:"public_url" toml:"public_url" env:"PUBLIC_URL"`              // For links in feeds, webhooks and sign in
+	MaxDataAge Duration `yaml:"max_data_age" toml:"max_data_age" env:"READYZ_MAX_DATA_AGE"` // /readyz fails when data is older; 0 disables
+
+	QueryTimeout     Duration `yaml:"query_timeout" toml:"query_timeout" env:"QUERY_TIMEOUT"`                // Per request on pages and the API; 0 disables
+	SlowQueryTimeout Duration `yaml:"slow_query_timeout" toml:"slow_query_timeout" env:"SLOW_QUERY_TIMEOUT"` // Per request on search, history and compare
+	ShutdownTimeout  Duration `yaml:"shutdown_timeout" toml:"shutdown_timeout" env:"SHUTDOWN_TIMEOUT"`       // How long SIGTERM waits for in-flight requests
+	CacheRefresh     Duration `yaml:"cache_refresh" toml:"cache_refresh" env:"CACHE_REFRESH"`                // How often cached reads check the data version
+}
+
+// Log configures logging for every command
+type Log struct {
+	Level string `yaml:"level" toml:"level" env:"LOG_LEVEL"` // debug, info, warn or error
+}
+
+// ECFR configures the eCFR API client
+type ECFR struct {
+	BaseURL        string   `yaml:"base_url" toml:"base_url" env:"ECFR_BASE_URL"`
+	AdminBaseURL   string   `yaml:"admin_base_url" toml:"admin_base_url" env:"ECFR_ADMIN_BASE_URL"`
+	Timeout        Duration `yaml:"timeout" toml:"timeout" env:"ECFR_TIMEOUT"`
+	MaxRetries     int      `yaml:"max_retries" toml:"max_retries" env:"ECFR_MAX_RETRIES"` // Attempts per request
+	InitialBackoff Duration `yaml:"initial_backoff" toml:"initial_backoff" env:"ECFR_INITIAL_BACKOFF"`
+	RequestDelay   Duration `yaml:"request_delay" toml:"request_delay" env:"ECFR_REQUEST_DELAY"` // Pause between titles
+}
+
+// Import configures imports
+type Import struct {
+	Schedule    string `yaml:"schedule" toml:"schedule" env:"IMPORT_SCHEDULE"`                // Cron expression for "usds daemon"
+	MaxWordDrop int    `yaml:"max_word_drop" toml:"max_word_drop" env:"IMPORT_MAX_WORD_DROP"` // Percent a title may shrink before it is quarantined; 0 disables
+}
+
+// Auth configures OIDC login. Login is disabled when IssuerURL is empty.
+type Auth struct {
+	IssuerURL     string   `yaml:"issuer_url" toml:"issuer_url" env:"OIDC_ISSUER_URL"`
+	ClientID      string   `yaml:"client_id" toml:"client_id" env:"OIDC_CLIENT_ID"`
+	ClientSecret  string   `yaml:"client_secret" toml:"client_secret" env:"OIDC_CLIENT_SECRET" secret:"true"`
+	RedirectURL   string   `yaml:"redirect_url" toml:"redirect_url" env:"OIDC_REDIRECT_URL"` // Defaults to PublicURL + /auth/callback
+	RolesClaim    string   `yaml:"roles_claim" toml:"roles_claim" env:"OIDC_ROLES_CLAIM"`
+	DefaultRole   string   `yaml:"default_role" toml:"default_role" env:"OIDC_DEFAULT_ROLE"`
+	DisabledRole  string   `yaml:"disabled_role" toml:"disabled_role" env:"AUTH_DISABLED_ROLE"` // Every visitor's role when login is disabled
+	SessionSecret string   `yaml:"session_secret" toml:"session_secret" env:"SESSION_SECRET" secret:"true"`
+	SessionTTL    Duration `yaml:"session_ttl" toml:"session_ttl" env:"SESSION_TTL"`
+}
+
+// Default returns the built-in settings
+func Default() *Config {
+	return &Config{
+		Server: Server{
+			Port:             "8080",
+			MaxDataAge:       Duration{48 * time.Hour},
+			QueryTimeout:     Duration{10 * time.Second},
+			SlowQueryTimeout: Duration{30 * time.Second},
+			ShutdownTimeout:  Duration{30 * time.Second},
+			CacheRefresh:     Duration{5 * time.Second},
+		},
+		Log: Log{
+			Level: "info",
+		},
+		ECFR: ECFR{
+			BaseURL:        "https://www.ecfr.gov/api/versioner/v1",
+			AdminBaseURL:   "https://www.ecfr.gov/api/admin/v1",
+			Timeout:        Duration{120 * time.Second}, // Large historical titles are slow
+			MaxRetries:     3,
+			InitialBackoff: Duration{2 * time.Second}, // Long enough for 504s to clear
+			RequestDelay:   Duration{1 * time.Second},
+		},
+		Import: Import{
+			Schedule:    "0 6 * * *",
+			MaxWordDrop: 50,
+		},
+		Auth: Auth{
+			RolesClaim:   "roles",
+			DefaultRole:  "viewer",
+			DisabledRole: "viewer",
+			SessionTTL:   Duration{12 * time.Hour},
+		},
+	}
+}
+
+// Sources of a setting's value
+const (
+	SourceDefault = "default"
+	SourceFile    = "file"
+	SourceEnv     = "env"
+	SourceFlag    = "flag"
+)
+
+// Loaded is a Config along with where each setting came from
+type Loaded struct {
+	*Config
+	File    string            // Config file read, if any
+	Sources map[string]string // Setting key, such as "server.port", to its source
+}
+
+// Load reads the defaults, then the YAML or TOML file at path if path is not
+// empty, then the environment
+func Load(path string) (*Loaded, error) {
+	l := &Loaded{Config: Default(), File: path, Sources: make(map[string]string)}
+	for _, s := range settings(l.Config) {
+		l.Sources[s.key] = SourceDefault
+	}
+
+	if path != "" {
+		if err := l.readFile(path); err != nil {
+			return nil, err
+		}
+	}
+
+	for _, s := range settings(l.Config) {
+		value, ok := os.LookupEnv(s.env)
+		if s.env == "" || !ok || value == "" {
+			continue
+		}
+		if err := s.set(value); err != nil {
+			return nil, fmt.Errorf("invalid %s: %w", s.env, err)
+		}
+		l.Sources[s.key] = SourceEnv + " " + s.env
+	}
+
+	return l, nil
+}
+
+// readFile decodes the config file over the defaults, recording which
+// settings it set
+func (l *Loaded) readFile(path string) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("failed to read config file: %w", err)
+	}
+
+	// Decode into generic form too, to learn which keys the file sets
+	var present map[string]any
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".yaml", ".yml":
+		dec := yaml.NewDecoder(bytes.NewReader(data))
+		dec.KnownFields(true)
+		if err := dec.Decode(l.Config); err != nil && !errors.Is(err, io.EOF) {
+			return fmt.Errorf("failed to parse %s: %w", path, err)
+		}
+		err = yaml.Unmarshal(data, &present)
+	case ".toml":
+		dec := toml.NewDecoder(bytes.NewReader(data))
+		dec.DisallowUnknownFields()
+		if err := dec.Decode(l.Config); err != nil {
+			return fmt.Errorf("failed to parse %s: %w", path, err)
+		}
+		err = toml.Unmarshal(data, &present)
+	default:
+		return fmt.Errorf("config file %s must end in .yaml, .yml or .toml", path)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to parse %s: %w", path, err)
+	}
+
+	for _, s := range settings(l.Config) {
+		section, _ := present[s.section].(map[string]any)
+		if _, ok := section[s.name]; ok {
+			l.Sources[s.key] = SourceFile
+		}
+	}
+	return nil
+}
+
+// Set overrides one setting by key, such as "server.port", recording source
+func (l *Loaded) Set(key, value, source string) error {
+	for _, s := range settings(l.Config) {
+		if s.key == key {
+			if err := s.set(value); err != nil {
+				return fmt.Errorf("invalid %s: %w", key, err)
+			}
+			l.Sources[key] = source
+			return nil
+		}
+	}
+	return fmt.Errorf("unknown setting %q", key)
+}
+
+// Validate checks settings whose bad values would only fail later
+func (c *Config) Validate() error {
+	switch strings.ToLower(c.Log.Level) {
+	case "debug", "info", "warn", "error":
+	default:
+		return fmt.Errorf("log.level must be debug, info, warn or error, not %q", c.Log.Level)
+	}
+	if c.ECFR.MaxRetries < 1 {
+		return fmt.Errorf("ecfr.max_retries must be at least 1")
+	}
+	if c.Import.MaxWordDrop < 0 || c.Import.MaxWordDrop > 100 {
+		return fmt.Errorf("import.max_word_drop must be a percentage from 0 to 100")
+	}
+	for key, role := range map[string]string{
+		"auth.default_role":  c.Auth.DefaultRole,
+		"auth.disabled_role": c.Auth.DisabledRole,
+	} {
+		switch strings.ToLower(strings.TrimSpace(role)) {
+		case "", "viewer", "analyst", "admin":
+		default:
+			return fmt.Errorf("%s must be viewer, analyst or admin, not %q", key, role)
+		}
+	}
+	for key, d := range map[string]Duration{
+		"server.max_data_age":       c.Server.MaxDataAge,
+		"server.query_timeout":      c.Server.QueryTimeout,
+		"server.slow_query_timeout": c.Server.SlowQueryTimeout,
+		"server.shutdown_timeout":   c.Server.ShutdownTimeout,
+		"server.cache_refresh":      c.Server.CacheRefresh,
+		"ecfr.timeout":              c.ECFR.Timeout,
+		"ecfr.request_delay":        c.ECFR.RequestDelay,
+		"auth.session_ttl":          c.Auth.SessionTTL,
+	} {
+		if d.Duration < 0 {
+			return fmt.Errorf("%s must not be negative", key)
+		}
+	}
+	return nil
+}
+
+// Redacted returns a copy with secrets masked and passwords removed from URLs
+func (c *Config) Redacted() *Config {
+	redacted := *c
+	for _, s := range settings(&redacted) {
+		value := s.field.String()
+		if s.secret == "" || value == "" {
+			continue
+		}
+		if s.secret == "url" {
+			if u, err := url.Parse(value); err == nil {
+				s.field.SetString(u.Redacted())
+				continue
+			}
+		}
+		s.field.SetString("REDACTED")
+	}
+	return &redacted
+}
+
+// Setting describes one configurable value
+type Setting struct {
+	Key    string // Dotted key, such as "server.port"
+	Env    string // Environment variable, if any
+	Value  string
+	Secret bool
+}
+
+// Settings lists every setting in file order with its current value
+func (c *Config) Settings() []Setting {
+	var list []Setting
+	for _, s := range settings(c) {
+		list = append(list, Setting{Key: s.key, Env: s.env, Value: s.string(), Secret: s.secret != ""})
+	}
+	return list
+}
+
+// setting is a reflected field of Config
+type setting struct {
+	section string
+	name    string
+	key     string
+	env     string
+	secret  string
+	field   reflect.Value
+}
+
+func settings(c *Config) []setting {
+	var list []setting
+	root := reflect.ValueOf(c).Elem()
+	for i := 0; i < root.NumField(); i++ {
+		sectionField := root.Type().Field(i)
+		section := sectionField.Tag.Get("yaml")
+		group := root.Field(i)
+		for j := 0; j < group.NumField(); j++ {
+			f := group.Type().Field(j)
+			name := f.Tag.Get("yaml")
+			list = append(list, setting{
+				section: section,
+				name:    name,
+				key:     section + "." + name,
+				env:     f.Tag.Get("env"),
+				secret:  f.Tag.Get("secret"),
+				field:   group.Field(j),
+			})
+		}
+	}
+	return list
+}
+
+func (s setting) set(value string) error {
+	if u, ok := s.field.Addr().Interface().(encoding.TextUnmarshaler); ok {
+		return u.UnmarshalText([]byte(value))
+	}
+	switch s.field.Kind() {
+	case reflect.String:
+		s.field.SetString(value)
+	case reflect.Int:
+		n, err := strconv.Atoi(value)
+		if err != nil {
+			return fmt.Errorf("%q is not a whole number", value)
+		}
+		s.field.SetInt(int64(n))
+	default:
+		return fmt.Errorf("unsupported setting type %s", s.field.Type())
+	}
+	return nil
+}
+
+func (s setting) string() string {
+	if m, ok := s.field.Interface().(encoding.TextMarshaler); ok {
+		text, _ := m.MarshalText()
+		return string(text)
+	}
+	return fmt.Sprint(s.field.Interface())
+}
+
+// Duration is a time.Duration written as a string such as "90s" or "12h"
+type Duration struct {
+	time.Duration
+}
+
+// UnmarshalText parses a duration such as "90s"
+func (d *Duration) UnmarshalText(text []byte) error {
+	parsed, err := time.ParseDuration(string(text))
+	if err != nil {
+		return fmt.Errorf("%q is not a duration such as 90s or 12h", text)
+	}
+	d.Duration = parsed
+	return nil
+}
+
+// MarshalText writes the duration such as "1m30s"
+func (d Duration) MarshalText() ([]byte, error) {
+	return []byte(d.Duration.String()), nil
+}
@@ -0,0 +1,10 @@
+// Package db holds the database schemas
+package db
+
+import _ "embed"
+
+// SQLiteSchema creates the tables for the SQLite backend. It is idempotent, so
+// it is applied every time a SQLite database is opened.
+//
+//go:embed schema_sqlite.sql
+var SQLiteSchema string
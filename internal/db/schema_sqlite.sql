@@ -0,0 +1,307 @@
+-- eCFR Analyzer Database Schema for SQLite
+--
+-- Mirrors schema.sql table for table; keep the two in step. store.NewDB
+-- applies this file whenever it opens a sqlite: database. Sections are stored
+-- without a search vector, so full-text search still needs Postgres.
+
+-- Titles: Current state of each CFR title (50 total)
+CREATE TABLE IF NOT EXISTS titles (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    title_number INTEGER UNIQUE NOT NULL,
+    title_name TEXT NOT NULL,
+    full_content TEXT,
+    word_count INTEGER DEFAULT 0,
+    section_count INTEGER DEFAULT 0,
+    readability_score REAL,
+    checksum TEXT,
+    last_amended_date DATE,
+    fetched_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_titles_number ON titles(title_number);
+CREATE INDEX IF NOT EXISTS idx_titles_checksum ON titles(checksum);
+
+-- Title Snapshots: Historical versions for change tracking
+CREATE TABLE IF NOT EXISTS title_snapshots (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    title_number INTEGER NOT NULL,
+    title_name TEXT NOT NULL,
+    full_content TEXT,
+    word_count INTEGER DEFAULT 0,
+    section_count INTEGER DEFAULT 0,
+    readability_score REAL,
+    checksum TEXT NOT NULL,
+    last_amended_date DATE,
+    snapshot_date DATE NOT NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE(title_number, snapshot_date)
+);
+
+CREATE INDEX IF NOT EXISTS idx_snapshots_title ON title_snapshots(title_number);
+CREATE INDEX IF NOT EXISTS idx_snapshots_date ON title_snapshots(snapshot_date);
+CREATE INDEX IF NOT EXISTS idx_snapshots_checksum ON title_snapshots(checksum);
+
+-- Sections: Section text per title snapshot
+CREATE TABLE IF NOT EXISTS sections (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    title_number INTEGER NOT NULL,
+    snapshot_date DATE NOT NULL,
+    identifier TEXT NOT NULL,
+    heading TEXT,
+    chapter TEXT,
+    text TEXT NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS idx_sections_title_date ON sections(title_number, snapshot_date);
+
+-- Section search: SQLite's stand-in for the search_vector column and its GIN
+-- index in schema.sql, kept in step with sections by triggers
+CREATE VIRTUAL TABLE IF NOT EXISTS sections_fts USING fts5(
+    heading, text, content = 'sections', content_rowid = 'id', tokenize = 'porter unicode61'
+);
+
+CREATE TRIGGER IF NOT EXISTS sections_fts_insert AFTER INSERT ON sections BEGIN
+    INSERT INTO sections_fts (rowid, heading, text) VALUES (new.id, COALESCE(new.heading, ''), new.text);
+END;
+
+CREATE TRIGGER IF NOT EXISTS sections_fts_delete AFTER DELETE ON sections BEGIN
+    INSERT INTO sections_fts (sections_fts, rowid, heading, text) VALUES ('delete', old.id, COALESCE(old.heading, ''), old.text);
+END;
+
+CREATE TRIGGER IF NOT EXISTS sections_fts_update AFTER UPDATE ON sections BEGIN
+    INSERT INTO sections_fts (sections_fts, rowid, heading, text) VALUES ('delete', old.id, COALESCE(old.heading, ''), old.text);
+    INSERT INTO sections_fts (rowid, heading, text) VALUES (new.id, COALESCE(new.heading, ''), new.text);
+END;
+
+-- Agencies: Federal agencies that issue regulations
+CREATE TABLE IF NOT EXISTS agencies (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    agency_name TEXT UNIQUE NOT NULL,
+    short_name TEXT,
+    slug TEXT UNIQUE NOT NULL,
+    parent_id INTEGER REFERENCES agencies(id),
+    total_word_count INTEGER DEFAULT 0,
+    regulation_count INTEGER DEFAULT 0,
+    checksum TEXT,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_agencies_slug ON agencies(slug);
+
+-- Agency-Title Junction: Many-to-many relationship between agencies and titles
+CREATE TABLE IF NOT EXISTS agency_titles (
+    agency_id INTEGER NOT NULL REFERENCES agencies(id) ON DELETE CASCADE,
+    title_number INTEGER NOT NULL REFERENCES titles(title_number) ON DELETE CASCADE,
+    PRIMARY KEY (agency_id, title_number)
+);
+
+CREATE INDEX IF NOT EXISTS idx_agency_titles_agency ON agency_titles(agency_id);
+CREATE INDEX IF NOT EXISTS idx_agency_titles_title ON agency_titles(title_number);
+
+-- Agency-Chapter Junction: The CFR chapters each agency owns within a title
+CREATE TABLE IF NOT EXISTS agency_chapters (
+    agency_id INTEGER NOT NULL REFERENCES agencies(id) ON DELETE CASCADE,
+    title_number INTEGER NOT NULL,
+    chapter TEXT NOT NULL,
+    PRIMARY KEY (agency_id, title_number, chapter)
+);
+
+CREATE INDEX IF NOT EXISTS idx_agency_chapters_title ON agency_chapters(title_number);
+
+-- Agency Snapshots: Historical agency metrics
+CREATE TABLE IF NOT EXISTS agency_snapshots (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    agency_id INTEGER NOT NULL REFERENCES agencies(id) ON DELETE CASCADE,
+    agency_name TEXT NOT NULL,
+    total_word_count INTEGER DEFAULT 0,
+    regulation_count INTEGER DEFAULT 0,
+    checksum TEXT NOT NULL,
+    snapshot_date DATE NOT NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE(agency_id, snapshot_date)
+);
+
+CREATE INDEX IF NOT EXISTS idx_agency_snapshots_agency ON agency_snapshots(agency_id);
+CREATE INDEX IF NOT EXISTS idx_agency_snapshots_date ON agency_snapshots(snapshot_date);
+
+-- Agency Snapshot Titles: Historical junction for agency-title relationships
+CREATE TABLE IF NOT EXISTS agency_snapshot_titles (
+    agency_snapshot_id INTEGER NOT NULL REFERENCES agency_snapshots(id) ON DELETE CASCADE,
+    title_number INTEGER NOT NULL,
+    PRIMARY KEY (agency_snapshot_id, title_number)
+);
+
+CREATE INDEX IF NOT EXISTS idx_agency_snapshot_titles_snapshot ON agency_snapshot_titles(agency_snapshot_id);
+
+-- Change Events: A title or agency snapshot that differs from the one before it, or is its first
+CREATE TABLE IF NOT EXISTS change_events (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    entity_type TEXT NOT NULL CHECK (entity_type IN ('title', 'agency')),
+    title_number INTEGER,
+    agency_id INTEGER REFERENCES agencies(id) ON DELETE CASCADE,
+    entity_name TEXT NOT NULL,
+    snapshot_date DATE NOT NULL,
+    previous_snapshot_date DATE, -- NULL for an entity's first snapshot
+    previous_word_count INTEGER,
+    word_count INTEGER NOT NULL,
+    checksum TEXT NOT NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_change_events_created ON change_events(created_at);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_change_events_title ON change_events(title_number, snapshot_date) WHERE entity_type = 'title';
+CREATE UNIQUE INDEX IF NOT EXISTS idx_change_events_agency ON change_events(agency_id, snapshot_date) WHERE entity_type = 'agency';
+
+-- Webhook subscriptions: Endpoints notified of change events
+CREATE TABLE IF NOT EXISTS webhook_subscriptions (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    name TEXT NOT NULL,
+    url TEXT NOT NULL,
+    secret TEXT NOT NULL,
+    title_number INTEGER,
+    agency_id INTEGER REFERENCES agencies(id) ON DELETE CASCADE,
+    active BOOLEAN NOT NULL DEFAULT TRUE,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+-- Webhook deliveries: One change event queued for one subscription
+CREATE TABLE IF NOT EXISTS webhook_deliveries (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    subscription_id INTEGER NOT NULL REFERENCES webhook_subscriptions(id) ON DELETE CASCADE,
+    change_event_id INTEGER NOT NULL REFERENCES change_events(id) ON DELETE CASCADE,
+    status TEXT NOT NULL DEFAULT 'pending' CHECK (status IN ('pending', 'delivered', 'dead')),
+    attempts INTEGER NOT NULL DEFAULT 0,
+    next_attempt_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    last_status_code INTEGER,
+    last_error TEXT,
+    delivered_at TIMESTAMP,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE(subscription_id, change_event_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_webhook_deliveries_due ON webhook_deliveries(status, next_attempt_at);
+
+-- Watchlists: Titles, agencies and sections each user follows, with the
+-- state the user last saw
+CREATE TABLE IF NOT EXISTS watchlist_items (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    user_id TEXT NOT NULL,
+    entity_type TEXT NOT NULL CHECK (entity_type IN ('title', 'agency', 'section')),
+    title_number INTEGER,
+    agency_id INTEGER REFERENCES agencies(id) ON DELETE CASCADE,
+    section_identifier TEXT,
+    seen_word_count INTEGER NOT NULL DEFAULT 0,
+    seen_checksum TEXT NOT NULL DEFAULT '',
+    seen_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_watchlist_items_entity ON watchlist_items(
+    user_id, entity_type, COALESCE(title_number, 0), COALESCE(agency_id, 0), COALESCE(section_identifier, '')
+);
+CREATE INDEX IF NOT EXISTS idx_sections_identifier ON sections(title_number, identifier, snapshot_date);
+
+-- Import runs: Each import from the CLI, the admin pages or the daemon
+CREATE TABLE IF NOT EXISTS import_runs (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    source TEXT NOT NULL CHECK (source IN ('cli', 'web', 'daemon')),
+    mode TEXT NOT NULL,
+    snapshot_date DATE,
+    title_number INTEGER,
+    from_date DATE,
+    to_date DATE,
+    started_by TEXT NOT NULL DEFAULT '',
+    status TEXT NOT NULL DEFAULT 'running' CHECK (status IN ('running', 'succeeded', 'failed', 'cancelled')),
+    error TEXT,
+    titles_total INTEGER NOT NULL DEFAULT 0,
+    titles_imported INTEGER NOT NULL DEFAULT 0,
+    titles_changed INTEGER NOT NULL DEFAULT 0,
+    titles_unchanged INTEGER NOT NULL DEFAULT 0,
+    titles_skipped INTEGER NOT NULL DEFAULT 0,
+    titles_failed INTEGER NOT NULL DEFAULT 0,
+    agencies_total INTEGER NOT NULL DEFAULT 0,
+    agencies_imported INTEGER NOT NULL DEFAULT 0,
+    agencies_failed INTEGER NOT NULL DEFAULT 0,
+    versions_processed INTEGER NOT NULL DEFAULT 0,
+    snapshots_created INTEGER NOT NULL DEFAULT 0,
+    started_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    finished_at TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_import_runs_started ON import_runs(started_at);
+
+-- Import failures: A title, version or agency an import run failed on
+CREATE TABLE IF NOT EXISTS import_failures (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    run_id INTEGER NOT NULL REFERENCES import_runs(id) ON DELETE CASCADE,
+    title_number INTEGER,
+    version_date DATE,
+    agency_slug TEXT,
+    message TEXT NOT NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_import_failures_run ON import_failures(run_id);
+CREATE INDEX IF NOT EXISTS idx_import_failures_title ON import_failures(title_number, created_at);
+
+-- Quarantined versions: Fetched title content that failed validation, held
+-- back from snapshots until reviewed. An accepted checksum is imported as is.
+CREATE TABLE IF NOT EXISTS quarantined_versions (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    title_number INTEGER NOT NULL,
+    version_date DATE NOT NULL,
+    checksum TEXT NOT NULL,
+    size_bytes INTEGER NOT NULL,
+    word_count INTEGER,
+    previous_word_count INTEGER,
+    reason TEXT NOT NULL,
+    status TEXT NOT NULL DEFAULT 'pending' CHECK (status IN ('pending', 'accepted', 'rejected')),
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    reviewed_at TIMESTAMP,
+    UNIQUE(title_number, version_date, checksum)
+);
+
+CREATE INDEX IF NOT EXISTS idx_quarantined_versions_status ON quarantined_versions(status, created_at);
+
+-- Metrics: Calculated system-wide metrics
+CREATE TABLE IF NOT EXISTS metrics (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    metric_name TEXT NOT NULL,
+    metric_value TEXT NOT NULL,
+    metric_type TEXT,
+    entity_id INTEGER,
+    calculated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_metrics_name ON metrics(metric_name);
+CREATE INDEX IF NOT EXISTS idx_metrics_entity ON metrics(entity_id, metric_type);
+
+-- Advisory locks: SQLite's stand-in for pg_try_advisory_lock, a lease on a
+-- key that its holder renews until it releases it
+CREATE TABLE IF NOT EXISTS advisory_locks (
+    lock_key BIGINT PRIMARY KEY,
+    holder TEXT NOT NULL,
+    expires_at TIMESTAMP NOT NULL
+);
+
+-- Data version: Bumped by imports so servers know when cached reads are stale
+CREATE TABLE IF NOT EXISTS data_version (
+    id BOOLEAN PRIMARY KEY DEFAULT TRUE CHECK (id),
+    version BIGINT NOT NULL DEFAULT 1,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+INSERT INTO data_version (version) VALUES (1) ON CONFLICT (id) DO NOTHING;
+
+-- Schema version: Bump with store.SchemaVersion whenever this file changes, so
+-- /readyz reports servers running against an outdated schema
+CREATE TABLE IF NOT EXISTS schema_version (
+    id BOOLEAN PRIMARY KEY DEFAULT TRUE CHECK (id),
+    version INTEGER NOT NULL,
+    applied_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+INSERT INTO schema_version (version) VALUES (5)
+ON CONFLICT (id) DO UPDATE SET version = EXCLUDED.version, applied_at = CURRENT_TIMESTAMP;
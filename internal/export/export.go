@@ -0,0 +1,247 @@
+package export
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"strconv"
+	"time"
+
+	"github.com/xitongsys/parquet-go/writer"
+	"github.com/xuri/excelize/v2"
+)
+
+// Format identifies an export file format
+type Format string
+
+const (
+	FormatCSV     Format = "csv"
+	FormatXLSX    Format = "xlsx"
+	FormatParquet Format = "parquet"
+)
+
+// Formats lists the supported export formats in display order
+var Formats = []Format{FormatCSV, FormatXLSX, FormatParquet}
+
+// ParseFormat validates a format name from a query parameter or flag
+func ParseFormat(name string) (Format, error) {
+	for _, f := range Formats {
+		if string(f) == name {
+			return f, nil
+		}
+	}
+	return "", fmt.Errorf("unsupported export format %q (expected csv, xlsx or parquet)", name)
+}
+
+// ContentType returns the MIME type for the format
+func (f Format) ContentType() string {
+	switch f {
+	case FormatXLSX:
+		return "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet"
+	case FormatParquet:
+		return "application/vnd.apache.parquet"
+	default:
+		return "text/csv; charset=utf-8"
+	}
+}
+
+// ColumnType describes how a column's values are encoded
+type ColumnType int
+
+const (
+	String ColumnType = iota
+	Int
+	Float
+	Date
+)
+
+// Column describes a single exported column
+type Column struct {
+	Name     string
+	Type     ColumnType
+	Nullable bool
+}
+
+// Table is a format-independent export: rows hold string, int, float64,
+// time.Time or nil (for nullable columns) values matching the columns
+type Table struct {
+	Name    string
+	Columns []Column
+	Rows    [][]any
+}
+
+// Filename returns the download file name for the table in the given format
+func (t *Table) Filename(f Format) string {
+	return fmt.Sprintf("%s.%s", t.Name, f)
+}
+
+// Write encodes the table in the given format
+func Write(w io.Writer, f Format, t *Table) error {
+	switch f {
+	case FormatCSV:
+		return writeCSV(w, t)
+	case FormatXLSX:
+		return writeXLSX(w, t)
+	case FormatParquet:
+		return writeParquet(w, t)
+	default:
+		return fmt.Errorf("unsupported export format %q", f)
+	}
+}
+
+func writeCSV(w io.Writer, t *Table) error {
+	cw := csv.NewWriter(w)
+
+	header := make([]string, len(t.Columns))
+	for i, col := range t.Columns {
+		header[i] = col.Name
+	}
+	if err := cw.Write(header); err != nil {
+		return fmt.Errorf("failed to write CSV header: %w", err)
+	}
+
+	record := make([]string, len(t.Columns))
+	for _, row := range t.Rows {
+		for i, v := range row {
+			record[i] = formatValue(v)
+		}
+		if err := cw.Write(record); err != nil {
+			return fmt.Errorf("failed to write CSV row: %w", err)
+		}
+	}
+
+	cw.Flush()
+	return cw.Error()
+}
+
+// formatValue renders a cell as text for CSV output
+func formatValue(v any) string {
+	switch val := v.(type) {
+	case nil:
+		return ""
+	case string:
+		return val
+	case int:
+		return strconv.Itoa(val)
+	case float64:
+		return strconv.FormatFloat(val, 'f', -1, 64)
+	case time.Time:
+		return val.Format("2006-01-02")
+	default:
+		return fmt.Sprint(val)
+	}
+}
+
+func writeXLSX(w io.Writer, t *Table) error {
+	f := excelize.NewFile()
+	defer f.Close()
+
+	sheet := t.Name
+	if len(sheet) > 31 {
+		sheet = sheet[:31] // Excel's sheet name limit
+	}
+	if err := f.SetSheetName("Sheet1", sheet); err != nil {
+		return fmt.Errorf("failed to name sheet: %w", err)
+	}
+
+	headerStyle, err := f.NewStyle(&excelize.Style{Font: &excelize.Font{Bold: true}})
+	if err != nil {
+		return fmt.Errorf("failed to create header style: %w", err)
+	}
+	dateFormat := "yyyy-mm-dd"
+	dateStyle, err := f.NewStyle(&excelize.Style{CustomNumFmt: &dateFormat})
+	if err != nil {
+		return fmt.Errorf("failed to create date style: %w", err)
+	}
+
+	for i, col := range t.Columns {
+		cell, _ := excelize.CoordinatesToCellName(i+1, 1)
+		f.SetCellValue(sheet, cell, col.Name)
+		f.SetCellStyle(sheet, cell, cell, headerStyle)
+	}
+
+	for r, row := range t.Rows {
+		for i, v := range row {
+			if v == nil {
+				continue
+			}
+			cell, _ := excelize.CoordinatesToCellName(i+1, r+2)
+			if err := f.SetCellValue(sheet, cell, v); err != nil {
+				return fmt.Errorf("failed to set cell %s: %w", cell, err)
+			}
+			if t.Columns[i].Type == Date {
+				f.SetCellStyle(sheet, cell, cell, dateStyle)
+			}
+		}
+	}
+
+	if err := f.SetPanes(sheet, &excelize.Panes{Freeze: true, YSplit: 1, TopLeftCell: "A2", ActivePane: "bottomLeft"}); err != nil {
+		return fmt.Errorf("failed to freeze header row: %w", err)
+	}
+
+	if _, err := f.WriteTo(w); err != nil {
+		return fmt.Errorf("failed to write XLSX: %w", err)
+	}
+	return nil
+}
+
+func writeParquet(w io.Writer, t *Table) error {
+	metadata := make([]string, len(t.Columns))
+	for i, col := range t.Columns {
+		metadata[i] = parquetMetadata(col)
+	}
+
+	pw, err := writer.NewCSVWriterFromWriter(metadata, w, 1)
+	if err != nil {
+		return fmt.Errorf("failed to create parquet writer: %w", err)
+	}
+
+	for _, row := range t.Rows {
+		rec := make([]any, len(row))
+		for i, v := range row {
+			rec[i] = parquetValue(v)
+		}
+		if err := pw.Write(rec); err != nil {
+			return fmt.Errorf("failed to write parquet row: %w", err)
+		}
+	}
+
+	if err := pw.WriteStop(); err != nil {
+		return fmt.Errorf("failed to finish parquet file: %w", err)
+	}
+	return nil
+}
+
+// parquetMetadata builds the parquet-go schema tag for a column
+func parquetMetadata(col Column) string {
+	var typ string
+	switch col.Type {
+	case Int:
+		typ = "type=INT64"
+	case Float:
+		typ = "type=DOUBLE"
+	case Date:
+		typ = "type=INT32, convertedtype=DATE"
+	default:
+		typ = "type=BYTE_ARRAY, convertedtype=UTF8"
+	}
+
+	repetition := "REQUIRED"
+	if col.Nullable {
+		repetition = "OPTIONAL"
+	}
+
+	return fmt.Sprintf("name=%s, %s, repetitiontype=%s", col.Name, typ, repetition)
+}
+
+// parquetValue converts a cell to the physical type parquet-go expects
+func parquetValue(v any) any {
+	switch val := v.(type) {
+	case int:
+		return int64(val)
+	case time.Time:
+		return int32(val.Unix() / 86400) // Days since the Unix epoch
+	default:
+		return val
+	}
+}
@@ -0,0 +1,176 @@
+package export
+
+import (
+	"database/sql"
+	"fmt"
+
+	"github.com/jjenkins/usds/internal/model"
+	"github.com/jjenkins/usds/internal/store"
+)
+
+// Titles builds the export for the titles table, preserving the given row order
+func Titles(titles []store.TitleWithDensity) *Table {
+	t := &Table{
+		Name: "titles",
+		Columns: []Column{
+			{Name: "title_number", Type: Int},
+			{Name: "title_name", Type: String},
+			{Name: "word_count", Type: Int},
+			{Name: "section_count", Type: Int},
+			{Name: "words_per_section", Type: Float, Nullable: true},
+			{Name: "density_score", Type: Float, Nullable: true},
+			{Name: "last_amended_date", Type: Date, Nullable: true},
+			{Name: "checksum", Type: String},
+		},
+	}
+
+	for _, title := range titles {
+		var wordsPerSection, densityScore any
+		if title.SectionCount > 0 {
+			wordsPerSection = float64(title.WordCount) / float64(title.SectionCount)
+			densityScore = title.DensityScore
+		}
+		t.Rows = append(t.Rows, []any{
+			title.TitleNumber,
+			title.TitleName,
+			title.WordCount,
+			title.SectionCount,
+			wordsPerSection,
+			densityScore,
+			nullTime(title.LastAmendedDate),
+			title.Checksum,
+		})
+	}
+
+	return t
+}
+
+// Agencies builds the export for the agencies table, preserving the given row order
+func Agencies(agencies []store.AgencyWithDepth) *Table {
+	t := &Table{
+		Name: "agencies",
+		Columns: []Column{
+			{Name: "agency_name", Type: String},
+			{Name: "short_name", Type: String, Nullable: true},
+			{Name: "slug", Type: String},
+			{Name: "depth", Type: Int},
+			{Name: "total_word_count", Type: Int},
+			{Name: "title_count", Type: Int},
+			{Name: "words_per_title", Type: Float, Nullable: true},
+			{Name: "density_score", Type: Float, Nullable: true},
+			{Name: "checksum", Type: String},
+		},
+	}
+
+	for _, a := range agencies {
+		var wordsPerTitle, densityScore any
+		if a.TitleCount > 0 {
+			wordsPerTitle = float64(a.TotalWordCount) / float64(a.TitleCount)
+			densityScore = a.DensityScore
+		}
+		t.Rows = append(t.Rows, []any{
+			a.AgencyName,
+			nullString(a.ShortName),
+			a.Slug,
+			a.Depth,
+			a.TotalWordCount,
+			a.TitleCount,
+			wordsPerTitle,
+			densityScore,
+			a.Checksum,
+		})
+	}
+
+	return t
+}
+
+// TitleSnapshots builds the export for a title's snapshot history
+func TitleSnapshots(titleNumber int, snapshots []model.TitleSnapshot) *Table {
+	t := &Table{
+		Name: fmt.Sprintf("title-%d-snapshots", titleNumber),
+		Columns: []Column{
+			{Name: "snapshot_date", Type: Date},
+			{Name: "title_number", Type: Int},
+			{Name: "title_name", Type: String},
+			{Name: "word_count", Type: Int},
+			{Name: "section_count", Type: Int},
+			{Name: "words_per_section", Type: Float, Nullable: true},
+			{Name: "word_count_change", Type: Int, Nullable: true},
+			{Name: "checksum", Type: String},
+		},
+	}
+
+	// Snapshots are ordered newest first, so the previous one is at i+1
+	for i, snap := range snapshots {
+		var wordsPerSection, change any
+		if snap.SectionCount > 0 {
+			wordsPerSection = float64(snap.WordCount) / float64(snap.SectionCount)
+		}
+		if i < len(snapshots)-1 {
+			change = snap.WordCount - snapshots[i+1].WordCount
+		}
+		t.Rows = append(t.Rows, []any{
+			snap.SnapshotDate,
+			snap.TitleNumber,
+			snap.TitleName,
+			snap.WordCount,
+			snap.SectionCount,
+			wordsPerSection,
+			change,
+			snap.Checksum,
+		})
+	}
+
+	return t
+}
+
+// AgencySnapshots builds the export for an agency's snapshot history
+func AgencySnapshots(slug string, snapshots []model.AgencySnapshot) *Table {
+	t := &Table{
+		Name: fmt.Sprintf("agency-%s-snapshots", slug),
+		Columns: []Column{
+			{Name: "snapshot_date", Type: Date},
+			{Name: "agency_name", Type: String},
+			{Name: "total_word_count", Type: Int},
+			{Name: "regulation_count", Type: Int},
+			{Name: "words_per_title", Type: Float, Nullable: true},
+			{Name: "word_count_change", Type: Int, Nullable: true},
+			{Name: "checksum", Type: String},
+		},
+	}
+
+	for i, snap := range snapshots {
+		var wordsPerTitle, change any
+		if snap.RegulationCount > 0 {
+			wordsPerTitle = float64(snap.TotalWordCount) / float64(snap.RegulationCount)
+		}
+		if i < len(snapshots)-1 {
+			change = snap.TotalWordCount - snapshots[i+1].TotalWordCount
+		}
+		t.Rows = append(t.Rows, []any{
+			snap.SnapshotDate,
+			snap.AgencyName,
+			snap.TotalWordCount,
+			snap.RegulationCount,
+			wordsPerTitle,
+			change,
+			snap.Checksum,
+		})
+	}
+
+	return t
+}
+
+func nullTime(t sql.NullTime) any {
+	if !t.Valid {
+		return nil
+	}
+	return t.Time
+}
+
+func nullString(s sql.NullString) any {
+	if !s.Valid || s.String == "" {
+		return nil
+	}
+	return s.String
+}
@@ -1,26 +1,30 @@
 package handlers
 
 import (
-	"context"
-
 	"github.com/a-h/templ"
 	"github.com/gofiber/fiber/v2"
 	"github.com/gofiber/fiber/v2/middleware/adaptor"
+	"github.com/jjenkins/usds/internal/export"
 	"github.com/jjenkins/usds/internal/model"
 	"github.com/jjenkins/usds/internal/store"
 	"github.com/jjenkins/usds/internal/templates"
 )
 
-func AgenciesHandler(agencyStore *store.AgencyStore) fiber.Handler {
+func AgenciesHandler(agencyStore store.AgencyRepository) fiber.Handler {
 	return func(c *fiber.Ctx) error {
-		ctx := context.Background()
+		ctx := c.UserContext()
+		agencyStore := agencyStore.AsOf(asOf(c))
 
 		sortBy := c.Query("sort", "name")
 		order := c.Query("order", "asc")
 
 		agencies, err := agencyStore.GetAllSorted(ctx, sortBy, order)
 		if err != nil {
-			return c.Status(fiber.StatusInternalServerError).SendString("Error loading agencies")
+			return internalError(c, err, "Error loading agencies")
+		}
+
+		if format := c.Query("format"); format != "" {
+			return sendExport(c, format, export.Agencies(agencies))
 		}
 
 		// Check if this is an HTMX request for just the table body
@@ -37,15 +41,16 @@ func AgenciesHandler(agencyStore *store.AgencyStore) fiber.Handler {
 	}
 }
 
-func AgencyDetailHandler(agencyStore *store.AgencyStore) fiber.Handler {
+func AgencyDetailHandler(agencyStore store.AgencyRepository, watchlistStore *store.WatchlistStore) fiber.Handler {
 	return func(c *fiber.Ctx) error {
-		ctx := context.Background()
+		ctx := c.UserContext()
+		agencyStore := agencyStore.AsOf(asOf(c))
 
 		slug := c.Params("slug")
 
 		agency, err := agencyStore.GetBySlug(ctx, slug)
 		if err != nil {
-			return c.Status(fiber.StatusInternalServerError).SendString("Error loading agency")
+			return internalError(c, err, "Error loading agency")
 		}
 		if agency == nil {
 			return c.Status(fiber.StatusNotFound).SendString("Agency not found")
@@ -60,25 +65,31 @@ func AgencyDetailHandler(agencyStore *store.AgencyStore) fiber.Handler {
 		// Get child agencies
 		children, err := agencyStore.GetChildren(ctx, agency.ID)
 		if err != nil {
-			return c.Status(fiber.StatusInternalServerError).SendString("Error loading child agencies")
+			return internalError(c, err, "Error loading child agencies")
 		}
 
 		// Get linked titles
 		titles, err := agencyStore.GetTitlesForAgency(ctx, agency.ID)
 		if err != nil {
-			return c.Status(fiber.StatusInternalServerError).SendString("Error loading titles")
+			return internalError(c, err, "Error loading titles")
 		}
 
 		// Get snapshots
 		snapshots, err := agencyStore.GetSnapshotsForAgency(ctx, agency.ID)
 		if err != nil {
-			return c.Status(fiber.StatusInternalServerError).SendString("Error loading snapshots")
+			return internalError(c, err, "Error loading snapshots")
+		}
+
+		if format := c.Query("format"); format != "" {
+			return sendExport(c, format, export.AgencySnapshots(agency.Slug, snapshots))
 		}
 
 		// Calculate density score
 		densityScore, _ := agencyStore.GetDensityScoreForAgency(ctx, agency)
 
-		page := templates.AgencyDetail(agency, parent, children, titles, snapshots, densityScore)
+		watching := watchState(c, watchlistStore, model.AgencyTarget(agency.Slug))
+
+		page := templates.AgencyDetail(agency, parent, children, titles, snapshots, densityScore, watching)
 		handler := adaptor.HTTPHandler(templ.Handler(page))
 
 		return handler(c)
@@ -0,0 +1,409 @@
+package handlers
+
+import (
+	"context"
+	"strconv"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/jjenkins/usds/internal/api"
+	"github.com/jjenkins/usds/internal/model"
+	"github.com/jjenkins/usds/internal/openapi"
+	"github.com/jjenkins/usds/internal/store"
+)
+
+// APIPrefix is the mount point for the versioned JSON API
+const APIPrefix = "/api/v1"
+
+// apiRoute describes a JSON API endpoint; the same table registers the Fiber
+// routes and generates the OpenAPI document so the two cannot drift
+type apiRoute struct {
+	path        string // Fiber-style path relative to APIPrefix
+	specPath    string // OpenAPI-style path relative to APIPrefix
+	operationID string
+	summary     string
+	params      []openapi.Parameter
+	response    any
+	handler     fiber.Handler
+}
+
+var (
+	sortParam = func(values ...string) openapi.Parameter {
+		return openapi.Parameter{
+			Name:        "sort",
+			In:          "query",
+			Description: "Column to sort by",
+			Schema:      &openapi.Schema{Type: "string", Enum: values},
+		}
+	}
+	orderParam = openapi.Parameter{
+		Name:        "order",
+		In:          "query",
+		Description: "Sort direction",
+		Schema:      &openapi.Schema{Type: "string", Enum: []string{"asc", "desc"}},
+	}
+	titleNumberParam = openapi.Parameter{
+		Name:        "number",
+		In:          "path",
+		Description: "CFR title number",
+		Required:    true,
+		Schema:      &openapi.Schema{Type: "integer"},
+	}
+	agencySlugParam = openapi.Parameter{
+		Name:        "slug",
+		In:          "path",
+		Description: "Agency slug from the eCFR Admin API",
+		Required:    true,
+		Schema:      &openapi.Schema{Type: "string"},
+	}
+	// asOfParam is accepted by every route through AsOfMiddleware
+	asOfParam = openapi.Parameter{
+		Name:        "as_of",
+		In:          "query",
+		Description: "Reconstruct data from the latest snapshots on or before this date",
+		Schema:      &openapi.Schema{Type: "string", Format: "date"},
+	}
+)
+
+func apiRoutes(titleStore store.TitleRepository, agencyStore store.AgencyRepository) []apiRoute {
+	return []apiRoute{
+		{
+			path:        "/titles",
+			specPath:    "/titles",
+			operationID: "listTitles",
+			summary:     "List all CFR titles with density scores",
+			params:      []openapi.Parameter{sortParam("number", "name", "word_count", "section_count", "last_amended"), orderParam},
+			response:    []api.Title{},
+			handler:     apiTitlesHandler(titleStore),
+		},
+		{
+			path:        "/titles/:number",
+			specPath:    "/titles/{number}",
+			operationID: "getTitle",
+			summary:     "Get a title with its linked agencies and snapshots",
+			params:      []openapi.Parameter{titleNumberParam},
+			response:    api.TitleDetail{},
+			handler:     apiTitleDetailHandler(titleStore),
+		},
+		{
+			path:        "/titles/:number/snapshots",
+			specPath:    "/titles/{number}/snapshots",
+			operationID: "listTitleSnapshots",
+			summary:     "List historical snapshots for a title, newest first",
+			params:      []openapi.Parameter{titleNumberParam},
+			response:    []api.TitleSnapshot{},
+			handler:     apiTitleSnapshotsHandler(titleStore),
+		},
+		{
+			path:        "/agencies",
+			specPath:    "/agencies",
+			operationID: "listAgencies",
+			summary:     "List all agencies with density scores",
+			params:      []openapi.Parameter{sortParam("name", "word_count", "title_count"), orderParam},
+			response:    []api.Agency{},
+			handler:     apiAgenciesHandler(agencyStore),
+		},
+		{
+			path:        "/agencies/:slug",
+			specPath:    "/agencies/{slug}",
+			operationID: "getAgency",
+			summary:     "Get an agency with its hierarchy, titles and snapshots",
+			params:      []openapi.Parameter{agencySlugParam},
+			response:    api.AgencyDetail{},
+			handler:     apiAgencyDetailHandler(agencyStore),
+		},
+		{
+			path:        "/agencies/:slug/snapshots",
+			specPath:    "/agencies/{slug}/snapshots",
+			operationID: "listAgencySnapshots",
+			summary:     "List historical snapshots for an agency, newest first",
+			params:      []openapi.Parameter{agencySlugParam},
+			response:    []api.AgencySnapshot{},
+			handler:     apiAgencySnapshotsHandler(agencyStore),
+		},
+		{
+			path:        "/history",
+			specPath:    "/history",
+			operationID: "getHistory",
+			summary:     "List snapshot dates and current totals",
+			response:    api.History{},
+			handler:     apiHistoryHandler(titleStore, agencyStore),
+		},
+	}
+}
+
+// RegisterAPIRoutes mounts the JSON API and its OpenAPI document
+func RegisterAPIRoutes(app *fiber.App, titleStore store.TitleRepository, agencyStore store.AgencyRepository) {
+	group := app.Group(APIPrefix)
+
+	routes := apiRoutes(titleStore, agencyStore)
+	for _, r := range routes {
+		group.Get(r.path, r.handler)
+	}
+
+	spec := buildOpenAPISpec(routes)
+	group.Get("/openapi.json", func(c *fiber.Ctx) error {
+		return c.JSON(spec)
+	})
+}
+
+// OpenAPISpec returns the OpenAPI document describing the JSON API
+func OpenAPISpec() *openapi.Document {
+	return buildOpenAPISpec(apiRoutes(nil, nil))
+}
+
+func buildOpenAPISpec(routes []apiRoute) *openapi.Document {
+	doc := openapi.NewDocument(
+		"eCFR Analyzer API",
+		"1.0.0",
+		"Read-only access to CFR title and agency metrics and their historical snapshots.",
+	)
+
+	errorSchema := doc.SchemaFor(api.Error{})
+	errorResponse := func(description string) openapi.Response {
+		return openapi.Response{
+			Description: description,
+			Content:     map[string]openapi.MediaType{"application/json": {Schema: errorSchema}},
+		}
+	}
+
+	for _, r := range routes {
+		responses := map[string]openapi.Response{
+			"200": {
+				Description: "Successful response",
+				Content:     map[string]openapi.MediaType{"application/json": {Schema: doc.SchemaFor(r.response)}},
+			},
+			"400": errorResponse("Invalid parameter"),
+			"500": errorResponse("Database error"),
+		}
+		for _, p := range r.params {
+			if p.In == "path" {
+				responses["404"] = errorResponse("Resource not found")
+			}
+		}
+
+		doc.Paths[APIPrefix+r.specPath] = openapi.PathItem{
+			Get: &openapi.Operation{
+				OperationID: r.operationID,
+				Summary:     r.summary,
+				Parameters:  append(append([]openapi.Parameter{}, r.params...), asOfParam),
+				Responses:   responses,
+			},
+		}
+	}
+
+	doc.Paths[APIPrefix+"/openapi.json"] = openapi.PathItem{
+		Get: &openapi.Operation{
+			OperationID: "getOpenAPI",
+			Summary:     "This OpenAPI document",
+			Responses: map[string]openapi.Response{
+				"200": {
+					Description: "OpenAPI 3 document",
+					Content:     map[string]openapi.MediaType{"application/json": {Schema: &openapi.Schema{Type: "object"}}},
+				},
+			},
+		},
+	}
+
+	return doc
+}
+
+func apiError(c *fiber.Ctx, status int, message string) error {
+	return c.Status(status).JSON(api.Error{Error: message})
+}
+
+func apiTitlesHandler(titleStore store.TitleRepository) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		ctx := c.UserContext()
+		titleStore := titleStore.AsOf(asOf(c))
+
+		titles, err := titleStore.GetAllSortedWithDensity(ctx, c.Query("sort", "number"), c.Query("order", "asc"))
+		if err != nil {
+			return apiInternalError(c, err, "Error loading titles")
+		}
+
+		return c.JSON(api.NewTitlesWithDensity(titles))
+	}
+}
+
+// lookupTitle resolves the :number path parameter, writing the API error on failure
+func lookupTitle(c *fiber.Ctx, ctx context.Context, titleStore store.TitleRepository) (*model.Title, error) {
+	number, err := strconv.Atoi(c.Params("number"))
+	if err != nil {
+		return nil, apiError(c, fiber.StatusBadRequest, "Invalid title number")
+	}
+
+	title, err := titleStore.GetByNumber(ctx, number)
+	if err != nil {
+		return nil, apiInternalError(c, err, "Error loading title")
+	}
+	if title == nil {
+		return nil, apiError(c, fiber.StatusNotFound, "Title not found")
+	}
+
+	return title, nil
+}
+
+func apiTitleDetailHandler(titleStore store.TitleRepository) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		ctx := c.UserContext()
+		titleStore := titleStore.AsOf(asOf(c))
+
+		title, err := lookupTitle(c, ctx, titleStore)
+		if title == nil {
+			return err
+		}
+
+		snapshots, err := titleStore.GetSnapshots(ctx, title.TitleNumber)
+		if err != nil {
+			return apiInternalError(c, err, "Error loading snapshots")
+		}
+
+		agencies, err := titleStore.GetAgenciesForTitle(ctx, title.TitleNumber)
+		if err != nil {
+			return apiInternalError(c, err, "Error loading agencies")
+		}
+
+		densityScore, _ := titleStore.GetDensityScoreForTitle(ctx, title)
+
+		return c.JSON(api.TitleDetail{
+			Title:     api.NewTitle(*title, densityScore, title.SectionCount > 0),
+			Agencies:  api.NewAgencies(agencies),
+			Snapshots: api.NewTitleSnapshots(snapshots),
+		})
+	}
+}
+
+func apiTitleSnapshotsHandler(titleStore store.TitleRepository) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		ctx := c.UserContext()
+		titleStore := titleStore.AsOf(asOf(c))
+
+		title, err := lookupTitle(c, ctx, titleStore)
+		if title == nil {
+			return err
+		}
+
+		snapshots, err := titleStore.GetSnapshots(ctx, title.TitleNumber)
+		if err != nil {
+			return apiInternalError(c, err, "Error loading snapshots")
+		}
+
+		return c.JSON(api.NewTitleSnapshots(snapshots))
+	}
+}
+
+func apiAgenciesHandler(agencyStore store.AgencyRepository) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		ctx := c.UserContext()
+		agencyStore := agencyStore.AsOf(asOf(c))
+
+		agencies, err := agencyStore.GetAllSorted(ctx, c.Query("sort", "name"), c.Query("order", "asc"))
+		if err != nil {
+			return apiInternalError(c, err, "Error loading agencies")
+		}
+
+		return c.JSON(api.NewAgenciesWithDepth(agencies))
+	}
+}
+
+// lookupAgency resolves the :slug path parameter, writing the API error on failure
+func lookupAgency(c *fiber.Ctx, ctx context.Context, agencyStore store.AgencyRepository) (*model.Agency, error) {
+	agency, err := agencyStore.GetBySlug(ctx, c.Params("slug"))
+	if err != nil {
+		return nil, apiInternalError(c, err, "Error loading agency")
+	}
+	if agency == nil {
+		return nil, apiError(c, fiber.StatusNotFound, "Agency not found")
+	}
+
+	return agency, nil
+}
+
+func apiAgencyDetailHandler(agencyStore store.AgencyRepository) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		ctx := c.UserContext()
+		agencyStore := agencyStore.AsOf(asOf(c))
+
+		agency, err := lookupAgency(c, ctx, agencyStore)
+		if agency == nil {
+			return err
+		}
+
+		detail := api.AgencyDetail{}
+
+		if agency.ParentID.Valid {
+			parent, _ := agencyStore.GetByID(ctx, int(agency.ParentID.Int64))
+			if parent != nil {
+				p := api.NewAgency(*parent, 0, false)
+				detail.Parent = &p
+			}
+		}
+
+		children, err := agencyStore.GetChildren(ctx, agency.ID)
+		if err != nil {
+			return apiInternalError(c, err, "Error loading child agencies")
+		}
+
+		titles, err := agencyStore.GetTitlesForAgency(ctx, agency.ID)
+		if err != nil {
+			return apiInternalError(c, err, "Error loading titles")
+		}
+
+		snapshots, err := agencyStore.GetSnapshotsForAgency(ctx, agency.ID)
+		if err != nil {
+			return apiInternalError(c, err, "Error loading snapshots")
+		}
+
+		densityScore, _ := agencyStore.GetDensityScoreForAgency(ctx, agency)
+
+		detail.Agency = api.NewAgency(*agency, densityScore, agency.RegulationCount > 0)
+		detail.Children = api.NewAgencies(children)
+		detail.Titles = api.NewTitles(titles)
+		detail.Snapshots = api.NewAgencySnapshots(snapshots)
+
+		return c.JSON(detail)
+	}
+}
+
+func apiAgencySnapshotsHandler(agencyStore store.AgencyRepository) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		ctx := c.UserContext()
+		agencyStore := agencyStore.AsOf(asOf(c))
+
+		agency, err := lookupAgency(c, ctx, agencyStore)
+		if agency == nil {
+			return err
+		}
+
+		snapshots, err := agencyStore.GetSnapshotsForAgency(ctx, agency.ID)
+		if err != nil {
+			return apiInternalError(c, err, "Error loading snapshots")
+		}
+
+		return c.JSON(api.NewAgencySnapshots(snapshots))
+	}
+}
+
+func apiHistoryHandler(titleStore store.TitleRepository, agencyStore store.AgencyRepository) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		ctx := c.UserContext()
+		titleStore := titleStore.AsOf(asOf(c))
+		agencyStore := agencyStore.AsOf(asOf(c))
+
+		dates, err := loadSnapshotDates(ctx, titleStore, agencyStore)
+		if err != nil {
+			return apiInternalError(c, err, "Error loading snapshots")
+		}
+
+		totalTitles, _ := titleStore.CountTitles(ctx)
+		totalWords, _ := titleStore.GetTotalWordCount(ctx)
+		totalAgencies, _ := agencyStore.CountAgencies(ctx)
+
+		return c.JSON(api.History{
+			SnapshotDates: api.NewDates(dates),
+			TotalTitles:   totalTitles,
+			TotalWords:    totalWords,
+			TotalAgencies: totalAgencies,
+		})
+	}
+}
@@ -0,0 +1,158 @@
+package handlers_test
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"io"
+	"net/http/httptest"
+	"sort"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/jjenkins/usds/internal/handlers"
+	"github.com/jjenkins/usds/internal/model"
+	"github.com/jjenkins/usds/internal/store"
+)
+
+// newAPIApp serves the JSON API from a memory store holding title 1, linked
+// to a parent and a child agency, with snapshots on two dates
+func newAPIApp(t *testing.T) *fiber.App {
+	ctx := context.Background()
+	m := store.NewMemoryStore()
+	titles, agencies := m.Titles(), m.Agencies()
+
+	jan := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	jun := time.Date(2024, 6, 1, 0, 0, 0, 0, time.UTC)
+	title := &model.Title{TitleNumber: 1, TitleName: "General Provisions", WordCount: 100, SectionCount: 4, Checksum: "a",
+		LastAmendedDate: sql.NullTime{Time: jan, Valid: true}}
+	if _, err := titles.SaveTitleWithSnapshot(ctx, title, jan); err != nil {
+		t.Fatal(err)
+	}
+	title.WordCount, title.Checksum = 120, "b"
+	if _, err := titles.SaveTitleWithSnapshot(ctx, title, jun); err != nil {
+		t.Fatal(err)
+	}
+
+	parent := &model.Agency{AgencyName: "Executive Office of the President", Slug: "eop"}
+	if err := agencies.UpsertAgency(ctx, parent); err != nil {
+		t.Fatal(err)
+	}
+	child := &model.Agency{AgencyName: "Office of Management and Budget", ShortName: sql.NullString{String: "OMB", Valid: true},
+		Slug: "omb", ParentID: sql.NullInt64{Int64: int64(parent.ID), Valid: true}}
+	if err := agencies.UpsertAgency(ctx, child); err != nil {
+		t.Fatal(err)
+	}
+	for _, a := range []*model.Agency{parent, child} {
+		if err := agencies.LinkAgencyTitle(ctx, a.ID, 1); err != nil {
+			t.Fatal(err)
+		}
+		if err := agencies.UpdateWordCount(ctx, a.ID, 120, 1, "c"); err != nil {
+			t.Fatal(err)
+		}
+		snap := &model.AgencySnapshot{AgencyID: a.ID, AgencyName: a.AgencyName, TotalWordCount: 120, RegulationCount: 1, Checksum: "c", SnapshotDate: jun}
+		if _, err := agencies.InsertSnapshotIfChanged(ctx, snap, []int{1}); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	app := fiber.New()
+	app.Use(handlers.AsOfMiddleware())
+	handlers.RegisterAPIRoutes(app, titles, agencies)
+	return app
+}
+
+// TestAPIMatchesSpec requests every route in the OpenAPI document, along
+// with its error cases, and validates each body against the schema the
+// document declares for the response's status
+func TestAPIMatchesSpec(t *testing.T) {
+	app := newAPIApp(t)
+	spec := handlers.OpenAPISpec()
+
+	var paths []string
+	for path := range spec.Paths {
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+
+	get := func(t *testing.T, path string) (int, []byte) {
+		resp, err := app.Test(httptest.NewRequest("GET", path, nil), -1)
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer resp.Body.Close()
+		body, err := io.ReadAll(resp.Body)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return resp.StatusCode, body
+	}
+
+	for _, specPath := range paths {
+		type request struct {
+			path   string
+			status int
+		}
+		fill := func(number, slug string) string {
+			return strings.NewReplacer("{number}", number, "{slug}", slug).Replace(specPath)
+		}
+
+		requests := []request{
+			{fill("1", "omb"), fiber.StatusOK},
+			{fill("1", "eop") + "?as_of=2024-07-01", fiber.StatusOK},
+		}
+		if specPath != handlers.APIPrefix+"/openapi.json" {
+			requests = append(requests, request{fill("1", "omb") + "?as_of=March", fiber.StatusBadRequest})
+		}
+		if strings.Contains(specPath, "{number}") {
+			requests = append(requests,
+				request{fill("99", ""), fiber.StatusNotFound},
+				request{fill("abc", ""), fiber.StatusBadRequest},
+				// Title 1 did not exist yet
+				request{fill("1", "") + "?as_of=2023-12-31", fiber.StatusNotFound},
+			)
+		}
+		if strings.Contains(specPath, "{slug}") {
+			requests = append(requests, request{fill("", "unknown"), fiber.StatusNotFound})
+		}
+
+		for _, r := range requests {
+			t.Run(r.path, func(t *testing.T) {
+				status, body := get(t, r.path)
+				if status != r.status {
+					t.Fatalf("status = %d, want %d: %s", status, r.status, body)
+				}
+
+				schema, err := spec.ResponseSchema(specPath, strconv.Itoa(status))
+				if err != nil {
+					t.Fatal(err)
+				}
+				for _, err := range spec.ValidateJSON(schema, body) {
+					t.Error(err)
+				}
+			})
+		}
+	}
+
+	// The served document is the one the routes were checked against
+	_, served := get(t, handlers.APIPrefix+"/openapi.json")
+	want, err := json.Marshal(spec)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var a, b any
+	if err := json.Unmarshal(served, &a); err != nil {
+		t.Fatal(err)
+	}
+	if err := json.Unmarshal(want, &b); err != nil {
+		t.Fatal(err)
+	}
+	ca, _ := json.Marshal(a)
+	cb, _ := json.Marshal(b)
+	if string(ca) != string(cb) {
+		t.Errorf("served spec differs from OpenAPISpec")
+	}
+}
@@ -0,0 +1,43 @@
+package handlers
+
+import (
+	"strings"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/jjenkins/usds/internal/store"
+)
+
+// AsOfMiddleware parses the as_of=YYYY-MM-DD query parameter shared by every
+// page and API route. Dates before today select a point-in-time view; today
+// or later is the current state.
+func AsOfMiddleware() fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		value := c.Query("as_of")
+		if value == "" {
+			return c.Next()
+		}
+
+		date, err := time.Parse("2006-01-02", value)
+		if err != nil {
+			if strings.HasPrefix(c.Path(), APIPrefix) {
+				return apiError(c, fiber.StatusBadRequest, "Invalid as_of date, expected YYYY-MM-DD")
+			}
+			return c.Status(fiber.StatusBadRequest).SendString("Invalid as_of date, expected YYYY-MM-DD")
+		}
+
+		today := time.Now().UTC().Truncate(24 * time.Hour)
+		if date.Before(today) {
+			c.Locals(store.AsOfContextKey, date)
+		}
+
+		return c.Next()
+	}
+}
+
+// asOf returns the point-in-time date selected for this request, or the zero
+// time for the current state
+func asOf(c *fiber.Ctx) time.Time {
+	date, _ := c.Locals(store.AsOfContextKey).(time.Time)
+	return date
+}
@@ -0,0 +1,46 @@
+package handlers
+
+import (
+	"github.com/a-h/templ"
+	"github.com/gofiber/fiber/v2"
+	"github.com/gofiber/fiber/v2/middleware/adaptor"
+	"github.com/jjenkins/usds/internal/service"
+	"github.com/jjenkins/usds/internal/store"
+	"github.com/jjenkins/usds/internal/templates"
+)
+
+func CompareHandler(agencyStore store.AgencyRepository) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		ctx := c.UserContext()
+		agencyStore := agencyStore.AsOf(asOf(c))
+
+		// Accept both ?agencies=a,b,c and the picker's repeated ?agency= values
+		values := []string{c.Query("agencies")}
+		for _, v := range c.Context().QueryArgs().PeekMulti("agency") {
+			values = append(values, string(v))
+		}
+		slugs := service.ParseAgencySlugs(values...)
+
+		if len(slugs) > service.MaxComparedAgencies {
+			return c.Status(fiber.StatusBadRequest).SendString("Too many agencies to compare")
+		}
+
+		allAgencies, err := agencyStore.GetAll(ctx)
+		if err != nil {
+			return internalError(c, err, "Error loading agencies")
+		}
+
+		var comparison *service.AgencyComparison
+		if len(slugs) > 0 {
+			comparison, err = service.CompareAgencies(ctx, agencyStore, slugs)
+			if err != nil {
+				return internalError(c, err, "Error comparing agencies")
+			}
+		}
+
+		page := templates.Compare(comparison, allAgencies, slugs)
+		handler := adaptor.HTTPHandler(templ.Handler(page))
+
+		return handler(c)
+	}
+}
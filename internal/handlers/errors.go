@@ -0,0 +1,37 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// internalError logs err against the request, so its request ID leads back
+// to the cause, and responds with a 500 carrying only message. Queries cut
+// off by the route's timeout or by shutdown get a 503 instead.
+func internalError(c *fiber.Ctx, err error, message string) error {
+	status := errorStatus(c, err, message)
+	return c.Status(status).SendString(message)
+}
+
+// apiInternalError is internalError for the JSON API
+func apiInternalError(c *fiber.Ctx, err error, message string) error {
+	status := errorStatus(c, err, message)
+	return apiError(c, status, message)
+}
+
+// errorStatus logs err and picks the response status for it
+func errorStatus(c *fiber.Ctx, err error, message string) int {
+	ctx := c.UserContext()
+	// Drivers report a cancelled query in their own words, so check the
+	// request's context as well as the error
+	if ctx.Err() != nil || errors.Is(err, context.DeadlineExceeded) || errors.Is(err, context.Canceled) {
+		slog.WarnContext(ctx, message, "error", err, "path", c.Path())
+		c.Set(fiber.HeaderRetryAfter, "5")
+		return fiber.StatusServiceUnavailable
+	}
+	slog.ErrorContext(ctx, message, "error", err, "path", c.Path())
+	return fiber.StatusInternalServerError
+}
@@ -0,0 +1,25 @@
+package handlers
+
+import (
+	"bytes"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/jjenkins/usds/internal/export"
+)
+
+// sendExport writes a table as a file download in the requested format
+func sendExport(c *fiber.Ctx, formatName string, table *export.Table) error {
+	format, err := export.ParseFormat(formatName)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).SendString(err.Error())
+	}
+
+	var buf bytes.Buffer
+	if err := export.Write(&buf, format, table); err != nil {
+		return internalError(c, err, "Error generating export")
+	}
+
+	c.Set(fiber.HeaderContentType, format.ContentType())
+	c.Attachment(table.Filename(format))
+	return c.Send(buf.Bytes())
+}
@@ -0,0 +1,114 @@
+package handlers
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/gorilla/feeds"
+	"github.com/jjenkins/usds/internal/model"
+	"github.com/jjenkins/usds/internal/store"
+)
+
+// FeedFormat is a syndication format for the change feed
+type FeedFormat string
+
+const (
+	FeedAtom FeedFormat = "atom"
+	FeedRSS  FeedFormat = "rss"
+	FeedJSON FeedFormat = "json"
+)
+
+const changeFeedLimit = 50
+
+// ChangeFeedHandler serves recent change events as a feed, filterable with
+// ?title=<number> or ?agency=<slug>
+func ChangeFeedHandler(changeStore *store.ChangeStore, format FeedFormat) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		ctx := c.UserContext()
+
+		filter := store.ChangeFilter{
+			TitleNumber: c.QueryInt("title"),
+			AgencySlug:  c.Query("agency"),
+			Limit:       changeFeedLimit,
+		}
+
+		events, err := changeStore.List(ctx, filter)
+		if err != nil {
+			return internalError(c, err, "Error loading changes")
+		}
+
+		feed := buildChangeFeed(c.BaseURL(), filter, events)
+
+		var body, contentType string
+		switch format {
+		case FeedAtom:
+			body, err = feed.ToAtom()
+			contentType = "application/atom+xml; charset=utf-8"
+		case FeedRSS:
+			body, err = feed.ToRss()
+			contentType = "application/rss+xml; charset=utf-8"
+		case FeedJSON:
+			body, err = feed.ToJSON()
+			contentType = "application/feed+json; charset=utf-8"
+		}
+		if err != nil {
+			return internalError(c, err, "Error rendering feed")
+		}
+
+		c.Set(fiber.HeaderContentType, contentType)
+		return c.SendString(body)
+	}
+}
+
+// buildChangeFeed turns change events into a feed with one item per event
+func buildChangeFeed(baseURL string, filter store.ChangeFilter, events []model.ChangeEvent) *feeds.Feed {
+	title := "eCFR Analyzer: Regulatory Changes"
+	link := baseURL + "/history"
+	switch {
+	case filter.TitleNumber > 0:
+		title = fmt.Sprintf("eCFR Analyzer: Title %d Changes", filter.TitleNumber)
+		link = fmt.Sprintf("%s/titles/%d", baseURL, filter.TitleNumber)
+	case filter.AgencySlug != "":
+		title = fmt.Sprintf("eCFR Analyzer: %s Changes", filter.AgencySlug)
+		link = fmt.Sprintf("%s/agencies/%s", baseURL, filter.AgencySlug)
+	}
+
+	feed := &feeds.Feed{
+		Title:       title,
+		Link:        &feeds.Link{Href: link},
+		Description: "CFR titles and agencies whose regulation text changed between snapshots",
+		Id:          baseURL + "/feeds/changes",
+		Updated:     time.Now(),
+	}
+	if len(events) > 0 {
+		feed.Updated = events[0].CreatedAt
+	}
+
+	for _, e := range events {
+		itemLink := fmt.Sprintf("%s/titles/%d", baseURL, e.TitleNumber.Int64)
+		if e.EntityType == model.ChangeEntityAgency {
+			itemLink = fmt.Sprintf("%s/agencies/%s", baseURL, e.AgencySlug.String)
+		}
+
+		title := fmt.Sprintf("%s changed (%+d words)", e.EntityName, e.WordCountChange())
+		description := fmt.Sprintf("Word count went from %d on %s to %d on %s.",
+			e.PreviousWordCount.Int64, e.PreviousSnapshotDate.Time.Format("January 2, 2006"),
+			e.WordCount, e.SnapshotDate.Format("January 2, 2006"))
+		if e.First() {
+			title = fmt.Sprintf("%s first recorded (%d words)", e.EntityName, e.WordCount)
+			description = fmt.Sprintf("First snapshot, with %d words on %s.", e.WordCount, e.SnapshotDate.Format("January 2, 2006"))
+		}
+
+		feed.Add(&feeds.Item{
+			Title:       title,
+			Link:        &feeds.Link{Href: itemLink},
+			Description: description,
+			Id:          fmt.Sprintf("%s/feeds/changes/%d", baseURL, e.ID),
+			Created:     e.CreatedAt,
+			Updated:     e.CreatedAt,
+		})
+	}
+
+	return feed
+}
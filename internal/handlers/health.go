@@ -0,0 +1,136 @@
+package handlers
+
+import (
+	"context"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/jjenkins/usds/internal/store"
+)
+
+// readyCheckTimeout bounds each readiness check so a hung database fails the
+// probe instead of stalling it
+const readyCheckTimeout = 2 * time.Second
+
+// Readiness check statuses
+const (
+	checkOK    = "ok"
+	checkError = "error"
+	checkStale = "stale"
+	checkEmpty = "empty"
+)
+
+// readiness is the /readyz response
+type readiness struct {
+	Status string         `json:"status"` // "ready" or "not_ready"
+	Checks readinessCheck `json:"checks"`
+}
+
+type readinessCheck struct {
+	Database databaseCheck `json:"database"`
+	Schema   schemaCheck   `json:"schema"`
+	Data     dataCheck     `json:"data"`
+}
+
+type databaseCheck struct {
+	Status    string `json:"status"`
+	LatencyMS int64  `json:"latency_ms"`
+	Error     string `json:"error,omitempty"`
+}
+
+type schemaCheck struct {
+	Status   string `json:"status"`
+	Version  int    `json:"version"`
+	Expected int    `json:"expected"`
+	Error    string `json:"error,omitempty"`
+}
+
+type dataCheck struct {
+	Status          string     `json:"status"`
+	NewestFetchedAt *time.Time `json:"newest_fetched_at"`
+	AgeSeconds      int64      `json:"age_seconds"`
+	MaxAgeSeconds   int64      `json:"max_age_seconds,omitempty"`
+	Error           string     `json:"error,omitempty"`
+}
+
+// HealthzHandler reports that the process is up, without checking anything
+// it depends on
+func HealthzHandler() fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		return c.JSON(fiber.Map{"status": "ok"})
+	}
+}
+
+// ReadyzHandler checks that the database answers, its schema is current and
+// the newest title was fetched within maxAge, returning 503 with the
+// breakdown if any check fails. A maxAge of zero only reports freshness.
+func ReadyzHandler(healthStore *store.HealthStore, maxAge time.Duration) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		var result readiness
+		checks := &result.Checks
+		ready := true
+
+		ctx, cancel := context.WithTimeout(context.Background(), readyCheckTimeout)
+		start := time.Now()
+		err := healthStore.Ping(ctx)
+		cancel()
+		checks.Database.LatencyMS = time.Since(start).Milliseconds()
+		checks.Database.Status = checkOK
+		if err != nil {
+			checks.Database.Status = checkError
+			checks.Database.Error = err.Error()
+			ready = false
+		}
+
+		ctx, cancel = context.WithTimeout(context.Background(), readyCheckTimeout)
+		version, err := healthStore.SchemaVersion(ctx)
+		cancel()
+		checks.Schema.Version = version
+		checks.Schema.Expected = store.SchemaVersion
+		switch {
+		case err != nil:
+			checks.Schema.Status = checkError
+			checks.Schema.Error = err.Error()
+			ready = false
+		case version < store.SchemaVersion:
+			checks.Schema.Status = checkError
+			checks.Schema.Error = "schema is out of date, apply internal/db/schema.sql"
+			ready = false
+		default:
+			checks.Schema.Status = checkOK
+		}
+
+		ctx, cancel = context.WithTimeout(context.Background(), readyCheckTimeout)
+		fetchedAt, err := healthStore.NewestFetchedAt(ctx)
+		cancel()
+		checks.Data.MaxAgeSeconds = int64(maxAge.Seconds())
+		switch {
+		case err != nil:
+			checks.Data.Status = checkError
+			checks.Data.Error = err.Error()
+			ready = false
+		case !fetchedAt.Valid:
+			checks.Data.Status = checkEmpty
+			ready = false
+		default:
+			age := time.Since(fetchedAt.Time)
+			checks.Data.NewestFetchedAt = &fetchedAt.Time
+			checks.Data.AgeSeconds = int64(age.Seconds())
+			checks.Data.Status = checkOK
+			if maxAge > 0 && age > maxAge {
+				checks.Data.Status = checkStale
+				ready = false
+			}
+		}
+
+		result.Status = "ready"
+		status := fiber.StatusOK
+		if !ready {
+			result.Status = "not_ready"
+			status = fiber.StatusServiceUnavailable
+		}
+
+		c.Set(fiber.HeaderCacheControl, "no-store")
+		return c.Status(status).JSON(result)
+	}
+}
@@ -2,6 +2,7 @@ package handlers
 
 import (
 	"context"
+	"fmt"
 	"time"
 
 	"github.com/a-h/templ"
@@ -18,47 +19,20 @@ type SnapshotSummary struct {
 	AgencyCount int
 }
 
-func HistoryHandler(titleStore *store.TitleStore, agencyStore *store.AgencyStore) fiber.Handler {
+func HistoryHandler(titleStore store.TitleRepository, agencyStore store.AgencyRepository) fiber.Handler {
 	return func(c *fiber.Ctx) error {
-		ctx := context.Background()
+		ctx := c.UserContext()
+		titleStore := titleStore.AsOf(asOf(c))
+		agencyStore := agencyStore.AsOf(asOf(c))
 
-		// Get unique snapshot dates from titles
-		titleDates, err := titleStore.GetSnapshotDates(ctx)
+		dates, err := loadSnapshotDates(ctx, titleStore, agencyStore)
 		if err != nil {
-			return c.Status(fiber.StatusInternalServerError).SendString("Error loading title snapshots")
+			return internalError(c, err, "Error loading snapshots")
 		}
 
-		// Get unique snapshot dates from agencies
-		agencyDates, err := agencyStore.GetAgencySnapshotDates(ctx)
+		totals, err := titleStore.GetSnapshotTotals(ctx)
 		if err != nil {
-			return c.Status(fiber.StatusInternalServerError).SendString("Error loading agency snapshots")
-		}
-
-		// Merge and dedupe dates
-		dateMap := make(map[string]time.Time)
-		for _, d := range titleDates {
-			key := d.Format("2006-01-02")
-			dateMap[key] = d
-		}
-		for _, d := range agencyDates {
-			key := d.Format("2006-01-02")
-			if _, exists := dateMap[key]; !exists {
-				dateMap[key] = d
-			}
-		}
-
-		// Convert to sorted slice
-		var dates []time.Time
-		for _, d := range dateMap {
-			dates = append(dates, d)
-		}
-		// Sort descending
-		for i := 0; i < len(dates)-1; i++ {
-			for j := i + 1; j < len(dates); j++ {
-				if dates[j].After(dates[i]) {
-					dates[i], dates[j] = dates[j], dates[i]
-				}
-			}
+			return internalError(c, err, "Error loading snapshot totals")
 		}
 
 		// Get current totals
@@ -66,9 +40,53 @@ func HistoryHandler(titleStore *store.TitleStore, agencyStore *store.AgencyStore
 		totalWords, _ := titleStore.GetTotalWordCount(ctx)
 		totalAgencies, _ := agencyStore.CountAgencies(ctx)
 
-		page := templates.History(dates, totalTitles, totalWords, totalAgencies)
+		page := templates.History(dates, totals, totalTitles, totalWords, totalAgencies)
 		handler := adaptor.HTTPHandler(templ.Handler(page))
 
 		return handler(c)
 	}
 }
+
+// loadSnapshotDates merges title and agency snapshot dates, newest first
+func loadSnapshotDates(ctx context.Context, titleStore store.TitleRepository, agencyStore store.AgencyRepository) ([]time.Time, error) {
+	// Get unique snapshot dates from titles
+	titleDates, err := titleStore.GetSnapshotDates(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load title snapshot dates: %w", err)
+	}
+
+	// Get unique snapshot dates from agencies
+	agencyDates, err := agencyStore.GetAgencySnapshotDates(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load agency snapshot dates: %w", err)
+	}
+
+	// Merge and dedupe dates
+	dateMap := make(map[string]time.Time)
+	for _, d := range titleDates {
+		key := d.Format("2006-01-02")
+		dateMap[key] = d
+	}
+	for _, d := range agencyDates {
+		key := d.Format("2006-01-02")
+		if _, exists := dateMap[key]; !exists {
+			dateMap[key] = d
+		}
+	}
+
+	// Convert to sorted slice
+	var dates []time.Time
+	for _, d := range dateMap {
+		dates = append(dates, d)
+	}
+	// Sort descending
+	for i := 0; i < len(dates)-1; i++ {
+		for j := i + 1; j < len(dates); j++ {
+			if dates[j].After(dates[i]) {
+				dates[i], dates[j] = dates[j], dates[i]
+			}
+		}
+	}
+
+	return dates, nil
+}
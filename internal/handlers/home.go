@@ -1,8 +1,7 @@
 package handlers
 
 import (
-	"context"
-	"log"
+	"log/slog"
 
 	"github.com/a-h/templ"
 	"github.com/gofiber/fiber/v2"
@@ -11,16 +10,18 @@ import (
 	"github.com/jjenkins/usds/internal/templates"
 )
 
-func HomeHandler(titleStore *store.TitleStore, agencyStore *store.AgencyStore) fiber.Handler {
+func HomeHandler(titleStore store.TitleRepository, agencyStore store.AgencyRepository) fiber.Handler {
 	return func(c *fiber.Ctx) error {
-		ctx := context.Background()
+		ctx := c.UserContext()
+		titleStore := titleStore.AsOf(asOf(c))
+		agencyStore := agencyStore.AsOf(asOf(c))
 
 		metrics := templates.HomeMetrics{}
 
 		// Try to load metrics from database
 		totalTitles, err := titleStore.CountTitles(ctx)
 		if err != nil {
-			log.Printf("Error counting titles: %v", err)
+			slog.ErrorContext(ctx, "Error counting titles", "error", err)
 		} else {
 			metrics.TotalTitles = totalTitles
 			metrics.HasData = totalTitles > 0
@@ -29,21 +30,21 @@ func HomeHandler(titleStore *store.TitleStore, agencyStore *store.AgencyStore) f
 		if metrics.HasData {
 			totalWords, err := titleStore.GetTotalWordCount(ctx)
 			if err != nil {
-				log.Printf("Error getting total word count: %v", err)
+				slog.ErrorContext(ctx, "Error getting total word count", "error", err)
 			} else {
 				metrics.TotalWords = totalWords
 			}
 
 			avgDensity, err := titleStore.GetAverageDensity(ctx)
 			if err != nil {
-				log.Printf("Error getting average density: %v", err)
+				slog.ErrorContext(ctx, "Error getting average density", "error", err)
 			} else {
 				metrics.AverageDensity = avgDensity
 			}
 
 			totalAgencies, err := agencyStore.CountAgencies(ctx)
 			if err != nil {
-				log.Printf("Error counting agencies: %v", err)
+				slog.ErrorContext(ctx, "Error counting agencies", "error", err)
 			} else {
 				metrics.TotalAgencies = totalAgencies
 			}
@@ -0,0 +1,238 @@
+package handlers
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/a-h/templ"
+	"github.com/gofiber/fiber/v2"
+	"github.com/gofiber/fiber/v2/middleware/adaptor"
+	"github.com/jjenkins/usds/internal/auth"
+	"github.com/jjenkins/usds/internal/model"
+	"github.com/jjenkins/usds/internal/service"
+	"github.com/jjenkins/usds/internal/store"
+	"github.com/jjenkins/usds/internal/templates"
+)
+
+const (
+	sseHeartbeat      = 15 * time.Second // Keeps idle import log streams open through proxies
+	importRunsShown   = 50
+	importFailuresMax = 500
+)
+
+// ImportsHandler shows the form for starting an import, the running import
+// and the history of past runs
+func ImportsHandler(runner *service.ImportRunner, runStore *store.ImportRunStore) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		ctx := c.UserContext()
+
+		runs, err := runStore.ListRuns(ctx, store.ImportRunFilter{Limit: importRunsShown})
+		if err != nil {
+			return internalError(c, err, "Error loading import runs")
+		}
+
+		active, running := runner.Active()
+		var activeRun *model.ImportRun
+		if running {
+			activeRun = &active
+		}
+
+		page := templates.Imports(runs, activeRun, c.Query("error"))
+		handler := adaptor.HTTPHandler(templ.Handler(page))
+
+		return handler(c)
+	}
+}
+
+// ImportStartHandler starts an import from the form and redirects to its
+// live log
+func ImportStartHandler(runner *service.ImportRunner) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		req, err := importRequest(c)
+		if err == nil {
+			var run model.ImportRun
+			run, err = runner.Start(req, startedBy(c))
+			if err == nil {
+				return c.Redirect(fmt.Sprintf("/admin/imports/%d", run.ID), fiber.StatusSeeOther)
+			}
+		}
+
+		if errors.Is(err, service.ErrImportRunning) {
+			if active, running := runner.Active(); running {
+				return c.Redirect(fmt.Sprintf("/admin/imports/%d", active.ID), fiber.StatusSeeOther)
+			}
+		}
+		return c.Redirect("/admin/imports?error="+url.QueryEscape(err.Error()), fiber.StatusSeeOther)
+	}
+}
+
+// ImportRunHandler shows one run's stats, failures and log. Runs this server
+// is executing show their live counts; the log is only available for recent
+// runs this server started.
+func ImportRunHandler(runner *service.ImportRunner, runStore *store.ImportRunStore) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		ctx := c.UserContext()
+
+		id, err := c.ParamsInt("id")
+		if err != nil {
+			return c.Status(fiber.StatusBadRequest).SendString("Invalid import run")
+		}
+
+		run, err := runStore.GetRun(ctx, id)
+		if err != nil {
+			return internalError(c, err, "Error loading import run")
+		}
+		if run == nil {
+			return c.Status(fiber.StatusNotFound).SendString("Import run not found")
+		}
+
+		live, lines, found := runner.Run(id)
+		if found && !live.Finished() {
+			run = &live
+		}
+
+		failures, err := runStore.ListFailures(ctx, store.FailureFilter{RunID: id, Limit: importFailuresMax})
+		if err != nil {
+			return internalError(c, err, "Error loading import failures")
+		}
+
+		page := templates.ImportRunDetail(*run, failures, lines, found)
+		handler := adaptor.HTTPHandler(templ.Handler(page))
+
+		return handler(c)
+	}
+}
+
+// ImportCancelHandler cancels a running import
+func ImportCancelHandler(runner *service.ImportRunner) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		id, err := c.ParamsInt("id")
+		if err != nil {
+			return c.Status(fiber.StatusBadRequest).SendString("Invalid import run")
+		}
+
+		runner.Cancel(id)
+		return c.Redirect(fmt.Sprintf("/admin/imports/%d", id), fiber.StatusSeeOther)
+	}
+}
+
+// ImportEventsHandler streams a run's log as server-sent events: a "log"
+// event per line, numbered so a reconnecting EventSource resumes where it
+// left off, then a "done" event carrying the final status
+func ImportEventsHandler(runner *service.ImportRunner) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		id, err := c.ParamsInt("id")
+		if err != nil {
+			return c.Status(fiber.StatusBadRequest).SendString("Invalid import run")
+		}
+
+		after, _ := strconv.Atoi(c.Get("Last-Event-ID"))
+		sub, found := runner.Subscribe(id, after)
+		if !found {
+			return c.Status(fiber.StatusNotFound).SendString("Import run not found")
+		}
+
+		c.Set(fiber.HeaderContentType, "text/event-stream")
+		c.Set(fiber.HeaderCacheControl, "no-cache")
+		c.Set(fiber.HeaderConnection, "keep-alive")
+		c.Set("X-Accel-Buffering", "no")
+
+		c.Context().SetBodyStreamWriter(func(w *bufio.Writer) {
+			defer sub.Close()
+
+			next := sub.First
+			for _, line := range sub.Lines {
+				writeLogEvent(w, next, line)
+				next++
+			}
+			if w.Flush() != nil {
+				return
+			}
+
+			heartbeat := time.NewTicker(sseHeartbeat)
+			defer heartbeat.Stop()
+
+			for {
+				select {
+				case line, ok := <-sub.Updates:
+					if !ok {
+						run, _, _ := runner.Run(id)
+						fmt.Fprintf(w, "event: done\ndata: %s\n\n", run.Status)
+						w.Flush()
+						return
+					}
+					writeLogEvent(w, next, line)
+					next++
+				case <-heartbeat.C:
+					fmt.Fprint(w, ": ping\n\n")
+				}
+				// A failed flush means the client went away
+				if w.Flush() != nil {
+					return
+				}
+			}
+		})
+
+		return nil
+	}
+}
+
+func writeLogEvent(w *bufio.Writer, id int, line string) {
+	fmt.Fprintf(w, "id: %d\nevent: log\ndata: %s\n\n", id, strings.ReplaceAll(line, "\r", ""))
+}
+
+// importRequest reads an import request from the start form
+func importRequest(c *fiber.Ctx) (service.ImportRequest, error) {
+	req := service.ImportRequest{Mode: c.FormValue("mode")}
+
+	if title := c.FormValue("title"); title != "" {
+		number, err := strconv.Atoi(title)
+		if err != nil {
+			return req, fmt.Errorf("invalid title %q", title)
+		}
+		req.TitleNumber = number
+	}
+
+	dates := []struct {
+		field string
+		dest  *time.Time
+	}{
+		{"date", &req.Date},
+		{"from", &req.From},
+		{"to", &req.To},
+	}
+	for _, d := range dates {
+		value := c.FormValue(d.field)
+		if value == "" {
+			continue
+		}
+		parsed, err := time.Parse("2006-01-02", value)
+		if err != nil {
+			return req, fmt.Errorf("invalid %s date %q", d.field, value)
+		}
+		*d.dest = parsed
+	}
+
+	// The form submits every field; drop those the mode doesn't use
+	switch req.Mode {
+	case model.ImportModeFull, model.ImportModeIncremental:
+		req.TitleNumber = 0
+	case model.ImportModeRange:
+		req.Date = time.Time{}
+	}
+
+	return req, req.Validate()
+}
+
+// startedBy names the signed-in user for the run history
+func startedBy(c *fiber.Ctx) string {
+	if user := auth.CurrentUser(c); user != nil && user.Subject != "" {
+		return user.DisplayName()
+	}
+	return "admin"
+}
@@ -0,0 +1,44 @@
+package handlers
+
+import (
+	"fmt"
+	"log/slog"
+	"net/http"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/jjenkins/usds/internal/cache"
+)
+
+// Revalidate sends ETag and Last-Modified headers derived from the data
+// version and answers 304 Not Modified when the client already has that
+// version. Only use it on routes whose response depends on nothing but the
+// URL and the imported data, such as the JSON API and feeds; pages vary by
+// signed-in user.
+func Revalidate(dataCache *cache.Cache) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		if c.Method() != fiber.MethodGet && c.Method() != fiber.MethodHead {
+			return c.Next()
+		}
+
+		version, err := dataCache.Version(c.UserContext())
+		if err != nil {
+			slog.WarnContext(c.UserContext(), "Skipping revalidation headers", "error", err)
+			return c.Next()
+		}
+		if version.Version == 0 {
+			return c.Next()
+		}
+
+		c.Set(fiber.HeaderETag, fmt.Sprintf(`W/"v%d"`, version.Version))
+		if !version.UpdatedAt.IsZero() {
+			c.Set(fiber.HeaderLastModified, version.UpdatedAt.UTC().Format(http.TimeFormat))
+		}
+		// Shared caches may keep the response but must check it is current
+		c.Set(fiber.HeaderCacheControl, "public, no-cache")
+
+		if c.Fresh() {
+			return c.SendStatus(fiber.StatusNotModified)
+		}
+		return c.Next()
+	}
+}
@@ -0,0 +1,77 @@
+package handlers
+
+import (
+	"strings"
+
+	"github.com/a-h/templ"
+	"github.com/gofiber/fiber/v2"
+	"github.com/gofiber/fiber/v2/middleware/adaptor"
+	"github.com/jjenkins/usds/internal/store"
+	"github.com/jjenkins/usds/internal/templates"
+)
+
+const searchPageSize = 20
+
+func SearchHandler(sectionStore *store.SectionStore, titleStore store.TitleRepository, agencyStore store.AgencyRepository, watchlistStore *store.WatchlistStore) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		ctx := c.UserContext()
+		sectionStore := sectionStore.AsOf(asOf(c))
+
+		form := templates.SearchForm{
+			Query:       strings.TrimSpace(c.Query("q")),
+			TitleNumber: c.QueryInt("title"),
+			AgencySlug:  c.Query("agency"),
+			AsOf:        c.Query("as_of"),
+			Page:        c.QueryInt("page", 1),
+			PageSize:    searchPageSize,
+		}
+		if form.Page < 1 {
+			form.Page = 1
+		}
+
+		// Filter choices come from the current state; the snapshot date
+		// choices are every recorded snapshot
+		titles, err := titleStore.GetAll(ctx)
+		if err != nil {
+			return internalError(c, err, "Error loading titles")
+		}
+		agencies, err := agencyStore.GetAll(ctx)
+		if err != nil {
+			return internalError(c, err, "Error loading agencies")
+		}
+		dates, err := loadSnapshotDates(ctx, titleStore, agencyStore)
+		if err != nil {
+			return internalError(c, err, "Error loading snapshots")
+		}
+
+		indexed, err := sectionStore.CountSections(ctx)
+		if err != nil {
+			return internalError(c, err, "Error loading sections")
+		}
+
+		var hits []store.SearchHit
+		var total int
+		if form.Query != "" && indexed > 0 {
+			hits, total, err = sectionStore.Search(ctx, store.SearchQuery{
+				Query:       form.Query,
+				TitleNumber: form.TitleNumber,
+				AgencySlug:  form.AgencySlug,
+				Limit:       searchPageSize,
+				Offset:      (form.Page - 1) * searchPageSize,
+			})
+			if err != nil {
+				return internalError(c, err, "Error searching sections")
+			}
+		}
+
+		watched, err := watchlistStore.WatchedKeys(ctx, currentUser(c))
+		if err != nil {
+			return internalError(c, err, "Error loading watchlist")
+		}
+
+		page := templates.Search(form, titles, agencies, dates, hits, total, indexed > 0, watched)
+		handler := adaptor.HTTPHandler(templ.Handler(page))
+
+		return handler(c)
+	}
+}
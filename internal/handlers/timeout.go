@@ -0,0 +1,34 @@
+package handlers
+
+import (
+	"context"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// QueryTimeout bounds the store calls a route makes by giving the request's
+// user context a deadline. The context is also cancelled if the server's
+// shutdown drain runs out. A zero timeout leaves the request unbounded.
+//
+// A client disconnecting does not cancel the context: fasthttp neither
+// watches the connection while a handler runs nor cancels its request
+// context when the client goes away, so an abandoned request's queries run
+// until they finish or hit the timeout.
+func QueryTimeout(timeout time.Duration) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		if timeout <= 0 {
+			return c.Next()
+		}
+
+		ctx, cancel := context.WithTimeout(c.UserContext(), timeout)
+		defer cancel()
+
+		// fasthttp closes the request context's Done channel on shutdown
+		stop := context.AfterFunc(c.Context(), cancel)
+		defer stop()
+
+		c.SetUserContext(ctx)
+		return c.Next()
+	}
+}
@@ -1,26 +1,32 @@
 package handlers
 
 import (
-	"context"
 	"strconv"
 
 	"github.com/a-h/templ"
 	"github.com/gofiber/fiber/v2"
 	"github.com/gofiber/fiber/v2/middleware/adaptor"
+	"github.com/jjenkins/usds/internal/export"
+	"github.com/jjenkins/usds/internal/model"
 	"github.com/jjenkins/usds/internal/store"
 	"github.com/jjenkins/usds/internal/templates"
 )
 
-func TitlesHandler(titleStore *store.TitleStore) fiber.Handler {
+func TitlesHandler(titleStore store.TitleRepository) fiber.Handler {
 	return func(c *fiber.Ctx) error {
-		ctx := context.Background()
+		ctx := c.UserContext()
+		titleStore := titleStore.AsOf(asOf(c))
 
 		sortBy := c.Query("sort", "number")
 		order := c.Query("order", "asc")
 
 		titles, err := titleStore.GetAllSortedWithDensity(ctx, sortBy, order)
 		if err != nil {
-			return c.Status(fiber.StatusInternalServerError).SendString("Error loading titles")
+			return internalError(c, err, "Error loading titles")
+		}
+
+		if format := c.Query("format"); format != "" {
+			return sendExport(c, format, export.Titles(titles))
 		}
 
 		// Check if this is an HTMX request for just the table body
@@ -37,9 +43,10 @@ func TitlesHandler(titleStore *store.TitleStore) fiber.Handler {
 	}
 }
 
-func TitleDetailHandler(titleStore *store.TitleStore) fiber.Handler {
+func TitleDetailHandler(titleStore store.TitleRepository, watchlistStore *store.WatchlistStore) fiber.Handler {
 	return func(c *fiber.Ctx) error {
-		ctx := context.Background()
+		ctx := c.UserContext()
+		titleStore := titleStore.AsOf(asOf(c))
 
 		numberStr := c.Params("number")
 		number, err := strconv.Atoi(numberStr)
@@ -49,7 +56,7 @@ func TitleDetailHandler(titleStore *store.TitleStore) fiber.Handler {
 
 		title, err := titleStore.GetByNumber(ctx, number)
 		if err != nil {
-			return c.Status(fiber.StatusInternalServerError).SendString("Error loading title")
+			return internalError(c, err, "Error loading title")
 		}
 		if title == nil {
 			return c.Status(fiber.StatusNotFound).SendString("Title not found")
@@ -57,18 +64,24 @@ func TitleDetailHandler(titleStore *store.TitleStore) fiber.Handler {
 
 		snapshots, err := titleStore.GetSnapshots(ctx, number)
 		if err != nil {
-			return c.Status(fiber.StatusInternalServerError).SendString("Error loading snapshots")
+			return internalError(c, err, "Error loading snapshots")
+		}
+
+		if format := c.Query("format"); format != "" {
+			return sendExport(c, format, export.TitleSnapshots(number, snapshots))
 		}
 
 		agencies, err := titleStore.GetAgenciesForTitle(ctx, number)
 		if err != nil {
-			return c.Status(fiber.StatusInternalServerError).SendString("Error loading agencies")
+			return internalError(c, err, "Error loading agencies")
 		}
 
 		// Calculate density score
 		densityScore, _ := titleStore.GetDensityScoreForTitle(ctx, title)
 
-		page := templates.TitleDetail(title, snapshots, agencies, densityScore)
+		watching := watchState(c, watchlistStore, model.TitleTarget(number))
+
+		page := templates.TitleDetail(title, snapshots, agencies, densityScore, watching)
 		handler := adaptor.HTTPHandler(templ.Handler(page))
 
 		return handler(c)
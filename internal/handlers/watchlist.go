@@ -0,0 +1,145 @@
+package handlers
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"log/slog"
+	"strconv"
+	"time"
+
+	"github.com/a-h/templ"
+	"github.com/gofiber/fiber/v2"
+	"github.com/gofiber/fiber/v2/middleware/adaptor"
+	"github.com/jjenkins/usds/internal/auth"
+	"github.com/jjenkins/usds/internal/model"
+	"github.com/jjenkins/usds/internal/store"
+	"github.com/jjenkins/usds/internal/templates"
+)
+
+const userCookie = "usds_user"
+
+// currentUser returns the ID that watchlists are stored under: the OIDC
+// subject when signed in, otherwise a long-lived anonymous ID cookie issued
+// on the visitor's first request
+func currentUser(c *fiber.Ctx) string {
+	if user := auth.CurrentUser(c); user != nil && user.Subject != "" {
+		return user.Subject
+	}
+	if id := c.Cookies(userCookie); id != "" {
+		return id
+	}
+
+	buf := make([]byte, 16)
+	rand.Read(buf)
+	id := hex.EncodeToString(buf)
+
+	c.Cookie(&fiber.Cookie{
+		Name:     userCookie,
+		Value:    id,
+		Path:     "/",
+		Expires:  time.Now().AddDate(2, 0, 0),
+		HTTPOnly: true,
+		SameSite: fiber.CookieSameSiteLaxMode,
+	})
+	return id
+}
+
+// watchTarget reads the watch target from a toggle form
+func watchTarget(c *fiber.Ctx) (model.WatchTarget, bool) {
+	number, _ := strconv.Atoi(c.FormValue("title"))
+
+	switch c.FormValue("type") {
+	case model.ChangeEntityTitle:
+		return model.TitleTarget(number), number > 0
+	case model.ChangeEntityAgency:
+		slug := c.FormValue("agency")
+		return model.AgencyTarget(slug), slug != ""
+	case model.WatchEntitySection:
+		section := c.FormValue("section")
+		return model.SectionTarget(number, section), number > 0 && section != ""
+	}
+	return model.WatchTarget{}, false
+}
+
+// WatchlistHandler shows the entities the current user watches
+func WatchlistHandler(watchlistStore *store.WatchlistStore) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		ctx := c.UserContext()
+
+		items, err := watchlistStore.List(ctx, currentUser(c))
+		if err != nil {
+			return internalError(c, err, "Error loading watchlist")
+		}
+
+		page := templates.Watchlist(items)
+		handler := adaptor.HTTPHandler(templ.Handler(page))
+
+		return handler(c)
+	}
+}
+
+// WatchToggleHandler watches or unwatches the posted target. HTMX requests
+// get the updated button back; plain form posts are redirected back.
+func WatchToggleHandler(watchlistStore *store.WatchlistStore) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		ctx := c.UserContext()
+		user := currentUser(c)
+
+		target, ok := watchTarget(c)
+		if !ok {
+			return c.Status(fiber.StatusBadRequest).SendString("Invalid watch target")
+		}
+
+		watching, err := watchlistStore.IsWatching(ctx, user, target)
+		if err == nil {
+			if watching {
+				err = watchlistStore.Unwatch(ctx, user, target)
+			} else {
+				err = watchlistStore.Watch(ctx, user, target)
+			}
+		}
+		if err != nil {
+			return internalError(c, err, "Error updating watchlist")
+		}
+
+		if c.Get("HX-Request") == "true" {
+			page := templates.WatchButton(target, !watching)
+			handler := adaptor.HTTPHandler(templ.Handler(page))
+			return handler(c)
+		}
+
+		return c.Redirect(c.Get(fiber.HeaderReferer, "/watchlist"), fiber.StatusSeeOther)
+	}
+}
+
+// WatchlistSeenHandler marks everything on the current user's watchlist as seen
+func WatchlistSeenHandler(watchlistStore *store.WatchlistStore) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		ctx := c.UserContext()
+
+		if err := watchlistStore.MarkAllSeen(ctx, currentUser(c)); err != nil {
+			return internalError(c, err, "Error updating watchlist")
+		}
+
+		return c.Redirect("/watchlist", fiber.StatusSeeOther)
+	}
+}
+
+// watchState reports whether the current user watches the target, marking
+// it seen when they are viewing its current state
+func watchState(c *fiber.Ctx, watchlistStore *store.WatchlistStore, target model.WatchTarget) bool {
+	ctx := c.UserContext()
+	user := currentUser(c)
+
+	watching, err := watchlistStore.IsWatching(ctx, user, target)
+	if err != nil {
+		slog.ErrorContext(ctx, "Error loading watch state", "target", target.Key(), "error", err)
+		return false
+	}
+	if watching && asOf(c).IsZero() {
+		if err := watchlistStore.MarkSeen(ctx, user, target); err != nil {
+			slog.ErrorContext(ctx, "Error marking watch seen", "target", target.Key(), "error", err)
+		}
+	}
+	return watching
+}
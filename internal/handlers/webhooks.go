@@ -0,0 +1,44 @@
+package handlers
+
+import (
+	"github.com/a-h/templ"
+	"github.com/gofiber/fiber/v2"
+	"github.com/gofiber/fiber/v2/middleware/adaptor"
+	"github.com/jjenkins/usds/internal/store"
+	"github.com/jjenkins/usds/internal/templates"
+)
+
+const deliveryLogLimit = 100
+
+// WebhooksHandler lists webhook subscriptions and the delivery log,
+// filterable with ?status= and ?subscription=
+func WebhooksHandler(webhookStore *store.WebhookStore) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		ctx := c.UserContext()
+
+		subs, err := webhookStore.ListSubscriptions(ctx)
+		if err != nil {
+			return internalError(c, err, "Error loading webhook subscriptions")
+		}
+
+		status := c.Query("status")
+		deliveries, err := webhookStore.ListDeliveries(ctx, store.DeliveryFilter{
+			Status:         status,
+			SubscriptionID: c.QueryInt("subscription"),
+			Limit:          deliveryLogLimit,
+		})
+		if err != nil {
+			return internalError(c, err, "Error loading webhook deliveries")
+		}
+
+		counts, err := webhookStore.CountDeliveriesByStatus(ctx)
+		if err != nil {
+			return internalError(c, err, "Error loading webhook deliveries")
+		}
+
+		page := templates.Webhooks(subs, deliveries, counts, status)
+		handler := adaptor.HTTPHandler(templ.Handler(page))
+
+		return handler(c)
+	}
+}
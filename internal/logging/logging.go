@@ -0,0 +1,76 @@
+package logging
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"strings"
+)
+
+type contextKey int
+
+const (
+	requestIDKey contextKey = iota
+	importRunIDKey
+)
+
+// Setup makes a JSON handler writing to w at the named level ("debug",
+// "info", "warn" or "error") the default slog logger. The standard log
+// package then writes through it too.
+func Setup(w io.Writer, level string) error {
+	var l slog.Level
+	if err := l.UnmarshalText([]byte(strings.TrimSpace(level))); err != nil {
+		return fmt.Errorf("invalid log level %q", level)
+	}
+
+	handler := slog.NewJSONHandler(w, &slog.HandlerOptions{Level: l})
+	slog.SetDefault(slog.New(&contextHandler{Handler: handler}))
+	return nil
+}
+
+// WithRequestID returns a context whose log lines carry the request ID
+func WithRequestID(ctx context.Context, id string) context.Context {
+	return context.WithValue(ctx, requestIDKey, id)
+}
+
+// RequestID returns the request ID carried by ctx, if any
+func RequestID(ctx context.Context) string {
+	id, _ := ctx.Value(requestIDKey).(string)
+	return id
+}
+
+// WithImportRunID returns a context whose log lines carry the import run ID
+func WithImportRunID(ctx context.Context, id int) context.Context {
+	return context.WithValue(ctx, importRunIDKey, id)
+}
+
+// ImportRunID returns the import run ID carried by ctx, or 0
+func ImportRunID(ctx context.Context) int {
+	id, _ := ctx.Value(importRunIDKey).(int)
+	return id
+}
+
+// contextHandler adds the correlation IDs carried by the context to each
+// record
+type contextHandler struct {
+	slog.Handler
+}
+
+func (h *contextHandler) Handle(ctx context.Context, r slog.Record) error {
+	if id := RequestID(ctx); id != "" {
+		r.AddAttrs(slog.String("request_id", id))
+	}
+	if id := ImportRunID(ctx); id != 0 {
+		r.AddAttrs(slog.Int("import_run_id", id))
+	}
+	return h.Handler.Handle(ctx, r)
+}
+
+func (h *contextHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return &contextHandler{Handler: h.Handler.WithAttrs(attrs)}
+}
+
+func (h *contextHandler) WithGroup(name string) slog.Handler {
+	return &contextHandler{Handler: h.Handler.WithGroup(name)}
+}
@@ -0,0 +1,41 @@
+package model
+
+import (
+	"database/sql"
+	"time"
+)
+
+// Change event entity types
+const (
+	ChangeEntityTitle  = "title"
+	ChangeEntityAgency = "agency"
+)
+
+// ChangeEvent records that a title or agency snapshot differed from the
+// snapshot before it, or was its first. A first snapshot has no previous
+// date or word count.
+type ChangeEvent struct {
+	ID                   int
+	EntityType           string // ChangeEntityTitle or ChangeEntityAgency
+	TitleNumber          sql.NullInt64
+	AgencyID             sql.NullInt64
+	AgencySlug           sql.NullString
+	EntityName           string
+	SnapshotDate         time.Time
+	PreviousSnapshotDate sql.NullTime
+	PreviousWordCount    sql.NullInt64
+	WordCount            int
+	Checksum             string
+	CreatedAt            time.Time
+}
+
+// WordCountChange returns the change in word count since the previous
+// snapshot, which for a first snapshot is its whole word count
+func (e ChangeEvent) WordCountChange() int {
+	return e.WordCount - int(e.PreviousWordCount.Int64)
+}
+
+// First reports whether the event is for the entity's first snapshot
+func (e ChangeEvent) First() bool {
+	return !e.PreviousSnapshotDate.Valid
+}
@@ -0,0 +1,10 @@
+package model
+
+import "time"
+
+// DataVersion counts changes to imported data. Imports bump it, so anything
+// derived from the data at one version stays valid until it changes.
+type DataVersion struct {
+	Version   int64
+	UpdatedAt time.Time
+}
@@ -0,0 +1,135 @@
+package model
+
+import (
+	"database/sql"
+	"fmt"
+	"time"
+)
+
+// Import run sources
+const (
+	ImportSourceCLI    = "cli"
+	ImportSourceWeb    = "web"
+	ImportSourceDaemon = "daemon"
+)
+
+// Import run modes
+const (
+	ImportModeFull        = "full"        // Every title and agency on one date
+	ImportModeIncremental = "incremental" // Amended titles and every agency on one date
+	ImportModeTitle       = "title"       // One title on one date
+	ImportModeRange       = "range"       // Every version published between two dates
+	ImportModeHistory     = "history"     // Every version ever published
+	ImportModeReindex     = "reindex"     // Sections missing from latest snapshots
+)
+
+// Import run statuses
+const (
+	ImportRunning   = "running"
+	ImportSucceeded = "succeeded"
+	ImportFailed    = "failed"
+	ImportCancelled = "cancelled"
+)
+
+// ImportRun records one import and its statistics
+type ImportRun struct {
+	ID                int
+	Source            string // ImportSourceCLI, ImportSourceWeb or ImportSourceDaemon
+	Mode              string
+	SnapshotDate      sql.NullTime
+	TitleNumber       sql.NullInt64
+	FromDate          sql.NullTime
+	ToDate            sql.NullTime
+	StartedBy         string
+	Status            string
+	Error             sql.NullString
+	TitlesTotal       int
+	TitlesImported    int
+	TitlesChanged     int
+	TitlesUnchanged   int
+	TitlesSkipped     int
+	TitlesFailed      int
+	AgenciesTotal     int
+	AgenciesImported  int
+	AgenciesFailed    int
+	VersionsProcessed int
+	SnapshotsCreated  int
+	StartedAt         time.Time
+	FinishedAt        sql.NullTime
+}
+
+// Finished reports whether the run has stopped
+func (r ImportRun) Finished() bool {
+	return r.Status != ImportRunning
+}
+
+// ImportsAgencies reports whether the run's mode imports agencies after titles
+func (r ImportRun) ImportsAgencies() bool {
+	return r.Mode == ImportModeFull || r.Mode == ImportModeIncremental
+}
+
+// Failures returns the number of titles, versions and agencies that failed
+func (r ImportRun) Failures() int {
+	return r.TitlesFailed + r.AgenciesFailed
+}
+
+// Duration returns how long the run took, or has taken so far
+func (r ImportRun) Duration() time.Duration {
+	if !r.FinishedAt.Valid {
+		return time.Since(r.StartedAt).Round(time.Second)
+	}
+	return r.FinishedAt.Time.Sub(r.StartedAt).Round(time.Second)
+}
+
+// Description summarizes what the run imported
+func (r ImportRun) Description() string {
+	date := r.SnapshotDate.Time.Format("2006-01-02")
+	switch r.Mode {
+	case ImportModeFull:
+		return "All titles on " + date
+	case ImportModeIncremental:
+		return "Amended titles on " + date
+	case ImportModeTitle:
+		return fmt.Sprintf("Title %d on %s", r.TitleNumber.Int64, date)
+	case ImportModeReindex:
+		if r.TitleNumber.Valid {
+			return fmt.Sprintf("Title %d sections re-indexed", r.TitleNumber.Int64)
+		}
+		return "Missing sections re-indexed"
+	case ImportModeRange, ImportModeHistory:
+		scope := "All titles"
+		if r.TitleNumber.Valid {
+			scope = fmt.Sprintf("Title %d", r.TitleNumber.Int64)
+		}
+		if r.Mode == ImportModeHistory {
+			return scope + ", every version"
+		}
+		return fmt.Sprintf("%s, versions %s to %s", scope, r.FromDate.Time.Format("2006-01-02"), r.ToDate.Time.Format("2006-01-02"))
+	}
+	return r.Mode
+}
+
+// ImportFailure records a title, title version or agency that an import run
+// failed to import
+type ImportFailure struct {
+	ID          int
+	RunID       int
+	TitleNumber sql.NullInt64
+	VersionDate sql.NullTime
+	AgencySlug  sql.NullString
+	Message     string
+	CreatedAt   time.Time
+}
+
+// Subject names what failed
+func (f ImportFailure) Subject() string {
+	switch {
+	case f.AgencySlug.Valid:
+		return "Agency " + f.AgencySlug.String
+	case f.TitleNumber.Valid && f.VersionDate.Valid:
+		return fmt.Sprintf("Title %d (%s)", f.TitleNumber.Int64, f.VersionDate.Time.Format("2006-01-02"))
+	case f.TitleNumber.Valid:
+		return fmt.Sprintf("Title %d", f.TitleNumber.Int64)
+	}
+	return "Import"
+}
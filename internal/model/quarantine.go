@@ -0,0 +1,29 @@
+package model
+
+import (
+	"database/sql"
+	"time"
+)
+
+// Quarantine review statuses
+const (
+	QuarantinePending  = "pending"
+	QuarantineAccepted = "accepted" // Imported as is once fetched again
+	QuarantineRejected = "rejected"
+)
+
+// QuarantinedVersion is fetched title content that failed validation and
+// was held back from the title's snapshots for review
+type QuarantinedVersion struct {
+	ID                int
+	TitleNumber       int
+	VersionDate       time.Time
+	Checksum          string
+	SizeBytes         int
+	WordCount         sql.NullInt64 // NULL when the content did not parse
+	PreviousWordCount sql.NullInt64 // Latest snapshot's count, if any
+	Reason            string
+	Status            string // QuarantinePending, QuarantineAccepted or QuarantineRejected
+	CreatedAt         time.Time
+	ReviewedAt        sql.NullTime
+}
@@ -0,0 +1,14 @@
+package model
+
+import "time"
+
+// Section represents the text of a single CFR section within a title snapshot
+type Section struct {
+	ID           int
+	TitleNumber  int
+	SnapshotDate time.Time
+	Identifier   string // Section number, e.g. "§ 141.84"
+	Heading      string
+	Chapter      string // Chapter the section belongs to, e.g. "I"
+	Text         string
+}
@@ -12,6 +12,7 @@ type Title struct {
 	TitleName       string
 	WordCount       int
 	SectionCount    int
+	Readability     sql.NullFloat64 // Flesch reading ease of the title text
 	Checksum        string
 	LastAmendedDate sql.NullTime
 	FetchedAt       time.Time
@@ -25,6 +26,7 @@ type TitleSnapshot struct {
 	TitleName       string
 	WordCount       int
 	SectionCount    int
+	Readability     sql.NullFloat64
 	Checksum        string
 	LastAmendedDate sql.NullTime
 	SnapshotDate    time.Time
@@ -0,0 +1,91 @@
+package model
+
+import (
+	"database/sql"
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// WatchEntitySection is the watchlist entity type for a single CFR section;
+// titles and agencies use ChangeEntityTitle and ChangeEntityAgency
+const WatchEntitySection = "section"
+
+// WatchTarget identifies a title, agency or section that can be watched
+type WatchTarget struct {
+	EntityType  string
+	TitleNumber int    // Titles and sections
+	AgencySlug  string // Agencies
+	Section     string // Section identifier within the title, e.g. "§ 141.84"
+}
+
+// TitleTarget returns the watch target for a title
+func TitleTarget(number int) WatchTarget {
+	return WatchTarget{EntityType: ChangeEntityTitle, TitleNumber: number}
+}
+
+// AgencyTarget returns the watch target for an agency
+func AgencyTarget(slug string) WatchTarget {
+	return WatchTarget{EntityType: ChangeEntityAgency, AgencySlug: slug}
+}
+
+// SectionTarget returns the watch target for a section of a title
+func SectionTarget(titleNumber int, identifier string) WatchTarget {
+	return WatchTarget{EntityType: WatchEntitySection, TitleNumber: titleNumber, Section: identifier}
+}
+
+// Key uniquely identifies the target, e.g. for looking up watched sections
+func (t WatchTarget) Key() string {
+	switch t.EntityType {
+	case ChangeEntityAgency:
+		return "agency:" + t.AgencySlug
+	case WatchEntitySection:
+		return fmt.Sprintf("section:%d:%s", t.TitleNumber, t.Section)
+	}
+	return fmt.Sprintf("title:%d", t.TitleNumber)
+}
+
+// Path returns the web UI page for the target
+func (t WatchTarget) Path() string {
+	switch t.EntityType {
+	case ChangeEntityAgency:
+		return "/agencies/" + t.AgencySlug
+	case WatchEntitySection:
+		q := url.Values{
+			"title": {strconv.Itoa(t.TitleNumber)},
+			"q":     {`"` + strings.TrimSpace(strings.TrimPrefix(t.Section, "§")) + `"`},
+		}
+		return "/search?" + q.Encode()
+	}
+	return fmt.Sprintf("/titles/%d", t.TitleNumber)
+}
+
+// WatchlistItem is a watched entity with its current state and the state
+// the user last saw
+type WatchlistItem struct {
+	ID int
+	WatchTarget
+	Name          string
+	WordCount     int
+	Checksum      string
+	SeenWordCount int
+	SeenChecksum  string
+	SeenAt        time.Time
+	LastChangedOn sql.NullTime  // Snapshot date of the latest change
+	LastChange    sql.NullInt64 // Word count change of the latest change
+	CreatedAt     time.Time
+}
+
+// ChangedSinceSeen reports whether the entity's text changed since the user
+// last looked at it
+func (i WatchlistItem) ChangedSinceSeen() bool {
+	return i.Checksum != i.SeenChecksum
+}
+
+// WordCountSinceSeen returns the change in word count since the user last
+// looked at the entity
+func (i WatchlistItem) WordCountSinceSeen() int {
+	return i.WordCount - i.SeenWordCount
+}
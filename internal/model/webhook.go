@@ -0,0 +1,45 @@
+package model
+
+import (
+	"database/sql"
+	"time"
+)
+
+// Webhook delivery statuses
+const (
+	DeliveryPending   = "pending"
+	DeliveryDelivered = "delivered"
+	DeliveryDead      = "dead" // Retries exhausted
+)
+
+// WebhookSubscription is an endpoint notified of change events. A
+// subscription with neither a title nor an agency receives every event.
+type WebhookSubscription struct {
+	ID          int
+	Name        string
+	URL         string
+	Secret      string // HMAC-SHA256 signing key
+	TitleNumber sql.NullInt64
+	AgencyID    sql.NullInt64
+	AgencySlug  sql.NullString
+	Active      bool
+	CreatedAt   time.Time
+}
+
+// WebhookDelivery is one change event queued for one subscription
+type WebhookDelivery struct {
+	ID               int
+	SubscriptionID   int
+	SubscriptionName string
+	URL              string
+	Secret           string
+	Event            ChangeEvent
+	Status           string
+	Attempts         int
+	NextAttemptAt    time.Time
+	LastStatusCode   sql.NullInt64
+	LastError        sql.NullString
+	DeliveredAt      sql.NullTime
+	CreatedAt        time.Time
+	UpdatedAt        time.Time
+}
@@ -0,0 +1,193 @@
+package openapi
+
+import (
+	"reflect"
+	"strings"
+	"time"
+)
+
+// Document is the subset of an OpenAPI 3 document used by the JSON API
+type Document struct {
+	OpenAPI    string              `json:"openapi"`
+	Info       Info                `json:"info"`
+	Paths      map[string]PathItem `json:"paths"`
+	Components Components          `json:"components"`
+}
+
+// Info describes the API
+type Info struct {
+	Title       string `json:"title"`
+	Description string `json:"description,omitempty"`
+	Version     string `json:"version"`
+}
+
+// PathItem holds the operations available on a path
+type PathItem struct {
+	Get *Operation `json:"get,omitempty"`
+}
+
+// Operation describes a single API operation
+type Operation struct {
+	OperationID string              `json:"operationId"`
+	Summary     string              `json:"summary"`
+	Parameters  []Parameter         `json:"parameters,omitempty"`
+	Responses   map[string]Response `json:"responses"`
+}
+
+// Parameter describes a path or query parameter
+type Parameter struct {
+	Name        string  `json:"name"`
+	In          string  `json:"in"`
+	Description string  `json:"description,omitempty"`
+	Required    bool    `json:"required,omitempty"`
+	Schema      *Schema `json:"schema"`
+}
+
+// Response describes an operation response
+type Response struct {
+	Description string               `json:"description"`
+	Content     map[string]MediaType `json:"content,omitempty"`
+}
+
+// MediaType wraps the schema for a response body
+type MediaType struct {
+	Schema *Schema `json:"schema"`
+}
+
+// Components holds the named schemas referenced by operations
+type Components struct {
+	Schemas map[string]*Schema `json:"schemas"`
+}
+
+// Schema is the subset of JSON Schema used by the spec
+type Schema struct {
+	Ref        string             `json:"$ref,omitempty"`
+	AllOf      []*Schema          `json:"allOf,omitempty"`
+	Type       string             `json:"type,omitempty"`
+	Format     string             `json:"format,omitempty"`
+	Nullable   bool               `json:"nullable,omitempty"`
+	Enum       []string           `json:"enum,omitempty"`
+	Items      *Schema            `json:"items,omitempty"`
+	Properties map[string]*Schema `json:"properties,omitempty"`
+	Required   []string           `json:"required,omitempty"`
+}
+
+// NewDocument creates an empty OpenAPI 3 document
+func NewDocument(title, version, description string) *Document {
+	return &Document{
+		OpenAPI: "3.0.3",
+		Info: Info{
+			Title:       title,
+			Description: description,
+			Version:     version,
+		},
+		Paths:      make(map[string]PathItem),
+		Components: Components{Schemas: make(map[string]*Schema)},
+	}
+}
+
+// SchemaFor returns a schema for the given Go value, registering any named
+// struct types as components so the spec is derived from the response types
+func (d *Document) SchemaFor(v any) *Schema {
+	return d.schemaForType(reflect.TypeOf(v))
+}
+
+// Resolve follows a $ref to its component schema
+func (d *Document) Resolve(s *Schema) *Schema {
+	for s != nil && s.Ref != "" {
+		name := strings.TrimPrefix(s.Ref, "#/components/schemas/")
+		s = d.Components.Schemas[name]
+	}
+	return s
+}
+
+var timeType = reflect.TypeOf(time.Time{})
+
+func (d *Document) schemaForType(t reflect.Type) *Schema {
+	if t.Kind() == reflect.Pointer {
+		s := d.schemaForType(t.Elem())
+		if s.Ref != "" {
+			// Siblings of $ref are ignored in OpenAPI 3.0, so wrap it
+			return &Schema{Nullable: true, AllOf: []*Schema{s}}
+		}
+		s.Nullable = true
+		return s
+	}
+
+	if t == timeType {
+		return &Schema{Type: "string", Format: "date-time"}
+	}
+
+	switch t.Kind() {
+	case reflect.Bool:
+		return &Schema{Type: "boolean"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return &Schema{Type: "integer"}
+	case reflect.Float32, reflect.Float64:
+		return &Schema{Type: "number"}
+	case reflect.String:
+		return &Schema{Type: "string"}
+	case reflect.Slice, reflect.Array:
+		return &Schema{Type: "array", Items: d.schemaForType(t.Elem())}
+	case reflect.Map:
+		return &Schema{Type: "object"}
+	case reflect.Struct:
+		return d.structSchema(t)
+	default:
+		return &Schema{}
+	}
+}
+
+func (d *Document) structSchema(t reflect.Type) *Schema {
+	name := t.Name()
+	if name != "" {
+		if _, ok := d.Components.Schemas[name]; ok {
+			return &Schema{Ref: "#/components/schemas/" + name}
+		}
+		// Register a placeholder first so self-referencing types terminate
+		d.Components.Schemas[name] = &Schema{}
+	}
+
+	s := &Schema{Type: "object", Properties: make(map[string]*Schema)}
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if !field.IsExported() {
+			continue
+		}
+
+		tag := field.Tag.Get("json")
+		if tag == "-" {
+			continue
+		}
+		parts := strings.Split(tag, ",")
+		jsonName := parts[0]
+		if jsonName == "" {
+			jsonName = field.Name
+		}
+		omitEmpty := false
+		for _, opt := range parts[1:] {
+			if opt == "omitempty" {
+				omitEmpty = true
+			}
+		}
+
+		prop := d.schemaForType(field.Type)
+		if format := field.Tag.Get("format"); format != "" {
+			prop.Format = format
+		}
+		if enum := field.Tag.Get("enum"); enum != "" {
+			prop.Enum = strings.Split(enum, ",")
+		}
+		s.Properties[jsonName] = prop
+		if !omitEmpty {
+			s.Required = append(s.Required, jsonName)
+		}
+	}
+
+	if name == "" {
+		return s
+	}
+	*d.Components.Schemas[name] = *s
+	return &Schema{Ref: "#/components/schemas/" + name}
+}
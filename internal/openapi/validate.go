@@ -0,0 +1,173 @@
+package openapi
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"time"
+)
+
+// ValidateJSON checks a JSON body against a schema from the document and
+// returns every mismatch found, using JSON pointer style paths
+func (d *Document) ValidateJSON(schema *Schema, body []byte) []error {
+	decoder := json.NewDecoder(bytes.NewReader(body))
+	decoder.UseNumber()
+
+	var value any
+	if err := decoder.Decode(&value); err != nil {
+		return []error{fmt.Errorf("invalid JSON: %w", err)}
+	}
+
+	var errs []error
+	d.validate(schema, value, "", &errs)
+	return errs
+}
+
+// ResponseSchema returns the JSON schema declared for an operation's response
+func (d *Document) ResponseSchema(path, status string) (*Schema, error) {
+	item, ok := d.Paths[path]
+	if !ok || item.Get == nil {
+		return nil, fmt.Errorf("path %s not found in spec", path)
+	}
+	resp, ok := item.Get.Responses[status]
+	if !ok {
+		return nil, fmt.Errorf("status %s not declared for %s", status, path)
+	}
+	media, ok := resp.Content["application/json"]
+	if !ok {
+		return nil, fmt.Errorf("no JSON content declared for %s %s", path, status)
+	}
+	return media.Schema, nil
+}
+
+func (d *Document) validate(schema *Schema, value any, path string, errs *[]error) {
+	if schema.Ref != "" {
+		resolved := d.Resolve(schema)
+		if resolved == nil {
+			*errs = append(*errs, fmt.Errorf("%s: unresolved reference %s", pointer(path), schema.Ref))
+			return
+		}
+		d.validate(resolved, value, path, errs)
+		return
+	}
+
+	if value == nil {
+		if !schema.Nullable {
+			*errs = append(*errs, fmt.Errorf("%s: null is not allowed", pointer(path)))
+		}
+		return
+	}
+
+	for _, sub := range schema.AllOf {
+		d.validate(sub, value, path, errs)
+	}
+
+	switch schema.Type {
+	case "":
+		return
+	case "object":
+		obj, ok := value.(map[string]any)
+		if !ok {
+			*errs = append(*errs, typeError(path, "object", value))
+			return
+		}
+		for _, name := range schema.Required {
+			if _, ok := obj[name]; !ok {
+				*errs = append(*errs, fmt.Errorf("%s: missing required property %q", pointer(path), name))
+			}
+		}
+		// An object schema without properties, like the document's own,
+		// allows any
+		if schema.Properties == nil {
+			return
+		}
+		names := make([]string, 0, len(obj))
+		for name := range obj {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			prop, ok := schema.Properties[name]
+			if !ok {
+				*errs = append(*errs, fmt.Errorf("%s: property %q is not in the spec", pointer(path), name))
+				continue
+			}
+			d.validate(prop, obj[name], path+"/"+name, errs)
+		}
+	case "array":
+		arr, ok := value.([]any)
+		if !ok {
+			*errs = append(*errs, typeError(path, "array", value))
+			return
+		}
+		for i, item := range arr {
+			d.validate(schema.Items, item, fmt.Sprintf("%s/%d", path, i), errs)
+		}
+	case "string":
+		str, ok := value.(string)
+		if !ok {
+			*errs = append(*errs, typeError(path, "string", value))
+			return
+		}
+		if err := checkFormat(schema.Format, str); err != nil {
+			*errs = append(*errs, fmt.Errorf("%s: %w", pointer(path), err))
+		}
+		if len(schema.Enum) > 0 && !contains(schema.Enum, str) {
+			*errs = append(*errs, fmt.Errorf("%s: %q is not one of %v", pointer(path), str, schema.Enum))
+		}
+	case "integer":
+		num, ok := value.(json.Number)
+		if !ok {
+			*errs = append(*errs, typeError(path, "integer", value))
+			return
+		}
+		if _, err := num.Int64(); err != nil {
+			*errs = append(*errs, fmt.Errorf("%s: %s is not an integer", pointer(path), num))
+		}
+	case "number":
+		if _, ok := value.(json.Number); !ok {
+			*errs = append(*errs, typeError(path, "number", value))
+		}
+	case "boolean":
+		if _, ok := value.(bool); !ok {
+			*errs = append(*errs, typeError(path, "boolean", value))
+		}
+	default:
+		*errs = append(*errs, fmt.Errorf("%s: unsupported schema type %q", pointer(path), schema.Type))
+	}
+}
+
+func checkFormat(format, value string) error {
+	switch format {
+	case "date":
+		if _, err := time.Parse("2006-01-02", value); err != nil {
+			return fmt.Errorf("%q is not a valid date", value)
+		}
+	case "date-time":
+		if _, err := time.Parse(time.RFC3339, value); err != nil {
+			return fmt.Errorf("%q is not a valid date-time", value)
+		}
+	}
+	return nil
+}
+
+func typeError(path, want string, value any) error {
+	return fmt.Errorf("%s: expected %s, got %T", pointer(path), want, value)
+}
+
+func pointer(path string) string {
+	if path == "" {
+		return "/"
+	}
+	return path
+}
+
+func contains(values []string, v string) bool {
+	for _, s := range values {
+		if s == v {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,188 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/jjenkins/usds/internal/model"
+	"github.com/jjenkins/usds/internal/store"
+)
+
+// MaxComparedAgencies caps how many agencies can be compared side by side
+const MaxComparedAgencies = 6
+
+// ComparedAgency holds one agency's metrics for a side-by-side comparison
+type ComparedAgency struct {
+	Agency       *model.Agency
+	DensityScore float64
+	HasDensity   bool
+	Readability  sql.NullFloat64 // Word-weighted Flesch reading ease of its titles
+	Titles       []model.Title
+	Chapters     []model.CFRReference
+	Snapshots    []model.AgencySnapshot // Newest first
+}
+
+// WordsPerTitle returns the agency's average words per linked title
+func (a ComparedAgency) WordsPerTitle() float64 {
+	if a.Agency.RegulationCount == 0 {
+		return 0
+	}
+	return float64(a.Agency.TotalWordCount) / float64(a.Agency.RegulationCount)
+}
+
+// SnapshotOn returns the agency's latest snapshot on or before date
+func (a ComparedAgency) SnapshotOn(date time.Time) (model.AgencySnapshot, bool) {
+	for _, snap := range a.Snapshots {
+		if !snap.SnapshotDate.After(date) {
+			return snap, true
+		}
+	}
+	return model.AgencySnapshot{}, false
+}
+
+// WordCountChange returns the change in word count between the agency's
+// oldest and newest snapshots
+func (a ComparedAgency) WordCountChange() (int, bool) {
+	if len(a.Snapshots) < 2 {
+		return 0, false
+	}
+	return a.Snapshots[0].TotalWordCount - a.Snapshots[len(a.Snapshots)-1].TotalWordCount, true
+}
+
+// SharedTitle is a title linked to more than one of the compared agencies
+type SharedTitle struct {
+	TitleNumber int
+	TitleName   string
+	Slugs       []string // Compared agencies linked to the title, in comparison order
+}
+
+// AgencyComparison lines up several agencies' metrics and history
+type AgencyComparison struct {
+	Agencies      []ComparedAgency
+	SnapshotDates []time.Time // Union of all agencies' snapshot dates, newest first
+	SharedTitles  []SharedTitle
+	NotFound      []string
+}
+
+// ParseAgencySlugs splits a comma-separated agencies parameter, dropping
+// blanks and duplicates while keeping the given order
+func ParseAgencySlugs(values ...string) []string {
+	seen := make(map[string]bool)
+	var slugs []string
+	for _, value := range values {
+		for _, slug := range strings.Split(value, ",") {
+			slug = strings.TrimSpace(slug)
+			if slug == "" || seen[slug] {
+				continue
+			}
+			seen[slug] = true
+			slugs = append(slugs, slug)
+		}
+	}
+	return slugs
+}
+
+// CompareAgencies loads the given agencies by slug and lines up their metrics,
+// snapshot history and shared titles. Unknown slugs are reported in NotFound.
+func CompareAgencies(ctx context.Context, agencyStore store.AgencyRepository, slugs []string) (*AgencyComparison, error) {
+	if len(slugs) > MaxComparedAgencies {
+		return nil, fmt.Errorf("at most %d agencies can be compared", MaxComparedAgencies)
+	}
+
+	comparison := &AgencyComparison{}
+	dateSet := make(map[time.Time]bool)
+	titleAgencies := make(map[int][]string)
+	titleNames := make(map[int]string)
+
+	for _, slug := range slugs {
+		agency, err := agencyStore.GetBySlug(ctx, slug)
+		if err != nil {
+			return nil, err
+		}
+		if agency == nil {
+			comparison.NotFound = append(comparison.NotFound, slug)
+			continue
+		}
+
+		compared := ComparedAgency{Agency: agency}
+
+		if agency.RegulationCount > 0 {
+			compared.DensityScore, err = agencyStore.GetDensityScoreForAgency(ctx, agency)
+			if err != nil {
+				return nil, fmt.Errorf("failed to get density score for agency %s: %w", slug, err)
+			}
+			compared.HasDensity = true
+		}
+
+		compared.Titles, err = agencyStore.GetTitlesForAgency(ctx, agency.ID)
+		if err != nil {
+			return nil, err
+		}
+		compared.Readability = weightedReadability(compared.Titles)
+
+		compared.Chapters, err = agencyStore.GetChaptersForAgency(ctx, agency.ID)
+		if err != nil {
+			return nil, err
+		}
+
+		compared.Snapshots, err = agencyStore.GetSnapshotsForAgency(ctx, agency.ID)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, snap := range compared.Snapshots {
+			dateSet[snap.SnapshotDate] = true
+		}
+		for _, t := range compared.Titles {
+			titleAgencies[t.TitleNumber] = append(titleAgencies[t.TitleNumber], agency.Slug)
+			titleNames[t.TitleNumber] = t.TitleName
+		}
+
+		comparison.Agencies = append(comparison.Agencies, compared)
+	}
+
+	for date := range dateSet {
+		comparison.SnapshotDates = append(comparison.SnapshotDates, date)
+	}
+	sort.Slice(comparison.SnapshotDates, func(i, j int) bool {
+		return comparison.SnapshotDates[i].After(comparison.SnapshotDates[j])
+	})
+
+	for titleNumber, agencySlugs := range titleAgencies {
+		if len(agencySlugs) < 2 {
+			continue
+		}
+		comparison.SharedTitles = append(comparison.SharedTitles, SharedTitle{
+			TitleNumber: titleNumber,
+			TitleName:   titleNames[titleNumber],
+			Slugs:       agencySlugs,
+		})
+	}
+	sort.Slice(comparison.SharedTitles, func(i, j int) bool {
+		return comparison.SharedTitles[i].TitleNumber < comparison.SharedTitles[j].TitleNumber
+	})
+
+	return comparison, nil
+}
+
+// weightedReadability averages the titles' reading ease weighted by word
+// count, so large titles dominate as they do in the agency's word count
+func weightedReadability(titles []model.Title) sql.NullFloat64 {
+	var sum float64
+	var words int
+	for _, t := range titles {
+		if !t.Readability.Valid || t.WordCount == 0 {
+			continue
+		}
+		sum += t.Readability.Float64 * float64(t.WordCount)
+		words += t.WordCount
+	}
+	if words == 0 {
+		return sql.NullFloat64{}
+	}
+	return sql.NullFloat64{Float64: sum / float64(words), Valid: true}
+}
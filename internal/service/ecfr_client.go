@@ -6,31 +6,35 @@ import (
 	"fmt"
 	"io"
 	"net/http"
+	"strings"
 	"time"
 
+	"github.com/jjenkins/usds/internal/config"
 	"github.com/jjenkins/usds/internal/model"
-)
-
-const (
-	baseURL        = "https://www.ecfr.gov/api/versioner/v1"
-	adminBaseURL   = "https://www.ecfr.gov/api/admin/v1"
-	defaultTimeout = 120 * time.Second // Increased for large historical titles
-	maxRetries     = 3
-	initialBackoff = 2 * time.Second // Longer initial backoff for 504s
-	requestDelay   = 1 * time.Second
+	"github.com/jjenkins/usds/internal/telemetry"
 )
 
 // ECFRClient handles communication with the eCFR API
 type ECFRClient struct {
-	client *http.Client
+	client         *http.Client
+	baseURL        string
+	adminBaseURL   string
+	maxRetries     int
+	initialBackoff time.Duration
+	requestDelay   time.Duration
 }
 
 // NewECFRClient creates a new eCFR API client
-func NewECFRClient() *ECFRClient {
+func NewECFRClient(cfg config.ECFR) *ECFRClient {
 	return &ECFRClient{
 		client: &http.Client{
-			Timeout: defaultTimeout,
+			Timeout: cfg.Timeout.Duration,
 		},
+		baseURL:        strings.TrimSuffix(cfg.BaseURL, "/"),
+		adminBaseURL:   strings.TrimSuffix(cfg.AdminBaseURL, "/"),
+		maxRetries:     cfg.MaxRetries,
+		initialBackoff: cfg.InitialBackoff.Duration,
+		requestDelay:   cfg.RequestDelay.Duration,
 	}
 }
 
@@ -64,9 +68,9 @@ type agencyJSON struct {
 
 // FetchTitles retrieves the list of all CFR titles
 func (c *ECFRClient) FetchTitles(ctx context.Context) ([]model.TitleMeta, error) {
-	url := fmt.Sprintf("%s/titles.json", baseURL)
+	url := fmt.Sprintf("%s/titles.json", c.baseURL)
 
-	body, err := c.fetchWithRetry(ctx, url)
+	body, err := c.fetchWithRetry(ctx, "titles", url)
 	if err != nil {
 		return nil, fmt.Errorf("failed to fetch titles: %w", err)
 	}
@@ -92,9 +96,9 @@ func (c *ECFRClient) FetchTitles(ctx context.Context) ([]model.TitleMeta, error)
 
 // FetchTitleContent retrieves the full XML content for a title
 func (c *ECFRClient) FetchTitleContent(ctx context.Context, date string, titleNumber int) ([]byte, error) {
-	url := fmt.Sprintf("%s/full/%s/title-%d.xml", baseURL, date, titleNumber)
+	url := fmt.Sprintf("%s/full/%s/title-%d.xml", c.baseURL, date, titleNumber)
 
-	body, err := c.fetchWithRetry(ctx, url)
+	body, err := c.fetchWithRetry(ctx, "full", url)
 	if err != nil {
 		return nil, fmt.Errorf("failed to fetch title %d content: %w", titleNumber, err)
 	}
@@ -104,9 +108,9 @@ func (c *ECFRClient) FetchTitleContent(ctx context.Context, date string, titleNu
 
 // FetchAgencies retrieves all agencies with their hierarchical structure
 func (c *ECFRClient) FetchAgencies(ctx context.Context) ([]model.AgencyMeta, error) {
-	url := fmt.Sprintf("%s/agencies.json", adminBaseURL)
+	url := fmt.Sprintf("%s/agencies.json", c.adminBaseURL)
 
-	body, err := c.fetchWithRetry(ctx, url)
+	body, err := c.fetchWithRetry(ctx, "agencies", url)
 	if err != nil {
 		return nil, fmt.Errorf("failed to fetch agencies: %w", err)
 	}
@@ -147,13 +151,16 @@ func convertAgencyJSON(a agencyJSON) model.AgencyMeta {
 	return agency
 }
 
-// fetchWithRetry performs an HTTP GET with exponential backoff retry
-func (c *ECFRClient) fetchWithRetry(ctx context.Context, url string) ([]byte, error) {
+// fetchWithRetry performs an HTTP GET with exponential backoff retry. Each
+// attempt's latency and each failure are recorded under the endpoint name.
+func (c *ECFRClient) fetchWithRetry(ctx context.Context, endpoint, url string) ([]byte, error) {
 	var lastErr error
-	backoff := initialBackoff
+	var lastReason string
+	backoff := c.initialBackoff
 
-	for attempt := 0; attempt < maxRetries; attempt++ {
+	for attempt := 0; attempt < c.maxRetries; attempt++ {
 		if attempt > 0 {
+			telemetry.CountRetry(endpoint, lastReason)
 			select {
 			case <-ctx.Done():
 				return nil, ctx.Err()
@@ -167,39 +174,53 @@ func (c *ECFRClient) fetchWithRetry(ctx context.Context, url string) ([]byte, er
 			return nil, fmt.Errorf("failed to create request: %w", err)
 		}
 
-		resp, err := c.client.Do(req)
-		if err != nil {
-			lastErr = err
-			continue
-		}
-
-		body, err := io.ReadAll(resp.Body)
-		resp.Body.Close()
+		start := time.Now()
+		body, status, err := c.do(req)
+		telemetry.ObserveFetch(endpoint, time.Since(start))
 
 		if err != nil {
-			lastErr = err
+			if ctx.Err() != nil {
+				return nil, ctx.Err()
+			}
+			lastErr, lastReason = err, telemetry.ReasonTransport
 			continue
 		}
 
-		if resp.StatusCode == http.StatusTooManyRequests {
-			lastErr = fmt.Errorf("rate limited (HTTP 429)")
+		if status == http.StatusTooManyRequests {
+			lastErr, lastReason = fmt.Errorf("rate limited (HTTP 429)"), telemetry.ReasonRateLimited
 			continue
 		}
 
-		if resp.StatusCode != http.StatusOK {
-			lastErr = fmt.Errorf("unexpected status code: %d", resp.StatusCode)
+		if status != http.StatusOK {
+			lastErr, lastReason = fmt.Errorf("unexpected status code: %d", status), telemetry.ReasonStatus
 			continue
 		}
 
 		return body, nil
 	}
 
-	return nil, fmt.Errorf("failed after %d attempts: %w", maxRetries, lastErr)
+	telemetry.CountError(endpoint, lastReason)
+	return nil, fmt.Errorf("failed after %d attempts: %w", c.maxRetries, lastErr)
+}
+
+// do sends the request and reads the whole response body
+func (c *ECFRClient) do(req *http.Request) ([]byte, int, error) {
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, 0, err
+	}
+	return body, resp.StatusCode, nil
 }
 
 // Delay returns the configured delay between requests
 func (c *ECFRClient) Delay() time.Duration {
-	return requestDelay
+	return c.requestDelay
 }
 
 // versionsResponse represents the API response for /versions/title-{n}.json
@@ -212,9 +233,9 @@ type versionsResponse struct {
 
 // FetchTitleVersions retrieves all available issue dates for a title
 func (c *ECFRClient) FetchTitleVersions(ctx context.Context, titleNumber int) ([]string, error) {
-	url := fmt.Sprintf("%s/versions/title-%d.json", baseURL, titleNumber)
+	url := fmt.Sprintf("%s/versions/title-%d.json", c.baseURL, titleNumber)
 
-	body, err := c.fetchWithRetry(ctx, url)
+	body, err := c.fetchWithRetry(ctx, "versions", url)
 	if err != nil {
 		return nil, fmt.Errorf("failed to fetch versions for title %d: %w", titleNumber, err)
 	}
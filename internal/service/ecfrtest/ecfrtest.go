@@ -0,0 +1,213 @@
+// Package ecfrtest fakes the eCFR versioner and admin APIs over HTTP, serving
+// the small golden titles and agencies in testdata, so imports can be tested
+// end to end without the network.
+package ecfrtest
+
+import (
+	"embed"
+	"encoding/json"
+	"fmt"
+	"io/fs"
+	"net/http"
+	"net/http/httptest"
+	"path"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/jjenkins/usds/internal/config"
+)
+
+//go:embed testdata
+var fixtures embed.FS
+
+// API roots, as on www.ecfr.gov
+const (
+	VersionerPath = "/api/versioner/v1"
+	AdminPath     = "/api/admin/v1"
+)
+
+// Each title's versions are testdata/title-N/YYYY-MM-DD.xml
+var (
+	fullPattern     = regexp.MustCompile(`^full/(\d{4}-\d{2}-\d{2})/title-(\d+)\.xml$`)
+	versionsPattern = regexp.MustCompile(`^versions/title-(\d+)\.json$`)
+)
+
+// Server is a running fake of the eCFR APIs. Requests are named by their path
+// below the API root, such as "titles.json", "versions/title-1.json",
+// "full/2024-06-01/title-1.xml" or "agencies.json".
+type Server struct {
+	*httptest.Server
+
+	mu       sync.Mutex
+	queued   map[string][]response // Responses to give before the fixture, by request name
+	requests map[string]int
+}
+
+// response is an injected answer to one request
+type response struct {
+	status int
+	body   []byte
+}
+
+// NewServer starts a Server that is closed when the test ends
+func NewServer(t testing.TB) *Server {
+	s := &Server{
+		queued:   make(map[string][]response),
+		requests: make(map[string]int),
+	}
+	s.Server = httptest.NewServer(http.HandlerFunc(s.serve))
+	t.Cleanup(s.Close)
+	return s
+}
+
+// Config returns client settings pointing at the server, retrying quickly
+// and without pausing between titles
+func (s *Server) Config() config.ECFR {
+	return config.ECFR{
+		BaseURL:        s.URL + VersionerPath,
+		AdminBaseURL:   s.URL + AdminPath,
+		Timeout:        config.Duration{Duration: 5 * time.Second},
+		MaxRetries:     3,
+		InitialBackoff: config.Duration{Duration: time.Millisecond},
+	}
+}
+
+// Fail answers the next requests for name with the given statuses in turn,
+// such as 429 or 504, before serving it normally again
+func (s *Server) Fail(name string, statuses ...int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, status := range statuses {
+		s.queued[name] = append(s.queued[name], response{status: status, body: []byte(http.StatusText(status))})
+	}
+}
+
+// Serve answers the next request for name with body and HTTP 200 in place of
+// the fixture, as when the API returns a truncated or wrong document
+func (s *Server) Serve(name string, body []byte) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.queued[name] = append(s.queued[name], response{status: http.StatusOK, body: body})
+}
+
+// Requests returns how many times name has been requested, failed or not
+func (s *Server) Requests(name string) int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.requests[name]
+}
+
+// Fixture returns the golden XML of a title version
+func Fixture(titleNumber int, date string) []byte {
+	content, err := fixtures.ReadFile(path.Join("testdata", fmt.Sprintf("title-%d", titleNumber), date+".xml"))
+	if err != nil {
+		panic(err)
+	}
+	return content
+}
+
+func (s *Server) serve(w http.ResponseWriter, r *http.Request) {
+	var name string
+	var admin bool
+	if rest, ok := strings.CutPrefix(r.URL.Path, VersionerPath+"/"); ok {
+		name = rest
+	} else if rest, ok := strings.CutPrefix(r.URL.Path, AdminPath+"/"); ok {
+		name, admin = rest, true
+	} else {
+		http.NotFound(w, r)
+		return
+	}
+
+	s.mu.Lock()
+	s.requests[name]++
+	var injected *response
+	if queued := s.queued[name]; len(queued) > 0 {
+		injected, s.queued[name] = &queued[0], queued[1:]
+	}
+	s.mu.Unlock()
+
+	if injected != nil {
+		w.WriteHeader(injected.status)
+		w.Write(injected.body)
+		return
+	}
+
+	switch {
+	case admin && name == "agencies.json", !admin && name == "titles.json":
+		serveFile(w, r, path.Join("testdata", name), "application/json")
+	case !admin && versionsPattern.MatchString(name):
+		s.serveVersions(w, r, versionsPattern.FindStringSubmatch(name)[1])
+	case !admin && fullPattern.MatchString(name):
+		m := fullPattern.FindStringSubmatch(name)
+		s.serveFull(w, r, m[2], m[1])
+	default:
+		http.NotFound(w, r)
+	}
+}
+
+// serveVersions lists a title's fixture dates, oldest first
+func (s *Server) serveVersions(w http.ResponseWriter, r *http.Request, titleNumber string) {
+	dates := versionDates(titleNumber)
+	if len(dates) == 0 {
+		http.NotFound(w, r)
+		return
+	}
+
+	type version struct {
+		Date       string `json:"date"`
+		Identifier string `json:"identifier"`
+	}
+	resp := struct {
+		ContentVersions []version `json:"content_versions"`
+	}{}
+	for _, date := range dates {
+		resp.ContentVersions = append(resp.ContentVersions, version{Date: date, Identifier: titleNumber})
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(resp)
+}
+
+// serveFull serves a title as of date, which is its latest version on or
+// before it, as the versioner API does
+func (s *Server) serveFull(w http.ResponseWriter, r *http.Request, titleNumber, date string) {
+	var current string
+	for _, version := range versionDates(titleNumber) {
+		if version <= date {
+			current = version
+		}
+	}
+	if current == "" {
+		http.NotFound(w, r)
+		return
+	}
+	serveFile(w, r, path.Join("testdata", "title-"+titleNumber, current+".xml"), "application/xml")
+}
+
+// versionDates returns the dates of a title's fixtures in order
+func versionDates(titleNumber string) []string {
+	entries, err := fs.ReadDir(fixtures, path.Join("testdata", "title-"+titleNumber))
+	if err != nil {
+		return nil
+	}
+	var dates []string
+	for _, e := range entries {
+		dates = append(dates, strings.TrimSuffix(e.Name(), ".xml"))
+	}
+	sort.Strings(dates)
+	return dates
+}
+
+func serveFile(w http.ResponseWriter, r *http.Request, name, contentType string) {
+	content, err := fixtures.ReadFile(name)
+	if err != nil {
+		http.NotFound(w, r)
+		return
+	}
+	w.Header().Set("Content-Type", contentType)
+	w.Write(content)
+}
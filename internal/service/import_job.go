@@ -0,0 +1,319 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/jjenkins/usds/internal/logging"
+	"github.com/jjenkins/usds/internal/model"
+	"github.com/jjenkins/usds/internal/store"
+)
+
+// ImportRequest describes an import run
+type ImportRequest struct {
+	Mode        string    // One of the model.ImportMode constants
+	Date        time.Time // Snapshot date for full, incremental and title imports
+	TitleNumber int       // Title imports, or limits range, history and reindex runs to one title
+	From        time.Time // Range imports
+	To          time.Time // Range imports
+}
+
+// Validate checks that the request has what its mode needs
+func (r ImportRequest) Validate() error {
+	if r.TitleNumber < 0 || r.TitleNumber > 50 {
+		return fmt.Errorf("title must be between 1 and 50")
+	}
+
+	switch r.Mode {
+	case model.ImportModeFull, model.ImportModeIncremental:
+		if r.Date.IsZero() {
+			return fmt.Errorf("date is required")
+		}
+	case model.ImportModeTitle:
+		if r.Date.IsZero() {
+			return fmt.Errorf("date is required")
+		}
+		if r.TitleNumber == 0 {
+			return fmt.Errorf("title is required")
+		}
+	case model.ImportModeRange:
+		if r.From.IsZero() || r.To.IsZero() {
+			return fmt.Errorf("from and to dates are required")
+		}
+		if r.To.Before(r.From) {
+			return fmt.Errorf("to date is before from date")
+		}
+	case model.ImportModeHistory, model.ImportModeReindex:
+	default:
+		return fmt.Errorf("unknown import mode %q", r.Mode)
+	}
+	return nil
+}
+
+// ImportJob runs import requests end to end for the CLI, the admin pages and
+// the daemon: the importer stages the mode needs, system metrics and webhook
+// delivery afterwards, a record of the run and its failures, and a data
+// version bump after each stage so servers drop cached reads. A run holds
+// the database's import lock from Start until Run returns, so only one
+// import runs at a time across every process sharing the database.
+type ImportJob struct {
+	db           *sql.DB
+	importer     *Importer
+	metrics      *MetricsService
+	dispatcher   *WebhookDispatcher
+	runStore     *store.ImportRunStore
+	versionStore *store.DataVersionStore
+
+	mu   sync.Mutex
+	lock *store.AdvisoryLock // Held by the started run
+}
+
+// NewImportJob creates an ImportJob. The dispatcher may be nil.
+func NewImportJob(db *sql.DB, importer *Importer, metrics *MetricsService, dispatcher *WebhookDispatcher, runStore *store.ImportRunStore, versionStore *store.DataVersionStore) *ImportJob {
+	return &ImportJob{
+		db:           db,
+		importer:     importer,
+		metrics:      metrics,
+		dispatcher:   dispatcher,
+		runStore:     runStore,
+		versionStore: versionStore,
+	}
+}
+
+// Start validates the request, takes the import lock and records the
+// request as a running import. It returns ErrImportRunning if another run,
+// here or in another process, holds the lock. Run releases it.
+func (j *ImportJob) Start(ctx context.Context, req ImportRequest, source, startedBy string) (*model.ImportRun, error) {
+	if err := req.Validate(); err != nil {
+		return nil, err
+	}
+
+	lock, err := store.TryAdvisoryLock(ctx, j.db, store.ImportLockKey)
+	if err != nil {
+		return nil, err
+	}
+	if lock == nil {
+		return nil, ErrImportRunning
+	}
+
+	run := &model.ImportRun{
+		Source:       source,
+		Mode:         req.Mode,
+		SnapshotDate: nullDate(req.Date),
+		TitleNumber:  sql.NullInt64{Int64: int64(req.TitleNumber), Valid: req.TitleNumber != 0},
+		FromDate:     nullDate(req.From),
+		ToDate:       nullDate(req.To),
+		StartedBy:    startedBy,
+	}
+	if err := j.runStore.StartRun(ctx, run); err != nil {
+		j.release(lock)
+		return nil, err
+	}
+
+	j.mu.Lock()
+	j.lock = lock
+	j.mu.Unlock()
+	return run, nil
+}
+
+// release gives up the import lock
+func (j *ImportJob) release(lock *store.AdvisoryLock) {
+	if err := lock.Release(); err != nil {
+		j.importer.logger.Error("Failed to release import lock", "error", err)
+	}
+}
+
+// Run executes a started run, updating its counts as each stage finishes and
+// calling progress, if set, with them. When the import ends the run's final
+// status and failures are recorded, even if ctx was cancelled. It returns the
+// error that stopped the import, if any.
+func (j *ImportJob) Run(ctx context.Context, run *model.ImportRun, req ImportRequest, progress func(model.ImportRun)) error {
+	defer func() {
+		j.mu.Lock()
+		lock := j.lock
+		j.lock = nil
+		j.mu.Unlock()
+		if lock != nil {
+			j.release(lock)
+		}
+	}()
+
+	ctx = logging.WithImportRunID(ctx, run.ID)
+	log := j.importer.logger
+	log.InfoContext(ctx, "Import run started",
+		"source", run.Source,
+		"started_by", run.StartedBy,
+		"mode", run.Mode,
+		"description", run.Description(),
+	)
+
+	var failures []model.ImportFailure
+	stage := func(update func()) {
+		update()
+		// A stage cut short by cancellation may still have written data
+		if err := j.versionStore.Bump(context.WithoutCancel(ctx)); err != nil {
+			log.ErrorContext(ctx, "Failed to bump data version", "error", err)
+		}
+		if progress != nil {
+			progress(*run)
+		}
+	}
+
+	err := j.runStages(ctx, run, req, stage, &failures)
+	if err != nil && ctx.Err() == nil {
+		log.ErrorContext(ctx, "Import failed", "error", err)
+	}
+
+	if err == nil && j.dispatcher != nil {
+		if stats, werr := j.dispatcher.DeliverDue(ctx); werr != nil {
+			log.ErrorContext(ctx, "Failed to deliver webhooks", "error", werr)
+		} else if stats.Delivered+stats.Retrying+stats.Dead > 0 {
+			log.InfoContext(ctx, "Webhooks delivered", "delivered", stats.Delivered, "retrying", stats.Retrying, "dead", stats.Dead)
+		}
+	}
+
+	switch {
+	case ctx.Err() != nil:
+		run.Status = model.ImportCancelled
+	case err != nil:
+		run.Status = model.ImportFailed
+	case run.Failures() > 0:
+		run.Status = model.ImportFailed
+		run.Error = sql.NullString{String: fmt.Sprintf("%d failed", run.Failures()), Valid: true}
+	default:
+		run.Status = model.ImportSucceeded
+	}
+	if err != nil {
+		run.Error = sql.NullString{String: err.Error(), Valid: true}
+	}
+
+	// Record the outcome even when shutting down mid-import
+	if ferr := j.runStore.FinishRun(context.Background(), run, failures); ferr != nil {
+		log.ErrorContext(ctx, "Failed to record import run", "error", ferr)
+	}
+
+	log.InfoContext(ctx, "Import run finished",
+		"status", run.Status,
+		"duration", run.Duration().String(),
+		"failures", run.Failures(),
+	)
+	return err
+}
+
+// runStages runs the importer for the request's mode
+func (j *ImportJob) runStages(ctx context.Context, run *model.ImportRun, req ImportRequest, stage func(func()), failures *[]model.ImportFailure) error {
+	date := req.Date.Format("2006-01-02")
+
+	switch req.Mode {
+	case model.ImportModeTitle:
+		stats, err := j.importer.ImportSingleTitle(ctx, req.TitleNumber, date, req.Date)
+		stage(func() { *failures = append(*failures, applyImportStats(run, stats)...) })
+		if err != nil {
+			return err
+		}
+		j.importer.PrintSummary(ctx, stats)
+		return nil
+
+	case model.ImportModeReindex:
+		stats, err := j.importer.Reindex(ctx, req.TitleNumber)
+		stage(func() { *failures = append(*failures, applyImportStats(run, stats)...) })
+		if stats != nil {
+			j.importer.PrintSummary(ctx, stats)
+		}
+		return err
+
+	case model.ImportModeRange, model.ImportModeHistory:
+		stats, err := j.importer.ImportHistory(ctx, HistoryRange{From: req.From, To: req.To, TitleNumber: req.TitleNumber})
+		stage(func() { *failures = append(*failures, applyHistoricalStats(run, stats)...) })
+		if stats != nil {
+			j.importer.PrintHistoricalSummary(ctx, stats)
+		}
+		return err
+	}
+
+	var stats *ImportStats
+	var err error
+	if req.Mode == model.ImportModeIncremental {
+		stats, err = j.importer.ImportIncremental(ctx, date)
+	} else {
+		stats, err = j.importer.Import(ctx, date)
+	}
+	stage(func() { *failures = append(*failures, applyImportStats(run, stats)...) })
+	if err != nil {
+		return err
+	}
+	j.importer.PrintSummary(ctx, stats)
+
+	log := j.importer.logger
+	log.InfoContext(ctx, "Starting agency import")
+	agencyStats, err := j.importer.ImportAgencies(ctx, req.Date)
+	stage(func() { *failures = append(*failures, applyAgencyStats(run, agencyStats)...) })
+	if err != nil {
+		return fmt.Errorf("agency import failed: %w", err)
+	}
+	j.importer.PrintAgencySummary(ctx, agencyStats)
+
+	log.InfoContext(ctx, "Calculating system metrics")
+	systemMetrics, err := j.metrics.CalculateAndStore(ctx)
+	if err != nil {
+		log.ErrorContext(ctx, "Failed to calculate metrics", "error", err)
+		return nil
+	}
+	log.InfoContext(ctx, "System metrics",
+		"total_titles", systemMetrics.TotalTitles,
+		"total_words", systemMetrics.TotalWords,
+		"total_sections", systemMetrics.TotalSections,
+		"total_agencies", systemMetrics.TotalAgencies,
+		"average_density", fmt.Sprintf("%.2f", systemMetrics.AverageDensity),
+		"largest_title", systemMetrics.LargestTitle,
+		"largest_title_words", systemMetrics.LargestTitleWords,
+		"top_agency", systemMetrics.TopAgency,
+		"top_agency_words", systemMetrics.TopAgencyWords,
+	)
+	return nil
+}
+
+// applyImportStats copies title counts onto the run and returns the failures
+func applyImportStats(run *model.ImportRun, stats *ImportStats) []model.ImportFailure {
+	if stats == nil {
+		return nil
+	}
+	run.TitlesTotal = stats.Total
+	run.TitlesImported = stats.Imported
+	run.TitlesChanged = stats.Changed
+	run.TitlesUnchanged = stats.Unchanged
+	run.TitlesSkipped = stats.Skipped
+	run.TitlesFailed = stats.Failed
+	return stats.Failures
+}
+
+// applyAgencyStats copies agency counts onto the run and returns the failures
+func applyAgencyStats(run *model.ImportRun, stats *AgencyStats) []model.ImportFailure {
+	if stats == nil {
+		return nil
+	}
+	run.AgenciesTotal = stats.Total
+	run.AgenciesImported = stats.Imported
+	run.AgenciesFailed = stats.Failed
+	return stats.Failures
+}
+
+// applyHistoricalStats copies version counts onto the run and returns the
+// failures
+func applyHistoricalStats(run *model.ImportRun, stats *HistoricalStats) []model.ImportFailure {
+	if stats == nil {
+		return nil
+	}
+	run.TitlesImported = stats.TitlesProcessed
+	run.VersionsProcessed = stats.VersionsProcessed
+	run.SnapshotsCreated = stats.SnapshotsCreated
+	run.TitlesFailed = stats.Failed
+	return stats.Failures
+}
+
+func nullDate(t time.Time) sql.NullTime {
+	return sql.NullTime{Time: t, Valid: !t.IsZero()}
+}
@@ -0,0 +1,69 @@
+package service_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/jjenkins/usds/internal/model"
+	"github.com/jjenkins/usds/internal/service"
+	"github.com/jjenkins/usds/internal/service/ecfrtest"
+	"github.com/jjenkins/usds/internal/store"
+)
+
+func TestImportJobLock(t *testing.T) {
+	forEachBackend(t, func(t *testing.T, r repos) {
+		if r.db == nil {
+			t.Skip("the import lock needs a database")
+		}
+
+		ctx := context.Background()
+		srv := ecfrtest.NewServer(t)
+		runStore := store.NewImportRunStore(r.db)
+		newJob := func() *service.ImportJob {
+			return service.NewImportJob(r.db, newImporter(srv, r), service.NewMetricsService(r.db), nil, runStore, store.NewDataVersionStore(r.db))
+		}
+		// Separate jobs stand in for the CLI, a server and a daemon
+		cli, web, daemon := newJob(), newJob(), newJob()
+
+		req := service.ImportRequest{Mode: model.ImportModeTitle, Date: jun, TitleNumber: 1}
+		run, err := cli.Start(ctx, req, model.ImportSourceCLI, "alice")
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		// Neither the admin pages nor the daemon can start another
+		if _, err := web.Start(ctx, req, model.ImportSourceWeb, "bob"); !errors.Is(err, service.ErrImportRunning) {
+			t.Errorf("web Start = %v, want ErrImportRunning", err)
+		}
+		scheduler, err := service.NewImportScheduler(daemon, "@daily", true)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := scheduler.RunOnce(ctx); err != nil {
+			t.Errorf("RunOnce = %v, want the run skipped", err)
+		}
+		runs, err := runStore.ListRuns(ctx, store.ImportRunFilter{Limit: 10})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(runs) != 1 {
+			t.Errorf("%d runs recorded, want only the CLI's", len(runs))
+		}
+
+		// Finishing the run releases the lock
+		if err := cli.Run(ctx, run, req, nil); err != nil {
+			t.Fatal(err)
+		}
+		if run.Status != model.ImportSucceeded {
+			t.Errorf("run status = %s, want %s", run.Status, model.ImportSucceeded)
+		}
+		next, err := web.Start(ctx, req, model.ImportSourceWeb, "bob")
+		if err != nil {
+			t.Fatalf("web Start after the run = %v", err)
+		}
+		if err := web.Run(ctx, next, req, nil); err != nil {
+			t.Fatal(err)
+		}
+	})
+}
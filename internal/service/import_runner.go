@@ -0,0 +1,265 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"sync"
+
+	"github.com/jjenkins/usds/internal/model"
+)
+
+const (
+	maxImportRuns     = 20    // Runs whose logs are kept in memory
+	maxImportRunLines = 10000 // Log lines kept per run
+	subscriberBuffer  = 256   // Lines buffered per log subscriber
+)
+
+// ErrImportRunning is returned when a run is started while another is
+// active, in this process or another sharing the database
+var ErrImportRunning = errors.New("an import is already running")
+
+// ImportRunner runs one import at a time in the background for the web
+// server. The run history lives in the ImportRunStore; the runner keeps the
+// live state and log of its recent runs for streaming.
+type ImportRunner struct {
+	job *ImportJob
+	wg  sync.WaitGroup // Running executes
+
+	mu     sync.Mutex
+	runs   []*importRunState // Oldest first
+	active *importRunState
+}
+
+// importRunState is a run plus its log and the channels streaming it
+type importRunState struct {
+	run         model.ImportRun
+	cancel      context.CancelFunc
+	lines       []string
+	dropped     int    // Lines trimmed from the front of lines
+	partial     string // Text written since the last newline
+	subscribers map[chan string]struct{}
+}
+
+// NewImportRunner creates an ImportRunner
+func NewImportRunner(job *ImportJob) *ImportRunner {
+	return &ImportRunner{job: job}
+}
+
+// Start records a run and begins it in the background, or returns
+// ErrImportRunning if another run, from this server or elsewhere, has not
+// finished
+func (r *ImportRunner) Start(req ImportRequest, startedBy string) (model.ImportRun, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.active != nil {
+		return model.ImportRun{}, ErrImportRunning
+	}
+
+	run, err := r.job.Start(context.Background(), req, model.ImportSourceWeb, startedBy)
+	if err != nil {
+		return model.ImportRun{}, err
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	state := &importRunState{
+		run:         *run,
+		cancel:      cancel,
+		subscribers: make(map[chan string]struct{}),
+	}
+	r.active = state
+	r.runs = append(r.runs, state)
+	if len(r.runs) > maxImportRuns {
+		r.runs = r.runs[len(r.runs)-maxImportRuns:]
+	}
+
+	r.wg.Add(1)
+	go r.execute(ctx, state, run, req)
+
+	return *run, nil
+}
+
+// Cancel stops a running import; it reports false if no such run is active
+func (r *ImportRunner) Cancel(id int) bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.active == nil || r.active.run.ID != id {
+		return false
+	}
+	r.active.cancel()
+	return true
+}
+
+// Shutdown cancels the running import, if any, and waits until its outcome
+// is recorded or ctx is done
+func (r *ImportRunner) Shutdown(ctx context.Context) error {
+	r.mu.Lock()
+	if r.active != nil {
+		r.active.cancel()
+	}
+	r.mu.Unlock()
+
+	done := make(chan struct{})
+	go func() {
+		r.wg.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// Active returns the running import, if any
+func (r *ImportRunner) Active() (model.ImportRun, bool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.active == nil {
+		return model.ImportRun{}, false
+	}
+	return r.active.run, true
+}
+
+// Run returns a run this server started and its log lines, if still kept
+func (r *ImportRunner) Run(id int) (model.ImportRun, []string, bool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	state := r.find(id)
+	if state == nil {
+		return model.ImportRun{}, nil, false
+	}
+	return state.run, append([]string(nil), state.lines...), true
+}
+
+// LogSubscription streams an import run's log
+type LogSubscription struct {
+	First   int           // Number of the first line in Lines; lines are numbered from 1
+	Lines   []string      // Lines logged so far
+	Updates <-chan string // Lines logged later; closed when the run finishes
+	Close   func()        // Stops updates; call when done reading
+}
+
+// Subscribe returns the run's log lines numbered after the given line and a
+// channel of lines logged later, which is closed at once if the run has
+// already finished. A subscriber that falls behind misses lines rather than
+// slowing the import.
+func (r *ImportRunner) Subscribe(id, after int) (*LogSubscription, bool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	state := r.find(id)
+	if state == nil {
+		return nil, false
+	}
+
+	start := after - state.dropped
+	if start < 0 {
+		start = 0
+	}
+	sub := &LogSubscription{First: state.dropped + start + 1, Close: func() {}}
+	if start < len(state.lines) {
+		sub.Lines = append(sub.Lines, state.lines[start:]...)
+	}
+
+	ch := make(chan string, subscriberBuffer)
+	sub.Updates = ch
+	if state.subscribers == nil {
+		close(ch)
+		return sub, true
+	}
+
+	state.subscribers[ch] = struct{}{}
+	sub.Close = func() {
+		r.mu.Lock()
+		defer r.mu.Unlock()
+		if _, found := state.subscribers[ch]; found {
+			delete(state.subscribers, ch)
+			close(ch)
+		}
+	}
+	return sub, true
+}
+
+func (r *ImportRunner) find(id int) *importRunState {
+	for _, state := range r.runs {
+		if state.run.ID == id {
+			return state
+		}
+	}
+	return nil
+}
+
+// execute runs the import, logging to the run's log as well as stdout. The
+// log's subscribers are closed once the outcome is recorded, so a page that
+// reloads when its stream ends sees the final run.
+func (r *ImportRunner) execute(ctx context.Context, state *importRunState, run *model.ImportRun, req ImportRequest) {
+	defer r.wg.Done()
+	defer state.cancel()
+
+	r.job.importer.SetLogOutput(&runLog{runner: r, state: state})
+	defer r.job.importer.SetLogOutput(nil)
+
+	r.job.Run(ctx, run, req, func(progress model.ImportRun) {
+		r.mu.Lock()
+		defer r.mu.Unlock()
+		state.run = progress
+	})
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	state.run = *run
+	if state.partial != "" {
+		state.append(state.partial)
+		state.partial = ""
+	}
+	for ch := range state.subscribers {
+		close(ch)
+	}
+	state.subscribers = nil
+	if r.active == state {
+		r.active = nil
+	}
+}
+
+// append adds a complete line to the log and sends it to subscribers;
+// callers hold the runner's lock
+func (s *importRunState) append(line string) {
+	s.lines = append(s.lines, line)
+	if len(s.lines) > maxImportRunLines {
+		trim := len(s.lines) - maxImportRunLines
+		s.lines = append([]string(nil), s.lines[trim:]...)
+		s.dropped += trim
+	}
+	for ch := range s.subscribers {
+		select {
+		case ch <- line:
+		default:
+		}
+	}
+}
+
+// runLog is the io.Writer the importer's loggers write a run's output to
+type runLog struct {
+	runner *ImportRunner
+	state  *importRunState
+}
+
+func (w *runLog) Write(p []byte) (int, error) {
+	w.runner.mu.Lock()
+	defer w.runner.mu.Unlock()
+
+	text := w.state.partial + string(p)
+	lines := strings.Split(text, "\n")
+	for _, line := range lines[:len(lines)-1] {
+		w.state.append(line)
+	}
+	w.state.partial = lines[len(lines)-1]
+	return len(p), nil
+}
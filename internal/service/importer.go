@@ -6,11 +6,12 @@ import (
 	"database/sql"
 	"encoding/hex"
 	"fmt"
-	"log"
-	"os"
+	"io"
+	"log/slog"
 	"sort"
 	"time"
 
+	"github.com/jjenkins/usds/internal/logging"
 	"github.com/jjenkins/usds/internal/model"
 	"github.com/jjenkins/usds/internal/store"
 )
@@ -23,43 +24,82 @@ type ImportStats struct {
 	Unchanged int
 	Skipped   int
 	Failed    int
+	Failures  []model.ImportFailure
+}
+
+// fail counts a failed title and keeps it for the run's failure log
+func (s *ImportStats) fail(f model.ImportFailure) {
+	s.Failed++
+	s.Failures = append(s.Failures, f)
 }
 
 // Importer orchestrates the eCFR data import process
 type Importer struct {
-	client      *ECFRClient
-	parser      *Parser
-	titleStore  *store.TitleStore
-	agencyStore *store.AgencyStore
-	logger      *log.Logger
-	errLogger   *log.Logger
+	client       *ECFRClient
+	parser       *Parser
+	titleStore   store.TitleRepository
+	agencyStore  store.AgencyRepository
+	sectionStore store.SectionRepository
+	quarantine   store.QuarantineRepository
+	maxWordDrop  int
+	logger       *slog.Logger
 }
 
-// NewImporter creates a new Importer
-func NewImporter(client *ECFRClient, parser *Parser, titleStore *store.TitleStore, agencyStore *store.AgencyStore) *Importer {
+// NewImporter creates a new Importer. Fetched content that fails validation
+// is held in quarantine instead of being imported.
+func NewImporter(client *ECFRClient, parser *Parser, titleStore store.TitleRepository, agencyStore store.AgencyRepository, sectionStore store.SectionRepository, quarantine store.QuarantineRepository) *Importer {
 	return &Importer{
-		client:      client,
-		parser:      parser,
-		titleStore:  titleStore,
-		agencyStore: agencyStore,
-		logger:      log.New(os.Stdout, "", log.LstdFlags),
-		errLogger:   log.New(os.Stderr, "ERROR: ", log.LstdFlags),
+		client:       client,
+		parser:       parser,
+		titleStore:   titleStore,
+		agencyStore:  agencyStore,
+		sectionStore: sectionStore,
+		quarantine:   quarantine,
+		logger:       slog.Default(),
 	}
 }
 
+// SetMaxWordDrop quarantines title versions whose word count falls by more
+// than percent since the title's latest snapshot. Zero turns the check off,
+// and is what a new Importer starts with; the commands pass
+// import.max_word_drop, which defaults to 50.
+func (i *Importer) SetMaxWordDrop(percent int) {
+	i.maxWordDrop = percent
+}
+
+// SetLogOutput also writes each log record to w as a readable line, so a
+// caller such as ImportRunner can capture a run's log. A nil w stops it.
+func (i *Importer) SetLogOutput(w io.Writer) {
+	if w == nil {
+		i.logger = slog.Default()
+		return
+	}
+	i.logger = slog.New(logging.Tee(slog.Default().Handler(), logging.NewLineHandler(w)))
+}
+
 // Import fetches and stores all eCFR titles for the given date
 func (i *Importer) Import(ctx context.Context, date string) (*ImportStats, error) {
+	return i.importTitles(ctx, date, false)
+}
+
+// ImportIncremental is Import, but skips titles whose latest amendment date
+// matches the one already stored, counting them as unchanged
+func (i *Importer) ImportIncremental(ctx context.Context, date string) (*ImportStats, error) {
+	return i.importTitles(ctx, date, true)
+}
+
+func (i *Importer) importTitles(ctx context.Context, date string, incremental bool) (*ImportStats, error) {
 	stats := &ImportStats{}
 
 	// Fetch list of all titles
-	i.logger.Println("Fetching titles list from eCFR API...")
+	i.logger.InfoContext(ctx, "Fetching titles list from eCFR API")
 	titles, err := i.client.FetchTitles(ctx)
 	if err != nil {
 		return nil, fmt.Errorf("failed to fetch titles list: %w", err)
 	}
 
 	stats.Total = len(titles)
-	i.logger.Printf("Found %d titles to process", stats.Total)
+	i.logger.InfoContext(ctx, "Found titles to process", "count", stats.Total)
 
 	// Parse the snapshot date
 	snapshotDate, err := time.Parse("2006-01-02", date)
@@ -79,16 +119,34 @@ func (i *Importer) Import(ctx context.Context, date string) (*ImportStats, error
 
 		// Skip reserved titles
 		if titleMeta.Reserved {
-			i.logger.Printf("%s Skipping Title %d: %s (reserved)", progress, titleMeta.Number, titleMeta.Name)
+			i.logger.InfoContext(ctx, "Skipping reserved title", "progress", progress, "title", titleMeta.Number, "name", titleMeta.Name)
 			stats.Skipped++
 			continue
 		}
 
-		i.logger.Printf("%s Importing Title %d: %s...", progress, titleMeta.Number, titleMeta.Name)
+		if incremental {
+			amended, err := i.amendedSinceImport(ctx, titleMeta)
+			if err != nil {
+				i.logger.ErrorContext(ctx, "Failed to check title", "title", titleMeta.Number, "error", err)
+				stats.fail(titleFailure(titleMeta.Number, "", err))
+				continue
+			}
+			if !amended {
+				i.logger.InfoContext(ctx, "Title not amended since last import", "progress", progress, "title", titleMeta.Number)
+				// Keep the title's freshness current for readiness checks
+				if err := i.titleStore.MarkFetched(ctx, titleMeta.Number); err != nil {
+					i.logger.ErrorContext(ctx, "Failed to mark title fetched", "title", titleMeta.Number, "error", err)
+				}
+				stats.Unchanged++
+				continue
+			}
+		}
+
+		i.logger.InfoContext(ctx, "Importing title", "progress", progress, "title", titleMeta.Number, "name", titleMeta.Name)
 
 		if err := i.importTitle(ctx, titleMeta, date, snapshotDate, stats); err != nil {
-			i.errLogger.Printf("Failed to import Title %d: %v", titleMeta.Number, err)
-			stats.Failed++
+			i.logger.ErrorContext(ctx, "Failed to import title", "title", titleMeta.Number, "error", err)
+			stats.fail(titleFailure(titleMeta.Number, "", err))
 			continue
 		}
 
@@ -103,12 +161,130 @@ func (i *Importer) Import(ctx context.Context, date string) (*ImportStats, error
 	return stats, nil
 }
 
+// amendedSinceImport reports whether the eCFR lists a newer amendment date
+// for the title than the stored one, or the title was never imported. A
+// title whose latest snapshot is missing its sections counts as amended, so
+// that it is imported and re-indexed.
+func (i *Importer) amendedSinceImport(ctx context.Context, meta model.TitleMeta) (bool, error) {
+	stored, err := i.titleStore.GetByNumber(ctx, meta.Number)
+	if err != nil {
+		return false, err
+	}
+	if stored == nil || !stored.LastAmendedDate.Valid || meta.LatestAmendedOn == "" {
+		return true, nil
+	}
+	if meta.LatestAmendedOn != stored.LastAmendedDate.Time.Format("2006-01-02") {
+		return true, nil
+	}
+
+	latest, err := i.latestUnindexedSnapshot(ctx, meta.Number)
+	if err != nil {
+		return false, err
+	}
+	return latest != nil, nil
+}
+
+// latestUnindexedSnapshot returns the title's latest snapshot if it counted
+// sections but has none stored, or nil
+func (i *Importer) latestUnindexedSnapshot(ctx context.Context, titleNumber int) (*model.TitleSnapshot, error) {
+	snapshots, err := i.titleStore.GetSnapshots(ctx, titleNumber)
+	if err != nil {
+		return nil, err
+	}
+	if len(snapshots) == 0 || snapshots[0].SectionCount == 0 {
+		return nil, nil
+	}
+
+	indexed, err := i.sectionStore.HasSections(ctx, titleNumber, snapshots[0].SnapshotDate)
+	if err != nil {
+		return nil, err
+	}
+	if indexed {
+		return nil, nil
+	}
+	return &snapshots[0], nil
+}
+
+// Reindex stores the sections of each title's latest snapshot that has
+// none, fetching the snapshot's content from eCFR again. This backfills
+// snapshots whose sections failed to save, or that were imported before
+// section text was kept. A title number limits it to that title. Titles
+// already indexed count as unchanged.
+func (i *Importer) Reindex(ctx context.Context, titleNumber int) (*ImportStats, error) {
+	stats := &ImportStats{}
+
+	titles, err := i.titleStore.GetAll(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get titles: %w", err)
+	}
+
+	for _, t := range titles {
+		select {
+		case <-ctx.Done():
+			return stats, ctx.Err()
+		default:
+		}
+
+		if titleNumber != 0 && t.TitleNumber != titleNumber {
+			continue
+		}
+		stats.Total++
+
+		snap, err := i.latestUnindexedSnapshot(ctx, t.TitleNumber)
+		if err != nil {
+			i.logger.ErrorContext(ctx, "Failed to check title sections", "title", t.TitleNumber, "error", err)
+			stats.fail(titleFailure(t.TitleNumber, "", err))
+			continue
+		}
+		if snap == nil {
+			stats.Unchanged++
+			continue
+		}
+
+		date := snap.SnapshotDate.Format("2006-01-02")
+		i.logger.InfoContext(ctx, "Re-indexing title", "title", t.TitleNumber, "date", date)
+		if err := i.reindexSnapshot(ctx, snap); err != nil {
+			i.logger.ErrorContext(ctx, "Failed to re-index title", "title", t.TitleNumber, "date", date, "error", err)
+			stats.fail(titleFailure(t.TitleNumber, date, err))
+			continue
+		}
+		stats.Imported++
+
+		time.Sleep(i.client.Delay())
+	}
+
+	return stats, nil
+}
+
+// reindexSnapshot fetches a snapshot's content and stores its sections,
+// provided the content is still what the snapshot recorded
+func (i *Importer) reindexSnapshot(ctx context.Context, snap *model.TitleSnapshot) error {
+	date := snap.SnapshotDate.Format("2006-01-02")
+	content, err := i.client.FetchTitleContent(ctx, date, snap.TitleNumber)
+	if err != nil {
+		return fmt.Errorf("failed to fetch content: %w", err)
+	}
+
+	result, err := i.parser.Parse(content)
+	if err != nil {
+		return err
+	}
+	if result.Checksum != snap.Checksum {
+		return fmt.Errorf("content is now %s, but the snapshot recorded %s", result.Checksum, snap.Checksum)
+	}
+
+	if err := i.sectionStore.ReplaceSections(ctx, snap.TitleNumber, snap.SnapshotDate, result.Sections); err != nil {
+		return fmt.Errorf("failed to save sections: %w", err)
+	}
+	return nil
+}
+
 // ImportSingleTitle imports a specific title by number for the given date
 func (i *Importer) ImportSingleTitle(ctx context.Context, titleNumber int, date string, snapshotDate time.Time) (*ImportStats, error) {
 	stats := &ImportStats{Total: 1}
 
 	// Fetch the titles list to get metadata for the requested title
-	i.logger.Println("Fetching title metadata from eCFR API...")
+	i.logger.InfoContext(ctx, "Fetching title metadata from eCFR API")
 	titles, err := i.client.FetchTitles(ctx)
 	if err != nil {
 		return nil, fmt.Errorf("failed to fetch titles list: %w", err)
@@ -128,16 +304,16 @@ func (i *Importer) ImportSingleTitle(ctx context.Context, titleNumber int, date
 	}
 
 	if titleMeta.Reserved {
-		i.logger.Printf("Title %d is reserved, skipping", titleNumber)
+		i.logger.InfoContext(ctx, "Skipping reserved title", "title", titleNumber)
 		stats.Skipped++
 		return stats, nil
 	}
 
 	// Import the title
-	i.logger.Printf("Importing Title %d: %s", titleMeta.Number, titleMeta.Name)
+	i.logger.InfoContext(ctx, "Importing title", "title", titleMeta.Number, "name", titleMeta.Name)
 	if err := i.importTitle(ctx, *titleMeta, date, snapshotDate, stats); err != nil {
-		i.errLogger.Printf("Failed to import Title %d: %v", titleMeta.Number, err)
-		stats.Failed++
+		i.logger.ErrorContext(ctx, "Failed to import title", "title", titleMeta.Number, "error", err)
+		stats.fail(titleFailure(titleMeta.Number, "", err))
 		return stats, err
 	}
 
@@ -161,10 +337,10 @@ func (i *Importer) importTitle(ctx context.Context, meta model.TitleMeta, date s
 		return fmt.Errorf("failed to fetch content: %w", err)
 	}
 
-	// Parse content for metrics
-	parseResult, err := i.parser.Parse(content)
+	// Parse and validate content before anything is saved
+	parseResult, err := i.checkContent(ctx, meta, snapshotDate, content)
 	if err != nil {
-		return fmt.Errorf("failed to parse content: %w", err)
+		return err
 	}
 
 	// Parse last amended date
@@ -182,6 +358,7 @@ func (i *Importer) importTitle(ctx context.Context, meta model.TitleMeta, date s
 		TitleName:       meta.Name,
 		WordCount:       parseResult.WordCount,
 		SectionCount:    parseResult.SectionCount,
+		Readability:     readability(parseResult),
 		Checksum:        parseResult.Checksum,
 		LastAmendedDate: lastAmendedDate,
 		FetchedAt:       time.Now(),
@@ -193,31 +370,63 @@ func (i *Importer) importTitle(ctx context.Context, meta model.TitleMeta, date s
 		return fmt.Errorf("failed to save title: %w", err)
 	}
 
+	// Index section text alongside each new snapshot, or one whose sections
+	// an earlier import failed to save
+	if err := i.indexSections(ctx, meta.Number, snapshotDate, parseResult.Sections, changed); err != nil {
+		return fmt.Errorf("failed to save sections: %w", err)
+	}
+
 	// Track change statistics
 	if changed {
-		i.logger.Printf("  Title %d changed (snapshot created)", meta.Number)
+		i.logger.InfoContext(ctx, "Title changed, snapshot created", "title", meta.Number)
 		stats.Changed++
 	} else {
-		i.logger.Printf("  Title %d unchanged", meta.Number)
+		i.logger.InfoContext(ctx, "Title unchanged", "title", meta.Number)
 		stats.Unchanged++
 	}
 
 	return nil
 }
 
-// PrintSummary prints the import statistics
-func (i *Importer) PrintSummary(stats *ImportStats) {
-	i.logger.Println("")
-	i.logger.Println("=== Import Summary ===")
-	i.logger.Printf("Total titles:    %d", stats.Total)
-	i.logger.Printf("Imported:        %d", stats.Imported)
-	i.logger.Printf("Changed:         %d", stats.Changed)
-	i.logger.Printf("Unchanged:       %d", stats.Unchanged)
-	i.logger.Printf("Skipped:         %d (reserved)", stats.Skipped)
-	i.logger.Printf("Failed:          %d", stats.Failed)
+// indexSections stores a snapshot's sections when the snapshot is new. The
+// snapshot is saved before its sections, so an unchanged snapshot is also
+// indexed if it has none stored: importing the same date again re-indexes a
+// title whose sections failed to save.
+func (i *Importer) indexSections(ctx context.Context, titleNumber int, snapshotDate time.Time, sections []model.Section, changed bool) error {
+	if !changed {
+		if len(sections) == 0 {
+			return nil
+		}
+		indexed, err := i.sectionStore.HasSections(ctx, titleNumber, snapshotDate)
+		if err != nil {
+			return err
+		}
+		if indexed {
+			return nil
+		}
+		i.logger.InfoContext(ctx, "Re-indexing snapshot sections", "title", titleNumber, "date", snapshotDate.Format("2006-01-02"))
+	}
+	return i.sectionStore.ReplaceSections(ctx, titleNumber, snapshotDate, sections)
+}
+
+// readability converts a parse result's reading ease score for storage,
+// leaving it NULL for titles without any text
+func readability(result *ParseResult) sql.NullFloat64 {
+	return sql.NullFloat64{Float64: result.Readability, Valid: result.WordCount > 0}
+}
 
+// PrintSummary logs the import statistics
+func (i *Importer) PrintSummary(ctx context.Context, stats *ImportStats) {
 	successRate := float64(stats.Imported) / float64(stats.Total-stats.Skipped) * 100
-	i.logger.Printf("Success rate:    %.1f%%", successRate)
+	i.logger.InfoContext(ctx, "Import summary",
+		"total", stats.Total,
+		"imported", stats.Imported,
+		"changed", stats.Changed,
+		"unchanged", stats.Unchanged,
+		"skipped", stats.Skipped,
+		"failed", stats.Failed,
+		"success_rate", fmt.Sprintf("%.1f%%", successRate),
+	)
 }
 
 // AgencyStats tracks agency import statistics
@@ -225,19 +434,26 @@ type AgencyStats struct {
 	Total    int
 	Imported int
 	Failed   int
+	Failures []model.ImportFailure
+}
+
+// fail counts a failed agency and keeps it for the run's failure log
+func (s *AgencyStats) fail(f model.ImportFailure) {
+	s.Failed++
+	s.Failures = append(s.Failures, f)
 }
 
 // ImportAgencies fetches and stores all agencies with hierarchy
 func (i *Importer) ImportAgencies(ctx context.Context, snapshotDate time.Time) (*AgencyStats, error) {
 	stats := &AgencyStats{}
 
-	i.logger.Println("Fetching agencies from eCFR Admin API...")
+	i.logger.InfoContext(ctx, "Fetching agencies from eCFR Admin API")
 	agencies, err := i.client.FetchAgencies(ctx)
 	if err != nil {
 		return nil, fmt.Errorf("failed to fetch agencies: %w", err)
 	}
 
-	i.logger.Printf("Found %d top-level agencies", len(agencies))
+	i.logger.InfoContext(ctx, "Found top-level agencies", "count", len(agencies))
 
 	// Clear existing agency-title links for re-import
 	if err := i.agencyStore.ClearAgencyTitles(ctx); err != nil {
@@ -245,20 +461,20 @@ func (i *Importer) ImportAgencies(ctx context.Context, snapshotDate time.Time) (
 	}
 
 	// Pass 1: Insert all agencies with hierarchy (flattened but with parent_id)
-	i.logger.Println("Pass 1: Inserting agencies...")
+	i.logger.InfoContext(ctx, "Pass 1: Inserting agencies")
 	slugToID := make(map[string]int)
 	if err := i.insertAgenciesRecursive(ctx, agencies, sql.NullInt64{}, slugToID, stats); err != nil {
 		return nil, fmt.Errorf("failed to insert agencies: %w", err)
 	}
 
 	// Pass 2: Link agencies to titles via cfr_references
-	i.logger.Println("Pass 2: Linking agencies to titles...")
+	i.logger.InfoContext(ctx, "Pass 2: Linking agencies to titles")
 	if err := i.linkAgenciesToTitles(ctx, agencies, slugToID); err != nil {
 		return nil, fmt.Errorf("failed to link agencies: %w", err)
 	}
 
 	// Pass 3: Calculate roll-up word counts (bottom-up)
-	i.logger.Println("Pass 3: Calculating roll-up word counts...")
+	i.logger.InfoContext(ctx, "Pass 3: Calculating roll-up word counts")
 	if err := i.calculateRollupWordCounts(ctx, snapshotDate); err != nil {
 		return nil, fmt.Errorf("failed to calculate word counts: %w", err)
 	}
@@ -279,8 +495,8 @@ func (i *Importer) insertAgenciesRecursive(ctx context.Context, agencies []model
 		}
 
 		if err := i.agencyStore.UpsertAgency(ctx, agency); err != nil {
-			i.errLogger.Printf("Failed to insert agency %s: %v", meta.Slug, err)
-			stats.Failed++
+			i.logger.ErrorContext(ctx, "Failed to insert agency", "agency", meta.Slug, "error", err)
+			stats.fail(agencyFailure(meta.Slug, err))
 			continue
 		}
 
@@ -309,7 +525,12 @@ func (i *Importer) linkAgenciesToTitles(ctx context.Context, agencies []model.Ag
 
 		for _, ref := range meta.CFRReferences {
 			if err := i.agencyStore.LinkAgencyTitle(ctx, agencyID, ref.Title); err != nil {
-				i.errLogger.Printf("Failed to link agency %s to title %d: %v", meta.Slug, ref.Title, err)
+				i.logger.ErrorContext(ctx, "Failed to link agency to title", "agency", meta.Slug, "title", ref.Title, "error", err)
+			}
+			if ref.Chapter != "" {
+				if err := i.agencyStore.LinkAgencyChapter(ctx, agencyID, ref.Title, ref.Chapter); err != nil {
+					i.logger.ErrorContext(ctx, "Failed to link agency to chapter", "agency", meta.Slug, "title", ref.Title, "chapter", ref.Chapter, "error", err)
+				}
 			}
 		}
 
@@ -391,22 +612,17 @@ func (i *Importer) calculateAgencyWordCount(ctx context.Context, agencyID int, a
 	}
 	sort.Ints(titleNums)
 
-	// Sum word counts from all unique titles and build checksum input
-	totalWordCount := 0
-	checksumInput := ""
+	// Sum word counts from all unique titles
+	wordCounts := make(map[int]int, len(titleNums))
 	for _, titleNum := range titleNums {
 		wordCount, err := i.agencyStore.GetTitleWordCount(ctx, titleNum)
 		if err != nil {
-			i.errLogger.Printf("Failed to get word count for title %d: %v", titleNum, err)
+			i.logger.ErrorContext(ctx, "Failed to get title word count", "title", titleNum, "error", err)
 			continue
 		}
-		totalWordCount += wordCount
-		checksumInput += fmt.Sprintf("%d:%d;", titleNum, wordCount)
+		wordCounts[titleNum] = wordCount
 	}
-
-	// Generate MD5 checksum for change detection
-	hash := md5.Sum([]byte(checksumInput))
-	checksum := hex.EncodeToString(hash[:])
+	totalWordCount, checksum := rollupChecksum(titleNums, wordCounts)
 
 	// Update agency with calculated counts
 	if err := i.agencyStore.UpdateWordCount(ctx, agencyID, totalWordCount, len(titleSet), checksum); err != nil {
@@ -424,25 +640,46 @@ func (i *Importer) calculateAgencyWordCount(ctx context.Context, agencyID int, a
 	}
 	snapshotCreated, err := i.agencyStore.InsertSnapshotIfChanged(ctx, snapshot, titleNums)
 	if err != nil {
-		i.errLogger.Printf("Failed to insert snapshot for agency %d: %v", agencyID, err)
+		i.logger.ErrorContext(ctx, "Failed to insert agency snapshot", "agency_id", agencyID, "error", err)
 	}
 
-	if snapshotCreated {
-		i.logger.Printf("  Agency %s: %d words, %d titles (snapshot created)", agency.AgencyName, totalWordCount, len(titleSet))
-	} else {
-		i.logger.Printf("  Agency %s: %d words, %d titles (unchanged)", agency.AgencyName, totalWordCount, len(titleSet))
-	}
+	i.logger.InfoContext(ctx, "Agency word count",
+		"agency", agency.Slug,
+		"words", totalWordCount,
+		"titles", len(titleSet),
+		"snapshot_created", snapshotCreated,
+	)
 
 	return titleSet, nil
 }
 
-// PrintAgencySummary prints agency import statistics
-func (i *Importer) PrintAgencySummary(stats *AgencyStats) {
-	i.logger.Println("")
-	i.logger.Println("=== Agency Import Summary ===")
-	i.logger.Printf("Total agencies:  %d", stats.Total)
-	i.logger.Printf("Imported:        %d", stats.Imported)
-	i.logger.Printf("Failed:          %d", stats.Failed)
+// rollupChecksum sums the word counts of an agency's titles, sorted by
+// number, and checksums the "title:words;" pairs for change detection.
+// Titles missing from wordCounts are left out of both.
+func rollupChecksum(titleNums []int, wordCounts map[int]int) (int, string) {
+	totalWordCount := 0
+	checksumInput := ""
+	for _, titleNum := range titleNums {
+		wordCount, ok := wordCounts[titleNum]
+		if !ok {
+			continue
+		}
+		totalWordCount += wordCount
+		checksumInput += fmt.Sprintf("%d:%d;", titleNum, wordCount)
+	}
+
+	// Generate MD5 checksum for change detection
+	hash := md5.Sum([]byte(checksumInput))
+	return totalWordCount, hex.EncodeToString(hash[:])
+}
+
+// PrintAgencySummary logs agency import statistics
+func (i *Importer) PrintAgencySummary(ctx context.Context, stats *AgencyStats) {
+	i.logger.InfoContext(ctx, "Agency import summary",
+		"total", stats.Total,
+		"imported", stats.Imported,
+		"failed", stats.Failed,
+	)
 }
 
 // HistoricalStats tracks historical import statistics
@@ -451,20 +688,52 @@ type HistoricalStats struct {
 	VersionsProcessed int
 	SnapshotsCreated  int
 	Failed            int
+	Failures          []model.ImportFailure
+}
+
+// fail counts a failed title or version and keeps it for the run's failure log
+func (s *HistoricalStats) fail(f model.ImportFailure) {
+	s.Failed++
+	s.Failures = append(s.Failures, f)
+}
+
+// HistoryRange limits a historical import; the zero value imports every
+// version of every title
+type HistoryRange struct {
+	From        time.Time // Earliest version date, inclusive
+	To          time.Time // Latest version date, inclusive
+	TitleNumber int       // Only this title when non-zero
+}
+
+// includes reports whether a version date falls inside the range
+func (r HistoryRange) includes(date time.Time) bool {
+	if !r.From.IsZero() && date.Before(r.From) {
+		return false
+	}
+	if !r.To.IsZero() && date.After(r.To) {
+		return false
+	}
+	return true
 }
 
 // ImportAllHistory fetches all historical versions for all titles
 func (i *Importer) ImportAllHistory(ctx context.Context) (*HistoricalStats, error) {
+	return i.ImportHistory(ctx, HistoryRange{})
+}
+
+// ImportHistory fetches the historical versions of each title published
+// within the range
+func (i *Importer) ImportHistory(ctx context.Context, r HistoryRange) (*HistoricalStats, error) {
 	stats := &HistoricalStats{}
 
 	// Fetch list of all titles
-	i.logger.Println("Fetching titles list from eCFR API...")
+	i.logger.InfoContext(ctx, "Fetching titles list from eCFR API")
 	titles, err := i.client.FetchTitles(ctx)
 	if err != nil {
 		return nil, fmt.Errorf("failed to fetch titles list: %w", err)
 	}
 
-	i.logger.Printf("Found %d titles", len(titles))
+	i.logger.InfoContext(ctx, "Found titles", "count", len(titles))
 
 	// Process each title
 	for titleIdx, titleMeta := range titles {
@@ -474,23 +743,34 @@ func (i *Importer) ImportAllHistory(ctx context.Context) (*HistoricalStats, erro
 		default:
 		}
 
+		if r.TitleNumber != 0 && titleMeta.Number != r.TitleNumber {
+			continue
+		}
+
 		// Skip reserved titles
 		if titleMeta.Reserved {
-			i.logger.Printf("[%d/%d] Skipping Title %d: %s (reserved)", titleIdx+1, len(titles), titleMeta.Number, titleMeta.Name)
+			i.logger.InfoContext(ctx, "Skipping reserved title", "progress", fmt.Sprintf("[%d/%d]", titleIdx+1, len(titles)), "title", titleMeta.Number, "name", titleMeta.Name)
 			continue
 		}
 
-		i.logger.Printf("[%d/%d] Fetching versions for Title %d: %s...", titleIdx+1, len(titles), titleMeta.Number, titleMeta.Name)
+		i.logger.InfoContext(ctx, "Fetching title versions", "progress", fmt.Sprintf("[%d/%d]", titleIdx+1, len(titles)), "title", titleMeta.Number, "name", titleMeta.Name)
 
 		// Fetch all versions for this title
-		versions, err := i.client.FetchTitleVersions(ctx, titleMeta.Number)
+		allVersions, err := i.client.FetchTitleVersions(ctx, titleMeta.Number)
 		if err != nil {
-			i.errLogger.Printf("Failed to fetch versions for Title %d: %v", titleMeta.Number, err)
-			stats.Failed++
+			i.logger.ErrorContext(ctx, "Failed to fetch title versions", "title", titleMeta.Number, "error", err)
+			stats.fail(titleFailure(titleMeta.Number, "", fmt.Errorf("failed to fetch versions: %w", err)))
 			continue
 		}
 
-		i.logger.Printf("  Found %d versions for Title %d", len(versions), titleMeta.Number)
+		var versions []string
+		for _, versionDate := range allVersions {
+			if date, err := time.Parse("2006-01-02", versionDate); err != nil || r.includes(date) {
+				versions = append(versions, versionDate)
+			}
+		}
+
+		i.logger.InfoContext(ctx, "Found title versions", "title", titleMeta.Number, "count", len(versions))
 		stats.TitlesProcessed++
 
 		// Import each version
@@ -504,27 +784,27 @@ func (i *Importer) ImportAllHistory(ctx context.Context) (*HistoricalStats, erro
 			// Parse the version date for snapshot
 			snapshotDate, err := time.Parse("2006-01-02", versionDate)
 			if err != nil {
-				i.errLogger.Printf("Invalid date format %s: %v", versionDate, err)
-				stats.Failed++
+				i.logger.ErrorContext(ctx, "Invalid version date", "title", titleMeta.Number, "date", versionDate, "error", err)
+				stats.fail(titleFailure(titleMeta.Number, "", fmt.Errorf("invalid version date %s: %w", versionDate, err)))
 				continue
 			}
 
-			i.logger.Printf("  [%d/%d] Importing version %s...", versionIdx+1, len(versions), versionDate)
+			i.logger.InfoContext(ctx, "Importing title version", "progress", fmt.Sprintf("[%d/%d]", versionIdx+1, len(versions)), "title", titleMeta.Number, "date", versionDate)
 
 			// Fetch XML content for this version
 			content, err := i.client.FetchTitleContent(ctx, versionDate, titleMeta.Number)
 			if err != nil {
-				i.errLogger.Printf("Failed to fetch content for Title %d date %s: %v", titleMeta.Number, versionDate, err)
-				stats.Failed++
+				i.logger.ErrorContext(ctx, "Failed to fetch title version", "title", titleMeta.Number, "date", versionDate, "error", err)
+				stats.fail(titleFailure(titleMeta.Number, versionDate, fmt.Errorf("failed to fetch content: %w", err)))
 				time.Sleep(i.client.Delay())
 				continue
 			}
 
-			// Parse content for metrics
-			parseResult, err := i.parser.Parse(content)
+			// Parse and validate content before anything is saved
+			parseResult, err := i.checkContent(ctx, titleMeta, snapshotDate, content)
 			if err != nil {
-				i.errLogger.Printf("Failed to parse content for Title %d date %s: %v", titleMeta.Number, versionDate, err)
-				stats.Failed++
+				i.logger.ErrorContext(ctx, "Failed to check title version", "title", titleMeta.Number, "date", versionDate, "error", err)
+				stats.fail(titleFailure(titleMeta.Number, versionDate, err))
 				continue
 			}
 
@@ -543,25 +823,32 @@ func (i *Importer) ImportAllHistory(ctx context.Context) (*HistoricalStats, erro
 				TitleName:       titleMeta.Name,
 				WordCount:       parseResult.WordCount,
 				SectionCount:    parseResult.SectionCount,
+				Readability:     readability(parseResult),
 				Checksum:        parseResult.Checksum,
 				LastAmendedDate: lastAmendedDate,
 				FetchedAt:       time.Now(),
 			}
 
-			// Save title and snapshot
+			// Save the snapshot, and the title too if this is its latest version
 			changed, err := i.titleStore.SaveTitleWithSnapshot(ctx, title, snapshotDate)
 			if err != nil {
-				i.errLogger.Printf("Failed to save Title %d date %s: %v", titleMeta.Number, versionDate, err)
-				stats.Failed++
+				i.logger.ErrorContext(ctx, "Failed to save title version", "title", titleMeta.Number, "date", versionDate, "error", err)
+				stats.fail(titleFailure(titleMeta.Number, versionDate, fmt.Errorf("failed to save title: %w", err)))
+				continue
+			}
+
+			if err := i.indexSections(ctx, titleMeta.Number, snapshotDate, parseResult.Sections, changed); err != nil {
+				i.logger.ErrorContext(ctx, "Failed to save title version sections", "title", titleMeta.Number, "date", versionDate, "error", err)
+				stats.fail(titleFailure(titleMeta.Number, versionDate, fmt.Errorf("failed to save sections: %w", err)))
 				continue
 			}
 
 			stats.VersionsProcessed++
 			if changed {
 				stats.SnapshotsCreated++
-				i.logger.Printf("    Snapshot created: %d words, %d sections", parseResult.WordCount, parseResult.SectionCount)
+				i.logger.InfoContext(ctx, "Snapshot created", "title", titleMeta.Number, "date", versionDate, "words", parseResult.WordCount, "sections", parseResult.SectionCount)
 			} else {
-				i.logger.Printf("    Unchanged (duplicate checksum)")
+				i.logger.InfoContext(ctx, "Title version unchanged (duplicate checksum)", "title", titleMeta.Number, "date", versionDate)
 			}
 
 			// Rate limiting
@@ -572,12 +859,33 @@ func (i *Importer) ImportAllHistory(ctx context.Context) (*HistoricalStats, erro
 	return stats, nil
 }
 
-// PrintHistoricalSummary prints historical import statistics
-func (i *Importer) PrintHistoricalSummary(stats *HistoricalStats) {
-	i.logger.Println("")
-	i.logger.Println("=== Historical Import Summary ===")
-	i.logger.Printf("Titles processed:   %d", stats.TitlesProcessed)
-	i.logger.Printf("Versions processed: %d", stats.VersionsProcessed)
-	i.logger.Printf("Snapshots created:  %d", stats.SnapshotsCreated)
-	i.logger.Printf("Failed:             %d", stats.Failed)
+// PrintHistoricalSummary logs historical import statistics
+func (i *Importer) PrintHistoricalSummary(ctx context.Context, stats *HistoricalStats) {
+	i.logger.InfoContext(ctx, "Historical import summary",
+		"titles_processed", stats.TitlesProcessed,
+		"versions_processed", stats.VersionsProcessed,
+		"snapshots_created", stats.SnapshotsCreated,
+		"failed", stats.Failed,
+	)
+}
+
+// titleFailure describes a title, or one version of it when versionDate is
+// set, that failed to import
+func titleFailure(titleNumber int, versionDate string, err error) model.ImportFailure {
+	f := model.ImportFailure{
+		TitleNumber: sql.NullInt64{Int64: int64(titleNumber), Valid: true},
+		Message:     err.Error(),
+	}
+	if date, perr := time.Parse("2006-01-02", versionDate); perr == nil {
+		f.VersionDate = sql.NullTime{Time: date, Valid: true}
+	}
+	return f
+}
+
+// agencyFailure describes an agency that failed to import
+func agencyFailure(slug string, err error) model.ImportFailure {
+	return model.ImportFailure{
+		AgencySlug: sql.NullString{String: slug, Valid: true},
+		Message:    err.Error(),
+	}
 }
@@ -0,0 +1,648 @@
+package service_test
+
+import (
+	"context"
+	"crypto/md5"
+	"database/sql"
+	"encoding/hex"
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/jjenkins/usds/internal/model"
+	"github.com/jjenkins/usds/internal/service"
+	"github.com/jjenkins/usds/internal/service/ecfrtest"
+	"github.com/jjenkins/usds/internal/store"
+)
+
+var (
+	jan = time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	feb = time.Date(2024, 2, 1, 0, 0, 0, 0, time.UTC)
+	mar = time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC)
+	jun = time.Date(2024, 6, 1, 0, 0, 0, 0, time.UTC)
+	jul = time.Date(2024, 7, 1, 0, 0, 0, 0, time.UTC)
+)
+
+// repos are the stores an import writes to, and verify reads
+type repos struct {
+	titles     store.TitleRepository
+	agencies   store.AgencyRepository
+	sections   store.SectionRepository
+	quarantine store.QuarantineRepository
+	integrity  store.IntegrityRepository
+	db         *sql.DB // Nil for the in-memory stores
+}
+
+// forEachBackend runs test against empty in-memory stores, against a new
+// SQLite database and, when TEST_DATABASE_URL is set, against that database,
+// which it wipes
+func forEachBackend(t *testing.T, test func(t *testing.T, r repos)) {
+	t.Run("Memory", func(t *testing.T) {
+		m := store.NewMemoryStore()
+		test(t, repos{titles: m.Titles(), agencies: m.Agencies(), sections: m.Sections(), quarantine: m.Quarantine(),
+			integrity: m.Integrity()})
+	})
+
+	t.Run("Postgres", func(t *testing.T) {
+		dsn := os.Getenv("TEST_DATABASE_URL")
+		if dsn == "" {
+			t.Skip("TEST_DATABASE_URL is not set")
+		}
+
+		db, err := store.NewDB(dsn)
+		if err != nil {
+			t.Fatal(err)
+		}
+		t.Cleanup(func() { db.Close() })
+
+		schema, err := os.ReadFile("../db/schema.sql")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := db.Exec(string(schema)); err != nil {
+			t.Fatalf("failed to apply schema: %v", err)
+		}
+		_, err = db.Exec(`TRUNCATE titles, title_snapshots, sections, agencies, agency_titles, agency_chapters,
+			agency_snapshots, agency_snapshot_titles, change_events, quarantined_versions, import_runs, import_failures
+			RESTART IDENTITY CASCADE`)
+		if err != nil {
+			t.Fatalf("failed to reset tables: %v", err)
+		}
+
+		test(t, dbRepos(db))
+	})
+
+	t.Run("SQLite", func(t *testing.T) {
+		db, err := store.NewDB("sqlite://" + filepath.Join(t.TempDir(), "usds.db"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		t.Cleanup(func() { db.Close() })
+
+		test(t, dbRepos(db))
+	})
+}
+
+// dbRepos are the database stores over db
+func dbRepos(db *sql.DB) repos {
+	return repos{
+		titles:     store.NewTitleStore(db),
+		agencies:   store.NewAgencyStore(db),
+		sections:   store.NewSectionStore(db),
+		quarantine: store.NewQuarantineStore(db),
+		integrity:  store.NewIntegrityStore(db),
+		db:         db,
+	}
+}
+
+func newImporter(srv *ecfrtest.Server, r repos) *service.Importer {
+	return service.NewImporter(service.NewECFRClient(srv.Config()), service.NewParser(), r.titles, r.agencies, r.sections, r.quarantine)
+}
+
+func TestImport(t *testing.T) {
+	forEachBackend(t, func(t *testing.T, r repos) {
+		ctx := context.Background()
+		srv := ecfrtest.NewServer(t)
+		importer := newImporter(srv, r)
+
+		// Title 3 recovers on its last attempt; title 4 never does
+		srv.Fail("full/2024-06-01/title-3.xml", 429, 504)
+		srv.Fail("full/2024-06-01/title-4.xml", 504, 504, 504)
+
+		stats, err := importer.Import(ctx, "2024-06-01")
+		if err != nil {
+			t.Fatal(err)
+		}
+		want := &service.ImportStats{
+			Total: 4, Imported: 2, Changed: 2, Skipped: 1, Failed: 1,
+			Failures: []model.ImportFailure{
+				failure(4, "", "failed to fetch content: failed to fetch title 4 content: failed after 3 attempts: unexpected status code: 504"),
+			},
+		}
+		if !reflect.DeepEqual(stats, want) {
+			t.Errorf("stats = %+v, want %+v", stats, want)
+		}
+		for name, want := range map[string]int{
+			"titles.json":                 1,
+			"full/2024-06-01/title-1.xml": 1,
+			"full/2024-06-01/title-2.xml": 0,
+			"full/2024-06-01/title-3.xml": 3,
+			"full/2024-06-01/title-4.xml": 3,
+		} {
+			if got := srv.Requests(name); got != want {
+				t.Errorf("%s requested %d times, want %d", name, got, want)
+			}
+		}
+
+		// Title 3 is served as of its March version
+		assertTitles(t, r.titles, []titleRow{
+			{1, "General Provisions", 49, 3, checksum(ecfrtest.Fixture(1, "2024-06-01")), "2024-05-20"},
+			{3, "The President", 30, 1, checksum(ecfrtest.Fixture(3, "2024-03-01")), "2024-02-15"},
+		})
+		assertSnapshots(t, r.titles, 1, []snapshotRow{{jun, 49}})
+		assertSnapshots(t, r.titles, 3, []snapshotRow{{jun, 30}})
+		assertSections(t, r.sections, 1, jun, []model.Section{
+			section(1, jun, "§ 1.1", "§ 1.1 Definitions.", "As used in this chapter, Act means the Federal Register Act."),
+			section(1, jun, "§ 1.2", "§ 1.2 Scope.", "This chapter applies to documents published in the Federal Register."),
+			section(1, jun, "§ 1.3", "§ 1.3 Availability.", "Documents are available to the public online."),
+		})
+
+		// Importing the same date again only picks up the title that failed
+		stats, err = importer.Import(ctx, "2024-06-01")
+		if err != nil {
+			t.Fatal(err)
+		}
+		want = &service.ImportStats{Total: 4, Imported: 3, Changed: 1, Unchanged: 2, Skipped: 1}
+		if !reflect.DeepEqual(stats, want) {
+			t.Errorf("second import stats = %+v, want %+v", stats, want)
+		}
+		assertSnapshots(t, r.titles, 1, []snapshotRow{{jun, 49}})
+		assertSnapshots(t, r.titles, 4, []snapshotRow{{jun, 20}})
+	})
+}
+
+func TestImportAgencies(t *testing.T) {
+	forEachBackend(t, func(t *testing.T, r repos) {
+		ctx := context.Background()
+		srv := ecfrtest.NewServer(t)
+		importer := newImporter(srv, r)
+
+		if _, err := importer.Import(ctx, "2024-06-01"); err != nil {
+			t.Fatal(err)
+		}
+
+		srv.Fail("agencies.json", 504)
+		stats, err := importer.ImportAgencies(ctx, jun)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if want := (&service.AgencyStats{Total: 3, Imported: 3}); !reflect.DeepEqual(stats, want) {
+			t.Errorf("stats = %+v, want %+v", stats, want)
+		}
+		if got := srv.Requests("agencies.json"); got != 2 {
+			t.Errorf("agencies.json requested %d times, want 2", got)
+		}
+
+		// The parent's titles are its own and its child's, counted once
+		hierarchy, err := r.agencies.GetAllHierarchical(ctx)
+		if err != nil {
+			t.Fatal(err)
+		}
+		type agencyRow struct {
+			Slug      string
+			ShortName string
+			Depth     int
+			Words     int
+			Titles    int
+		}
+		var got []agencyRow
+		for _, a := range hierarchy {
+			got = append(got, agencyRow{a.Slug, a.ShortName.String, a.Depth, a.TotalWordCount, a.RegulationCount})
+		}
+		wantRows := []agencyRow{
+			{"administrative-committee-of-the-federal-register", "ACFR", 0, 49, 1},
+			{"executive-office-of-the-president", "EOP", 0, 50, 2},
+			{"office-of-management-and-budget", "OMB", 1, 50, 2},
+		}
+		if !reflect.DeepEqual(got, wantRows) {
+			t.Errorf("agencies = %+v, want %+v", got, wantRows)
+		}
+
+		eop, err := r.agencies.GetBySlug(ctx, "executive-office-of-the-president")
+		if err != nil || eop == nil {
+			t.Fatalf("GetBySlug = %v, %v", eop, err)
+		}
+		titles, err := r.agencies.GetAgencyTitles(ctx, eop.ID)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if want := []int{3}; !reflect.DeepEqual(titles, want) {
+			t.Errorf("EOP titles = %v, want %v", titles, want)
+		}
+		chapters, err := r.agencies.GetChaptersForAgency(ctx, eop.ID)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if want := []model.CFRReference{{Title: 3, Chapter: "I"}}; !reflect.DeepEqual(chapters, want) {
+			t.Errorf("EOP chapters = %v, want %v", chapters, want)
+		}
+		snapshots, err := r.agencies.GetSnapshotsForAgency(ctx, eop.ID)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(snapshots) != 1 || !snapshots[0].SnapshotDate.Equal(jun) ||
+			snapshots[0].TotalWordCount != 50 || snapshots[0].RegulationCount != 2 || snapshots[0].Checksum != eop.Checksum {
+			t.Errorf("EOP snapshots = %+v, want one on %s with 50 words in 2 titles", snapshots, jun.Format("2006-01-02"))
+		}
+
+	})
+}
+
+func TestImportAllHistory(t *testing.T) {
+	forEachBackend(t, func(t *testing.T, r repos) {
+		ctx := context.Background()
+		srv := ecfrtest.NewServer(t)
+		importer := newImporter(srv, r)
+
+		srv.Fail("full/2024-01-01/title-1.xml", 429)
+		srv.Fail("versions/title-4.json", 504, 504, 504)
+
+		stats, err := importer.ImportAllHistory(ctx)
+		if err != nil {
+			t.Fatal(err)
+		}
+		want := &service.HistoricalStats{
+			TitlesProcessed: 2, VersionsProcessed: 4, SnapshotsCreated: 4, Failed: 1,
+			Failures: []model.ImportFailure{
+				failure(4, "", "failed to fetch versions: failed to fetch versions for title 4: failed after 3 attempts: unexpected status code: 504"),
+			},
+		}
+		if !reflect.DeepEqual(stats, want) {
+			t.Errorf("stats = %+v, want %+v", stats, want)
+		}
+		if got := srv.Requests("full/2024-01-01/title-1.xml"); got != 2 {
+			t.Errorf("title 1's January version requested %d times, want 2", got)
+		}
+
+		// The current rows hold each title's latest version
+		assertTitles(t, r.titles, []titleRow{
+			{1, "General Provisions", 49, 3, checksum(ecfrtest.Fixture(1, "2024-06-01")), "2024-05-20"},
+			{3, "The President", 30, 1, checksum(ecfrtest.Fixture(3, "2024-03-01")), "2024-02-15"},
+		})
+		assertSnapshots(t, r.titles, 1, []snapshotRow{{jun, 49}, {jan, 39}})
+		assertSnapshots(t, r.titles, 3, []snapshotRow{{mar, 30}, {jan, 26}})
+		assertSections(t, r.sections, 1, jan, []model.Section{
+			section(1, jan, "§ 1.1", "§ 1.1 Definitions.", "As used in this chapter, Act means the Federal Register Act."),
+			section(1, jan, "§ 1.2", "§ 1.2 Scope.", "This chapter applies to documents published in the Federal Register."),
+		})
+
+		// A second pass re-reads every version but only snapshots title 4
+		stats, err = importer.ImportAllHistory(ctx)
+		if err != nil {
+			t.Fatal(err)
+		}
+		want = &service.HistoricalStats{TitlesProcessed: 3, VersionsProcessed: 5, SnapshotsCreated: 1}
+		if !reflect.DeepEqual(stats, want) {
+			t.Errorf("second import stats = %+v, want %+v", stats, want)
+		}
+		assertSnapshots(t, r.titles, 4, []snapshotRow{{feb, 20}})
+	})
+}
+
+func TestImportHistoryKeepsCurrentTitles(t *testing.T) {
+	forEachBackend(t, func(t *testing.T, r repos) {
+		ctx := context.Background()
+		srv := ecfrtest.NewServer(t)
+		importer := newImporter(srv, r)
+
+		if _, err := importer.Import(ctx, "2024-06-01"); err != nil {
+			t.Fatal(err)
+		}
+		current := []titleRow{
+			{1, "General Provisions", 49, 3, checksum(ecfrtest.Fixture(1, "2024-06-01")), "2024-05-20"},
+			{3, "The President", 30, 1, checksum(ecfrtest.Fixture(3, "2024-03-01")), "2024-02-15"},
+			{4, "Accounts", 20, 1, checksum(ecfrtest.Fixture(4, "2024-02-01")), "2024-01-25"},
+		}
+		assertTitles(t, r.titles, current)
+
+		// Backfilling older versions adds their snapshots and sections but
+		// leaves the current titles alone
+		stats, err := importer.ImportHistory(ctx, service.HistoryRange{To: mar})
+		if err != nil {
+			t.Fatal(err)
+		}
+		want := &service.HistoricalStats{TitlesProcessed: 3, VersionsProcessed: 4, SnapshotsCreated: 4}
+		if !reflect.DeepEqual(stats, want) {
+			t.Errorf("stats = %+v, want %+v", stats, want)
+		}
+		assertTitles(t, r.titles, current)
+		assertSnapshots(t, r.titles, 1, []snapshotRow{{jun, 49}, {jan, 39}})
+		assertSnapshots(t, r.titles, 3, []snapshotRow{{jun, 30}, {mar, 30}, {jan, 26}})
+		assertSections(t, r.sections, 1, jan, []model.Section{
+			section(1, jan, "§ 1.1", "§ 1.1 Definitions.", "As used in this chapter, Act means the Federal Register Act."),
+			section(1, jan, "§ 1.2", "§ 1.2 Scope.", "This chapter applies to documents published in the Federal Register."),
+		})
+
+		// So an incremental run still finds every title current
+		incremental, err := importer.ImportIncremental(ctx, "2024-06-01")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if incremental.Unchanged != 3 || incremental.Imported != 0 {
+			t.Errorf("incremental stats = %+v, want 3 unchanged", incremental)
+		}
+		assertTitles(t, r.titles, current)
+	})
+}
+
+func TestImportRecountsSnapshots(t *testing.T) {
+	forEachBackend(t, func(t *testing.T, r repos) {
+		ctx := context.Background()
+		srv := ecfrtest.NewServer(t)
+		importer := newImporter(srv, r)
+
+		if _, err := importer.ImportAllHistory(ctx); err != nil {
+			t.Fatal(err)
+		}
+
+		// Snapshots counted under older rules have the same checksums but
+		// different counts
+		for _, old := range []struct {
+			date  time.Time
+			words int
+		}{{jan, 35}, {jun, 45}} {
+			title, err := r.titles.GetByNumber(ctx, 1)
+			if err != nil {
+				t.Fatal(err)
+			}
+			title.Checksum = checksum(ecfrtest.Fixture(1, old.date.Format("2006-01-02")))
+			title.WordCount = old.words
+			if changed, err := r.titles.SaveTitleWithSnapshot(ctx, title, old.date); err != nil || changed {
+				t.Fatalf("SaveTitleWithSnapshot = %v, %v, want an unchanged snapshot", changed, err)
+			}
+		}
+		assertSnapshots(t, r.titles, 1, []snapshotRow{{jun, 45}, {jan, 35}})
+
+		// Re-importing the same versions recounts them without recording
+		// new snapshots
+		stats, err := importer.ImportAllHistory(ctx)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if stats.SnapshotsCreated != 0 || stats.Failed != 0 {
+			t.Errorf("stats = %+v, want no new snapshots", stats)
+		}
+		assertSnapshots(t, r.titles, 1, []snapshotRow{{jun, 49}, {jan, 39}})
+		assertTitles(t, r.titles, []titleRow{
+			{1, "General Provisions", 49, 3, checksum(ecfrtest.Fixture(1, "2024-06-01")), "2024-05-20"},
+			{3, "The President", 30, 1, checksum(ecfrtest.Fixture(3, "2024-03-01")), "2024-02-15"},
+			{4, "Accounts", 20, 1, checksum(ecfrtest.Fixture(4, "2024-02-01")), "2024-01-25"},
+		})
+	})
+}
+
+// failingSections fails to save sections while fail is set
+type failingSections struct {
+	store.SectionRepository
+	fail bool
+}
+
+func (f *failingSections) ReplaceSections(ctx context.Context, titleNumber int, snapshotDate time.Time, sections []model.Section) error {
+	if f.fail {
+		return errors.New("disk full")
+	}
+	return f.SectionRepository.ReplaceSections(ctx, titleNumber, snapshotDate, sections)
+}
+
+func TestImportReindex(t *testing.T) {
+	forEachBackend(t, func(t *testing.T, r repos) {
+		ctx := context.Background()
+		srv := ecfrtest.NewServer(t)
+		sections := &failingSections{SectionRepository: r.sections, fail: true}
+		r.sections = sections
+		importer := newImporter(srv, r)
+
+		// The snapshots are saved, but none of their sections
+		stats, err := importer.Import(ctx, "2024-06-01")
+		if err != nil {
+			t.Fatal(err)
+		}
+		want := &service.ImportStats{
+			Total: 4, Skipped: 1, Failed: 3,
+			Failures: []model.ImportFailure{
+				failure(1, "", "failed to save sections: disk full"),
+				failure(3, "", "failed to save sections: disk full"),
+				failure(4, "", "failed to save sections: disk full"),
+			},
+		}
+		if !reflect.DeepEqual(stats, want) {
+			t.Errorf("stats = %+v, want %+v", stats, want)
+		}
+		assertSnapshots(t, r.titles, 1, []snapshotRow{{jun, 49}})
+
+		// Reindex refetches each snapshot, but keeps out content that no
+		// longer matches it
+		sections.fail = false
+		srv.Serve("full/2024-06-01/title-4.xml", ecfrtest.Fixture(1, "2024-01-01"))
+		stats, err = importer.Reindex(ctx, 0)
+		if err != nil {
+			t.Fatal(err)
+		}
+		want = &service.ImportStats{
+			Total: 3, Imported: 2, Failed: 1,
+			Failures: []model.ImportFailure{
+				failure(4, "2024-06-01", "content is now "+checksum(ecfrtest.Fixture(1, "2024-01-01"))+
+					", but the snapshot recorded "+checksum(ecfrtest.Fixture(4, "2024-02-01"))),
+			},
+		}
+		if !reflect.DeepEqual(stats, want) {
+			t.Errorf("reindex stats = %+v, want %+v", stats, want)
+		}
+		assertSections(t, r.sections, 1, jun, []model.Section{
+			section(1, jun, "§ 1.1", "§ 1.1 Definitions.", "As used in this chapter, Act means the Federal Register Act."),
+			section(1, jun, "§ 1.2", "§ 1.2 Scope.", "This chapter applies to documents published in the Federal Register."),
+			section(1, jun, "§ 1.3", "§ 1.3 Availability.", "Documents are available to the public online."),
+		})
+
+		// An incremental import re-imports the title still missing its
+		// sections, though it was not amended
+		srv.Serve("full/2024-06-01/title-4.xml", ecfrtest.Fixture(4, "2024-02-01"))
+		stats, err = importer.ImportIncremental(ctx, "2024-06-01")
+		if err != nil {
+			t.Fatal(err)
+		}
+		want = &service.ImportStats{Total: 4, Imported: 1, Unchanged: 3, Skipped: 1}
+		if !reflect.DeepEqual(stats, want) {
+			t.Errorf("incremental stats = %+v, want %+v", stats, want)
+		}
+		if indexed, err := r.sections.HasSections(ctx, 4, jun); err != nil || !indexed {
+			t.Errorf("title 4 HasSections = %v, %v, want true", indexed, err)
+		}
+		assertSnapshots(t, r.titles, 4, []snapshotRow{{jun, 20}})
+
+		stats, err = importer.Reindex(ctx, 0)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if want := (&service.ImportStats{Total: 3, Unchanged: 3}); !reflect.DeepEqual(stats, want) {
+			t.Errorf("second reindex stats = %+v, want %+v", stats, want)
+		}
+	})
+}
+
+func TestImportQuarantine(t *testing.T) {
+	forEachBackend(t, func(t *testing.T, r repos) {
+		ctx := context.Background()
+		srv := ecfrtest.NewServer(t)
+		importer := newImporter(srv, r)
+		importer.SetMaxWordDrop(50)
+
+		if _, err := importer.Import(ctx, "2024-06-01"); err != nil {
+			t.Fatal(err)
+		}
+
+		// A truncated download, another title's document and a gutted title,
+		// all served with HTTP 200
+		gutted := []byte(`<?xml version="1.0" encoding="UTF-8"?>
+<DIV1 N="4" TYPE="TITLE"><HEAD>Title 4—Accounts</HEAD></DIV1>`)
+		srv.Serve("full/2024-07-01/title-1.xml", ecfrtest.Fixture(1, "2024-06-01")[:300])
+		srv.Serve("full/2024-07-01/title-3.xml", ecfrtest.Fixture(4, "2024-02-01"))
+		srv.Serve("full/2024-07-01/title-4.xml", gutted)
+
+		stats, err := importer.Import(ctx, "2024-07-01")
+		if err != nil {
+			t.Fatal(err)
+		}
+		want := &service.ImportStats{
+			Total: 4, Skipped: 1, Failed: 3,
+			Failures: []model.ImportFailure{
+				failure(1, "", "quarantined for review: malformed XML at byte 300: XML syntax error on line 8: unexpected EOF"),
+				failure(3, "", `quarantined for review: content is title "4", not title 3`),
+				failure(4, "", "quarantined for review: word count fell 90.0% from 20 to 2, more than the 50% allowed"),
+			},
+		}
+		if !reflect.DeepEqual(stats, want) {
+			t.Errorf("stats = %+v, want %+v", stats, want)
+		}
+
+		// Nothing was saved from the suspicious versions
+		assertSnapshots(t, r.titles, 1, []snapshotRow{{jun, 49}})
+		assertSnapshots(t, r.titles, 3, []snapshotRow{{jun, 30}})
+		assertSnapshots(t, r.titles, 4, []snapshotRow{{jun, 20}})
+
+		quarantined, err := r.quarantine.List(ctx, model.QuarantinePending)
+		if err != nil {
+			t.Fatal(err)
+		}
+		type quarantineRow struct {
+			Title    int
+			Date     time.Time
+			Checksum string
+			Size     int
+			Words    sql.NullInt64
+			Previous sql.NullInt64
+		}
+		var got []quarantineRow
+		for _, q := range quarantined {
+			got = append(got, quarantineRow{q.TitleNumber, q.VersionDate.UTC(), q.Checksum, q.SizeBytes, q.WordCount, q.PreviousWordCount})
+		}
+		wantRows := []quarantineRow{
+			{4, jul, checksum(gutted), len(gutted), sql.NullInt64{Int64: 2, Valid: true}, sql.NullInt64{Int64: 20, Valid: true}},
+			{3, jul, checksum(ecfrtest.Fixture(4, "2024-02-01")), len(ecfrtest.Fixture(4, "2024-02-01")), sql.NullInt64{Int64: 20, Valid: true}, sql.NullInt64{}},
+			{1, jul, checksum(ecfrtest.Fixture(1, "2024-06-01")[:300]), 300, sql.NullInt64{}, sql.NullInt64{}},
+		}
+		if !reflect.DeepEqual(got, wantRows) {
+			t.Errorf("quarantined = %+v, want %+v", got, wantRows)
+		}
+
+		// Once accepted, the gutted title imports; the others are served
+		// intact this time
+		if err := r.quarantine.Review(ctx, quarantined[0].ID, model.QuarantineAccepted); err != nil {
+			t.Fatal(err)
+		}
+		srv.Serve("full/2024-07-01/title-4.xml", gutted)
+
+		stats, err = importer.Import(ctx, "2024-07-01")
+		if err != nil {
+			t.Fatal(err)
+		}
+		want = &service.ImportStats{Total: 4, Imported: 3, Changed: 3, Skipped: 1}
+		if !reflect.DeepEqual(stats, want) {
+			t.Errorf("second import stats = %+v, want %+v", stats, want)
+		}
+		assertSnapshots(t, r.titles, 4, []snapshotRow{{jul, 2}, {jun, 20}})
+	})
+}
+
+// titleRow is the part of a stored title an import determines
+type titleRow struct {
+	Number      int
+	Name        string
+	Words       int
+	Sections    int
+	Checksum    string
+	LastAmended string
+}
+
+func assertTitles(t *testing.T, titles store.TitleRepository, want []titleRow) {
+	t.Helper()
+	ctx := context.Background()
+	count, err := titles.CountTitles(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if count != len(want) {
+		t.Errorf("got %d titles, want %d", count, len(want))
+	}
+	for _, w := range want {
+		title, err := titles.GetByNumber(ctx, w.Number)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if title == nil {
+			t.Errorf("title %d was not stored", w.Number)
+			continue
+		}
+		got := titleRow{title.TitleNumber, title.TitleName, title.WordCount, title.SectionCount, title.Checksum,
+			title.LastAmendedDate.Time.Format("2006-01-02")}
+		if got != w || !title.Readability.Valid {
+			t.Errorf("title %d = %+v with readability %v, want %+v with a score", w.Number, got, title.Readability, w)
+		}
+	}
+}
+
+// snapshotRow is a stored title snapshot's date and word count
+type snapshotRow struct {
+	Date  time.Time
+	Words int
+}
+
+func assertSnapshots(t *testing.T, titles store.TitleRepository, titleNumber int, want []snapshotRow) {
+	t.Helper()
+	snapshots, err := titles.GetSnapshots(context.Background(), titleNumber)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got []snapshotRow
+	for _, s := range snapshots {
+		got = append(got, snapshotRow{s.SnapshotDate.UTC(), s.WordCount})
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("title %d snapshots = %v, want %v", titleNumber, got, want)
+	}
+}
+
+func assertSections(t *testing.T, sections store.SectionRepository, titleNumber int, date time.Time, want []model.Section) {
+	t.Helper()
+	got, err := sections.GetSections(context.Background(), titleNumber, date)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := range got {
+		got[i].ID = 0
+		got[i].SnapshotDate = got[i].SnapshotDate.UTC()
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("title %d sections on %s = %+v, want %+v", titleNumber, date.Format("2006-01-02"), got, want)
+	}
+}
+
+func section(titleNumber int, date time.Time, identifier, heading, text string) model.Section {
+	return model.Section{TitleNumber: titleNumber, SnapshotDate: date, Identifier: identifier, Heading: heading, Chapter: "I", Text: text}
+}
+
+func failure(titleNumber int, versionDate, message string) model.ImportFailure {
+	f := model.ImportFailure{TitleNumber: sql.NullInt64{Int64: int64(titleNumber), Valid: true}, Message: message}
+	if date, err := time.Parse("2006-01-02", versionDate); err == nil {
+		f.VersionDate = sql.NullTime{Time: date, Valid: true}
+	}
+	return f
+}
+
+func checksum(content []byte) string {
+	hash := md5.Sum(content)
+	return hex.EncodeToString(hash[:])
+}
@@ -138,9 +138,14 @@ func (m *MetricsService) storeMetric(ctx context.Context, name, value string) er
 // GetLatestMetrics retrieves the most recent system metrics
 func (m *MetricsService) GetLatestMetrics(ctx context.Context) (map[string]string, error) {
 	query := `
-		SELECT DISTINCT ON (metric_name) metric_name, metric_value
-		FROM metrics
-		ORDER BY metric_name, calculated_at DESC
+		SELECT m.metric_name, m.metric_value
+		FROM metrics m
+		WHERE m.id = (
+			SELECT latest.id FROM metrics latest
+			WHERE latest.metric_name = m.metric_name
+			ORDER BY latest.calculated_at DESC, latest.id DESC
+			LIMIT 1
+		)
 	`
 
 	rows, err := m.db.QueryContext(ctx, query)
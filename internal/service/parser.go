@@ -5,14 +5,23 @@ import (
 	"crypto/md5"
 	"encoding/hex"
 	"encoding/xml"
+	"fmt"
+	"io"
 	"strings"
+
+	"github.com/jjenkins/usds/internal/model"
 )
 
 // ParseResult contains the metrics extracted from XML content
 type ParseResult struct {
 	WordCount    int
 	SectionCount int
+	Readability  float64 // Flesch reading ease, 0 when there is no text
 	Checksum     string
+	Sections     []model.Section // Section text for full-text search
+	RootElement  string          // Document element, DIV1 for a title
+	RootType     string          // Its TYPE attribute, TITLE for a title
+	RootNumber   string          // Its N attribute, the title number
 }
 
 // Parser handles XML content parsing
@@ -23,7 +32,9 @@ func NewParser() *Parser {
 	return &Parser{}
 }
 
-// Parse extracts metrics from XML content
+// Parse extracts metrics from XML content. Malformed or truncated content is
+// an error giving the byte offset where decoding failed, so a partial
+// download is never recorded as a shorter title.
 func (p *Parser) Parse(content []byte) (*ParseResult, error) {
 	result := &ParseResult{
 		Checksum: p.calculateChecksum(content),
@@ -32,61 +43,125 @@ func (p *Parser) Parse(content []byte) (*ParseResult, error) {
 	decoder := xml.NewDecoder(bytes.NewReader(content))
 
 	var textBuilder strings.Builder
-	var inTextElement bool
+	// Depth within skipped elements; character data anywhere else is text,
+	// however deeply nested
+	var skipDepth int
+
+	// Section text is collected alongside the title-wide text
+	var chapter string
+	var section *model.Section
+	var sectionText, headingText strings.Builder
+	var inHeading bool
+
+	var sawRoot bool
 
 	for {
 		token, err := decoder.Token()
+		if err == io.EOF {
+			break
+		}
 		if err != nil {
-			break // End of document or error
+			return nil, fmt.Errorf("malformed XML at byte %d: %w", decoder.InputOffset(), err)
 		}
 
 		switch t := token.(type) {
 		case xml.StartElement:
+			if !sawRoot {
+				sawRoot = true
+				result.RootElement = t.Name.Local
+				result.RootType = attrValue(t, "TYPE")
+				result.RootNumber = attrValue(t, "N")
+			}
+
 			// Count sections: DIV8 with TYPE="SECTION"
-			if t.Name.Local == "DIV8" {
-				for _, attr := range t.Attr {
-					if attr.Name.Local == "TYPE" && attr.Value == "SECTION" {
-						result.SectionCount++
-						break
-					}
-				}
+			if t.Name.Local == "DIV8" && attrValue(t, "TYPE") == "SECTION" {
+				result.SectionCount++
+				section = &model.Section{Identifier: attrValue(t, "N"), Chapter: chapter}
+				sectionText.Reset()
+				headingText.Reset()
 			}
 
-			// Track when we're inside text-containing elements
-			if isTextElement(t.Name.Local) {
-				inTextElement = true
+			// Remember the enclosing chapter for the sections within it
+			if t.Name.Local == "DIV3" && attrValue(t, "TYPE") == "CHAPTER" {
+				chapter = attrValue(t, "N")
+			}
+
+			if skipDepth > 0 || isSkippedElement(t.Name.Local) {
+				skipDepth++
+			}
+			if section != nil && t.Name.Local == "HEAD" {
+				inHeading = true
 			}
 
 		case xml.EndElement:
-			if isTextElement(t.Name.Local) {
-				inTextElement = false
+			if skipDepth > 0 {
+				skipDepth--
+			}
+			if t.Name.Local == "HEAD" {
+				inHeading = false
+			}
+
+			switch {
+			case t.Name.Local == "DIV8" && section != nil:
+				section.Heading = strings.TrimSpace(headingText.String())
+				section.Text = strings.TrimSpace(sectionText.String())
+				result.Sections = append(result.Sections, *section)
+				section = nil
+			case t.Name.Local == "DIV3":
+				chapter = ""
 			}
 
 		case xml.CharData:
-			if inTextElement {
+			if skipDepth == 0 {
 				text := strings.TrimSpace(string(t))
 				if text != "" {
 					textBuilder.WriteString(text)
 					textBuilder.WriteString(" ")
+
+					if inHeading {
+						headingText.WriteString(text)
+						headingText.WriteString(" ")
+					} else if section != nil {
+						sectionText.WriteString(text)
+						sectionText.WriteString(" ")
+					}
 				}
 			}
 		}
 	}
 
+	if !sawRoot {
+		return nil, fmt.Errorf("malformed XML at byte %d: no root element", decoder.InputOffset())
+	}
+
 	// Count words
 	text := textBuilder.String()
 	if text != "" {
 		words := strings.Fields(text)
 		result.WordCount = len(words)
+		result.Readability = fleschReadingEase(words)
 	}
 
 	return result, nil
 }
 
-// isTextElement returns true if the element typically contains readable text
-func isTextElement(name string) bool {
+// attrValue returns the value of an element's attribute, or "" if absent
+func attrValue(el xml.StartElement, name string) string {
+	for _, attr := range el.Attr {
+		if attr.Name.Local == name {
+			return attr.Value
+		}
+	}
+	return ""
+}
+
+// isSkippedElement reports whether the element holds citations rather than
+// regulatory text: a part's authority and source notes and the Federal
+// Register citations after each section. Everything else, including table
+// cells and text nested in notes and extracts, is counted.
+func isSkippedElement(name string) bool {
 	switch name {
-	case "P", "FP", "HD", "HEAD", "PRTPAGE", "EDNOTE", "NOTE", "EXTRACT", "APPRO":
+	case "AUTH", "SOURCE", "CITA", "SECAUTH":
 		return true
 	default:
 		return false
@@ -0,0 +1,106 @@
+package service_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"math"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/jjenkins/usds/internal/service"
+)
+
+var update = flag.Bool("update", false, "rewrite the parser golden files in testdata/parser")
+
+// parseGolden is what Parse made of a well-formed corpus file
+type parseGolden struct {
+	WordCount    int             `json:"word_count"`
+	SectionCount int             `json:"section_count"`
+	Readability  float64         `json:"readability"`
+	Checksum     string          `json:"checksum"`
+	Root         string          `json:"root"`
+	Sections     []sectionGolden `json:"sections,omitempty"`
+}
+
+// parseError is the golden result of a malformed corpus file
+type parseError struct {
+	Error string `json:"error"`
+}
+
+type sectionGolden struct {
+	Identifier string `json:"identifier"`
+	Chapter    string `json:"chapter"`
+	Heading    string `json:"heading"`
+	Text       string `json:"text"`
+}
+
+// TestParserGolden parses each testdata/parser/*.xml and compares the result
+// with the .golden file beside it. Run with -update after an intended change
+// and review the diff.
+func TestParserGolden(t *testing.T) {
+	files, err := filepath.Glob(filepath.Join("testdata", "parser", "*.xml"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(files) == 0 {
+		t.Fatal("no corpus files in testdata/parser")
+	}
+
+	parser := service.NewParser()
+	for _, file := range files {
+		name := strings.TrimSuffix(filepath.Base(file), ".xml")
+		t.Run(name, func(t *testing.T) {
+			content, err := os.ReadFile(file)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			var result any
+			parsed, err := parser.Parse(content)
+			if err != nil {
+				result = parseError{Error: err.Error()}
+			} else {
+				metrics := parseGolden{
+					WordCount:    parsed.WordCount,
+					SectionCount: parsed.SectionCount,
+					Readability:  math.Round(parsed.Readability*100) / 100,
+					Checksum:     parsed.Checksum,
+					Root:         fmt.Sprintf("%s TYPE=%s N=%s", parsed.RootElement, parsed.RootType, parsed.RootNumber),
+				}
+				for _, sec := range parsed.Sections {
+					metrics.Sections = append(metrics.Sections, sectionGolden{sec.Identifier, sec.Chapter, sec.Heading, sec.Text})
+				}
+				result = metrics
+			}
+
+			var buf bytes.Buffer
+			enc := json.NewEncoder(&buf)
+			enc.SetEscapeHTML(false)
+			enc.SetIndent("", "  ")
+			if err := enc.Encode(result); err != nil {
+				t.Fatal(err)
+			}
+			got := buf.Bytes()
+
+			golden := strings.TrimSuffix(file, ".xml") + ".golden"
+			if *update {
+				if err := os.WriteFile(golden, got, 0o644); err != nil {
+					t.Fatal(err)
+				}
+				return
+			}
+
+			want, err := os.ReadFile(golden)
+			if err != nil {
+				t.Fatalf("%v (run with -update to create it)", err)
+			}
+			if !bytes.Equal(got, want) {
+				t.Errorf("Parse(%s) =\n%s\nwant\n%s", file, got, want)
+			}
+		})
+	}
+}
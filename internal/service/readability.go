@@ -0,0 +1,61 @@
+package service
+
+import (
+	"strings"
+	"unicode"
+)
+
+// fleschReadingEase scores text on the Flesch reading ease scale, where higher
+// is easier to read: 60-70 is plain English, below 30 is very difficult
+func fleschReadingEase(words []string) float64 {
+	if len(words) == 0 {
+		return 0
+	}
+
+	sentences := 0
+	syllables := 0
+	for _, word := range words {
+		if strings.ContainsAny(word[len(word)-1:], ".!?;:") {
+			sentences++
+		}
+		syllables += countSyllables(word)
+	}
+	if sentences == 0 {
+		sentences = 1
+	}
+
+	wordsPerSentence := float64(len(words)) / float64(sentences)
+	syllablesPerWord := float64(syllables) / float64(len(words))
+
+	return 206.835 - 1.015*wordsPerSentence - 84.6*syllablesPerWord
+}
+
+// countSyllables estimates the syllables in a word by counting vowel groups,
+// discounting a silent trailing "e"; every word has at least one
+func countSyllables(word string) int {
+	word = strings.ToLower(strings.TrimFunc(word, func(r rune) bool {
+		return !unicode.IsLetter(r)
+	}))
+	if word == "" {
+		return 0
+	}
+
+	count := 0
+	prevVowel := false
+	for _, r := range word {
+		vowel := strings.ContainsRune("aeiouy", r)
+		if vowel && !prevVowel {
+			count++
+		}
+		prevVowel = vowel
+	}
+
+	if strings.HasSuffix(word, "e") && !strings.HasSuffix(word, "le") && count > 1 {
+		count--
+	}
+	if count == 0 {
+		count = 1
+	}
+
+	return count
+}
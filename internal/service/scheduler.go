@@ -0,0 +1,85 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/jjenkins/usds/internal/model"
+	"github.com/robfig/cron/v3"
+)
+
+// ImportScheduler runs imports on a cron schedule. Each run takes the
+// import lock first, so when several replicas run the scheduler only one of
+// them imports and the others skip that run, as they do while an import
+// started from the CLI or the admin pages is running.
+type ImportScheduler struct {
+	job         *ImportJob
+	schedule    cron.Schedule
+	incremental bool
+}
+
+// NewImportScheduler creates an ImportScheduler for a standard five-field
+// cron expression or a descriptor such as "@daily". Incremental runs skip
+// titles the eCFR lists as not amended since the last import.
+func NewImportScheduler(job *ImportJob, spec string, incremental bool) (*ImportScheduler, error) {
+	schedule, err := cron.ParseStandard(spec)
+	if err != nil {
+		return nil, fmt.Errorf("invalid schedule %q: %w", spec, err)
+	}
+
+	return &ImportScheduler{
+		job:         job,
+		schedule:    schedule,
+		incremental: incremental,
+	}, nil
+}
+
+// Next returns when the next scheduled run after t is due
+func (s *ImportScheduler) Next(t time.Time) time.Time {
+	return s.schedule.Next(t)
+}
+
+// Run imports on schedule until ctx is cancelled. A failed run is logged and
+// retried at the next scheduled time.
+func (s *ImportScheduler) Run(ctx context.Context) {
+	for {
+		next := s.Next(time.Now())
+		slog.InfoContext(ctx, "Next import scheduled", "at", next.Format(time.RFC3339))
+
+		timer := time.NewTimer(time.Until(next))
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return
+		case <-timer.C:
+		}
+
+		if err := s.RunOnce(ctx); err != nil && ctx.Err() == nil {
+			slog.ErrorContext(ctx, "Scheduled import failed", "error", err)
+		}
+	}
+}
+
+// RunOnce imports today's titles and agencies, recalculates system metrics
+// and delivers webhooks, unless another import holds the import lock. The
+// run is recorded in the import history.
+func (s *ImportScheduler) RunOnce(ctx context.Context) error {
+	today, _ := time.Parse("2006-01-02", time.Now().Format("2006-01-02"))
+	req := ImportRequest{Mode: model.ImportModeFull, Date: today}
+	if s.incremental {
+		req.Mode = model.ImportModeIncremental
+	}
+
+	run, err := s.job.Start(ctx, req, model.ImportSourceDaemon, "daemon")
+	if errors.Is(err, ErrImportRunning) {
+		slog.InfoContext(ctx, "Another import is running, skipping this run")
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	return s.job.Run(ctx, run, req, nil)
+}
@@ -0,0 +1,89 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/jjenkins/usds/internal/model"
+)
+
+// ErrQuarantined marks fetched title content that failed validation and was
+// held back for review rather than imported
+var ErrQuarantined = errors.New("quarantined for review")
+
+// checkContent parses a fetched title version and validates it before
+// anything is saved: the XML must be well-formed, its root must be the
+// requested title, and its word count must not have fallen by more than the
+// allowed percentage since the title's latest snapshot, unless a reviewer
+// accepted this exact content. Content that fails is quarantined and
+// returned as an ErrQuarantined error.
+func (i *Importer) checkContent(ctx context.Context, meta model.TitleMeta, snapshotDate time.Time, content []byte) (*ParseResult, error) {
+	q := &model.QuarantinedVersion{
+		TitleNumber: meta.Number,
+		VersionDate: snapshotDate,
+		Checksum:    i.parser.calculateChecksum(content),
+		SizeBytes:   len(content),
+	}
+
+	result, err := i.parser.Parse(content)
+	if err != nil {
+		q.Reason = err.Error()
+		return nil, i.quarantineVersion(ctx, q)
+	}
+	q.WordCount = sql.NullInt64{Int64: int64(result.WordCount), Valid: true}
+
+	if result.RootElement != "DIV1" || result.RootType != "TITLE" {
+		q.Reason = fmt.Sprintf("root element is <%s TYPE=%q>, not a title", result.RootElement, result.RootType)
+		return nil, i.quarantineVersion(ctx, q)
+	}
+	if result.RootNumber != strconv.Itoa(meta.Number) {
+		q.Reason = fmt.Sprintf("content is title %q, not title %d", result.RootNumber, meta.Number)
+		return nil, i.quarantineVersion(ctx, q)
+	}
+
+	if i.maxWordDrop <= 0 {
+		return result, nil
+	}
+
+	previous, err := i.titleStore.AsOf(snapshotDate).GetByNumber(ctx, meta.Number)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get previous snapshot: %w", err)
+	}
+	if previous == nil || previous.WordCount == 0 || result.WordCount >= previous.WordCount {
+		return result, nil
+	}
+
+	drop := float64(previous.WordCount-result.WordCount) * 100 / float64(previous.WordCount)
+	if drop <= float64(i.maxWordDrop) {
+		return result, nil
+	}
+
+	accepted, err := i.quarantine.IsAccepted(ctx, meta.Number, q.Checksum)
+	if err != nil {
+		return nil, err
+	}
+	if accepted {
+		i.logger.InfoContext(ctx, "Importing accepted title version despite word count drop", "title", meta.Number, "drop_percent", fmt.Sprintf("%.1f", drop))
+		return result, nil
+	}
+
+	q.PreviousWordCount = sql.NullInt64{Int64: int64(previous.WordCount), Valid: true}
+	q.Reason = fmt.Sprintf("word count fell %.1f%% from %d to %d, more than the %d%% allowed",
+		drop, previous.WordCount, result.WordCount, i.maxWordDrop)
+	return nil, i.quarantineVersion(ctx, q)
+}
+
+// quarantineVersion records q for review and returns the ErrQuarantined
+// error to report for it
+func (i *Importer) quarantineVersion(ctx context.Context, q *model.QuarantinedVersion) error {
+	if err := i.quarantine.Quarantine(ctx, q); err != nil {
+		return err
+	}
+	i.logger.WarnContext(ctx, "Quarantined title version", "title", q.TitleNumber, "date", q.VersionDate.Format("2006-01-02"),
+		"quarantine_id", q.ID, "reason", q.Reason)
+	return fmt.Errorf("%w: %s", ErrQuarantined, q.Reason)
+}
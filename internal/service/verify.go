@@ -0,0 +1,273 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	"github.com/jjenkins/usds/internal/model"
+	"github.com/jjenkins/usds/internal/store"
+)
+
+// Kinds of problem Verify reports
+const (
+	CheckTitle   = "title"   // A title row disagrees with its latest snapshot or sections
+	CheckContent = "content" // Recomputed content metrics disagree with the snapshot
+	CheckAgency  = "agency"  // An agency's stored roll-up disagrees with its titles
+	CheckOrphan  = "orphan"  // A row points at a missing title, agency or snapshot
+)
+
+// VerifyProblem is one integrity problem Verify found
+type VerifyProblem struct {
+	Check   string
+	Message string
+}
+
+// VerifyReport is the outcome of a Verify run
+type VerifyReport struct {
+	TitlesChecked   int
+	ContentChecked  int // Titles whose checksum was recomputed from content
+	ContentSkipped  int // Titles with no stored content, when not fetching
+	AgenciesChecked int
+	Problems        []VerifyProblem
+}
+
+func (r *VerifyReport) problem(check, format string, args ...any) {
+	r.Problems = append(r.Problems, VerifyProblem{Check: check, Message: fmt.Sprintf(format, args...)})
+}
+
+// Verifier audits the stored titles and agencies against each other and
+// against title content
+type Verifier struct {
+	client      *ECFRClient
+	parser      *Parser
+	titleStore  store.TitleRepository
+	agencyStore store.AgencyRepository
+	integrity   store.IntegrityRepository
+}
+
+// NewVerifier creates a new Verifier. A nil client only recomputes
+// checksums for titles whose content the database stores; otherwise the rest
+// are fetched from eCFR as of their latest snapshot.
+func NewVerifier(client *ECFRClient, parser *Parser, titleStore store.TitleRepository, agencyStore store.AgencyRepository, integrity store.IntegrityRepository) *Verifier {
+	return &Verifier{
+		client:      client,
+		parser:      parser,
+		titleStore:  titleStore,
+		agencyStore: agencyStore,
+		integrity:   integrity,
+	}
+}
+
+// Verify checks each title against its latest snapshot, stored sections and
+// content, recomputes each agency's roll-up as an import does, and looks for
+// rows left pointing at missing titles, agencies or snapshots. It returns an
+// error only when the checks themselves fail; what they find is in the
+// report's Problems.
+func (v *Verifier) Verify(ctx context.Context) (*VerifyReport, error) {
+	report := &VerifyReport{}
+
+	if err := v.verifyTitles(ctx, report); err != nil {
+		return nil, err
+	}
+	if err := v.verifyAgencies(ctx, report); err != nil {
+		return nil, err
+	}
+	if err := v.verifyOrphans(ctx, report); err != nil {
+		return nil, err
+	}
+
+	return report, nil
+}
+
+func (v *Verifier) verifyTitles(ctx context.Context, report *VerifyReport) error {
+	titles, err := v.titleStore.GetAll(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to get titles: %w", err)
+	}
+
+	for _, t := range titles {
+		report.TitlesChecked++
+
+		snapshots, err := v.titleStore.GetSnapshots(ctx, t.TitleNumber)
+		if err != nil {
+			return err
+		}
+		if len(snapshots) == 0 {
+			report.problem(CheckTitle, "title %d has no snapshots", t.TitleNumber)
+			continue
+		}
+		latest := snapshots[0]
+		date := latest.SnapshotDate.Format("2006-01-02")
+
+		if t.Checksum != latest.Checksum || t.WordCount != latest.WordCount || t.SectionCount != latest.SectionCount {
+			report.problem(CheckTitle, "title %d is %s with %d words and %d sections, but its %s snapshot is %s with %d words and %d sections",
+				t.TitleNumber, t.Checksum, t.WordCount, t.SectionCount, date, latest.Checksum, latest.WordCount, latest.SectionCount)
+		}
+
+		// Snapshots imported before section text was kept have none stored
+		sections, err := v.integrity.CountSections(ctx, t.TitleNumber, latest.SnapshotDate)
+		if err != nil {
+			return err
+		}
+		if sections > 0 && sections != latest.SectionCount {
+			report.problem(CheckTitle, "title %d has %d sections stored for its %s snapshot, which counted %d",
+				t.TitleNumber, sections, date, latest.SectionCount)
+		}
+
+		if err := v.verifyContent(ctx, report, latest); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// verifyContent recomputes a snapshot's checksum and counts from its stored
+// content, or from eCFR when the database has none and a client is set
+func (v *Verifier) verifyContent(ctx context.Context, report *VerifyReport, snap model.TitleSnapshot) error {
+	date := snap.SnapshotDate.Format("2006-01-02")
+
+	content, err := v.integrity.SnapshotContent(ctx, snap.TitleNumber, snap.SnapshotDate)
+	if err != nil {
+		return err
+	}
+	if content == nil {
+		if v.client == nil {
+			report.ContentSkipped++
+			return nil
+		}
+		content, err = v.client.FetchTitleContent(ctx, date, snap.TitleNumber)
+		if err != nil {
+			report.problem(CheckContent, "title %d %s: %v", snap.TitleNumber, date, err)
+			return nil
+		}
+	}
+	report.ContentChecked++
+
+	result, err := v.parser.Parse(content)
+	if err != nil {
+		report.problem(CheckContent, "title %d %s: %v", snap.TitleNumber, date, err)
+		return nil
+	}
+	if result.Checksum != snap.Checksum || result.WordCount != snap.WordCount || result.SectionCount != snap.SectionCount {
+		report.problem(CheckContent, "title %d %s content is %s with %d words and %d sections, but its snapshot is %s with %d words and %d sections",
+			snap.TitleNumber, date, result.Checksum, result.WordCount, result.SectionCount, snap.Checksum, snap.WordCount, snap.SectionCount)
+	}
+
+	return nil
+}
+
+// verifyAgencies recomputes each agency's roll-up from the titles linked to
+// it and its descendants, as calculateAgencyWordCount does on import
+func (v *Verifier) verifyAgencies(ctx context.Context, report *VerifyReport) error {
+	agencies, err := v.agencyStore.GetAll(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to get agencies: %w", err)
+	}
+
+	childrenMap := make(map[int][]int)
+	for _, a := range agencies {
+		if a.ParentID.Valid {
+			parentID := int(a.ParentID.Int64)
+			childrenMap[parentID] = append(childrenMap[parentID], a.ID)
+		}
+	}
+
+	titleSets := make(map[int]map[int]bool)
+	wordCounts := make(map[int]int)
+	for _, a := range agencies {
+		report.AgenciesChecked++
+
+		titleSet, err := v.agencyTitleSet(ctx, a.ID, childrenMap, titleSets)
+		if err != nil {
+			return err
+		}
+
+		var titleNums []int
+		for titleNum := range titleSet {
+			titleNums = append(titleNums, titleNum)
+			if _, ok := wordCounts[titleNum]; !ok {
+				wordCount, err := v.agencyStore.GetTitleWordCount(ctx, titleNum)
+				if err != nil {
+					return err
+				}
+				wordCounts[titleNum] = wordCount
+			}
+		}
+		sort.Ints(titleNums)
+		totalWordCount, checksum := rollupChecksum(titleNums, wordCounts)
+
+		if a.TotalWordCount != totalWordCount || a.RegulationCount != len(titleNums) || a.Checksum != checksum {
+			report.problem(CheckAgency, "agency %s has %d words in %d titles (%s), but its titles %v add up to %d words (%s)",
+				a.Slug, a.TotalWordCount, a.RegulationCount, a.Checksum, titleNums, totalWordCount, checksum)
+		}
+
+		snapshots, err := v.agencyStore.GetSnapshotsForAgency(ctx, a.ID)
+		if err != nil {
+			return err
+		}
+		if len(snapshots) > 0 && snapshots[0].Checksum != a.Checksum {
+			report.problem(CheckAgency, "agency %s is %s, but its %s snapshot is %s",
+				a.Slug, a.Checksum, snapshots[0].SnapshotDate.Format("2006-01-02"), snapshots[0].Checksum)
+		}
+	}
+
+	return nil
+}
+
+// agencyTitleSet returns the titles linked to an agency or any descendant,
+// remembering each agency's set in titleSets
+func (v *Verifier) agencyTitleSet(ctx context.Context, agencyID int, childrenMap map[int][]int, titleSets map[int]map[int]bool) (map[int]bool, error) {
+	if titleSet, ok := titleSets[agencyID]; ok {
+		return titleSet, nil
+	}
+
+	titleSet := make(map[int]bool)
+	directTitles, err := v.agencyStore.GetAgencyTitles(ctx, agencyID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get titles for agency %d: %w", agencyID, err)
+	}
+	for _, t := range directTitles {
+		titleSet[t] = true
+	}
+
+	for _, childID := range childrenMap[agencyID] {
+		childTitles, err := v.agencyTitleSet(ctx, childID, childrenMap, titleSets)
+		if err != nil {
+			return nil, err
+		}
+		for t := range childTitles {
+			titleSet[t] = true
+		}
+	}
+
+	titleSets[agencyID] = titleSet
+	return titleSet, nil
+}
+
+func (v *Verifier) verifyOrphans(ctx context.Context, report *VerifyReport) error {
+	links, err := v.integrity.OrphanAgencyTitles(ctx)
+	if err != nil {
+		return err
+	}
+	for _, link := range links {
+		report.problem(CheckOrphan, "agency_titles links agency %d to title %d, but one of them does not exist",
+			link.AgencyID, link.TitleNumber)
+	}
+
+	orphans, err := v.integrity.OrphanSnapshots(ctx)
+	if err != nil {
+		return err
+	}
+	for _, o := range orphans {
+		parent := fmt.Sprintf("title %d", o.TitleNumber)
+		if o.Table == "sections" {
+			parent = fmt.Sprintf("title %d snapshot", o.TitleNumber)
+		}
+		report.problem(CheckOrphan, "%s has %d rows for %s on %s, which does not exist",
+			o.Table, o.Rows, parent, o.SnapshotDate.Format("2006-01-02"))
+	}
+
+	return nil
+}
@@ -0,0 +1,109 @@
+package service_test
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/jjenkins/usds/internal/model"
+	"github.com/jjenkins/usds/internal/service"
+	"github.com/jjenkins/usds/internal/service/ecfrtest"
+	"github.com/jjenkins/usds/internal/store"
+)
+
+func TestVerify(t *testing.T) {
+	forEachBackend(t, func(t *testing.T, r repos) {
+		ctx := context.Background()
+		srv := ecfrtest.NewServer(t)
+		importer := newImporter(srv, r)
+
+		if _, err := importer.Import(ctx, "2024-06-01"); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := importer.ImportAgencies(ctx, jun); err != nil {
+			t.Fatal(err)
+		}
+
+		// A fresh import is consistent, with or without fetching content
+		offline := service.NewVerifier(nil, service.NewParser(), r.titles, r.agencies, r.integrity)
+		report, err := offline.Verify(ctx)
+		if err != nil {
+			t.Fatal(err)
+		}
+		want := &service.VerifyReport{TitlesChecked: 3, ContentSkipped: 3, AgenciesChecked: 3}
+		if !reflect.DeepEqual(report, want) {
+			t.Errorf("offline report = %+v, want %+v", report, want)
+		}
+
+		fetching := service.NewVerifier(service.NewECFRClient(srv.Config()), service.NewParser(), r.titles, r.agencies, r.integrity)
+		report, err = fetching.Verify(ctx)
+		if err != nil {
+			t.Fatal(err)
+		}
+		want = &service.VerifyReport{TitlesChecked: 3, ContentChecked: 3, AgenciesChecked: 3}
+		if !reflect.DeepEqual(report, want) {
+			t.Errorf("fetching report = %+v, want %+v", report, want)
+		}
+
+		// eCFR now serves title 1's January text for June, title 4's row is
+		// overwritten behind the importer's back, and OMB's roll-up is
+		// tampered with. Only the databases can be written to directly.
+		srv.Serve("full/2024-06-01/title-1.xml", ecfrtest.Fixture(1, "2024-01-01"))
+		title4Words := 20
+		if r.db != nil {
+			stale := &model.Title{TitleNumber: 4, TitleName: "Accounts", WordCount: 15, SectionCount: 1, Checksum: "stale", FetchedAt: time.Now()}
+			if err := store.NewTitleStore(r.db).UpsertTitle(ctx, stale); err != nil {
+				t.Fatal(err)
+			}
+			title4Words = 15
+		}
+		omb, err := r.agencies.GetBySlug(ctx, "office-of-management-and-budget")
+		if err != nil || omb == nil {
+			t.Fatalf("GetBySlug = %v, %v", omb, err)
+		}
+		if err := r.agencies.UpdateWordCount(ctx, omb.ID, 1, 1, "tampered"); err != nil {
+			t.Fatal(err)
+		}
+
+		report, err = fetching.Verify(ctx)
+		if err != nil {
+			t.Fatal(err)
+		}
+		jan1 := checksum(ecfrtest.Fixture(1, "2024-01-01"))
+		jun1 := checksum(ecfrtest.Fixture(1, "2024-06-01"))
+		jun4 := checksum(ecfrtest.Fixture(4, "2024-02-01"))
+		eop := rollup(map[int]int{3: 30, 4: 20})
+		titles := rollup(map[int]int{3: 30, 4: title4Words})
+		problems := []service.VerifyProblem{
+			{service.CheckContent, "title 1 2024-06-01 content is " + jan1 + " with 39 words and 2 sections, but its snapshot is " + jun1 + " with 49 words and 3 sections"},
+		}
+		if r.db != nil {
+			problems = append(problems,
+				service.VerifyProblem{service.CheckTitle, "title 4 is stale with 15 words and 1 sections, but its 2024-06-01 snapshot is " + jun4 + " with 20 words and 1 sections"},
+				service.VerifyProblem{service.CheckAgency, "agency executive-office-of-the-president has 50 words in 2 titles (" + eop + "), but its titles [3 4] add up to 45 words (" + titles + ")"},
+			)
+		}
+		problems = append(problems,
+			service.VerifyProblem{service.CheckAgency, fmt.Sprintf("agency office-of-management-and-budget has 1 words in 1 titles (tampered), but its titles [3 4] add up to %d words (%s)", 30+title4Words, titles)},
+			service.VerifyProblem{service.CheckAgency, "agency office-of-management-and-budget is tampered, but its 2024-06-01 snapshot is " + eop},
+		)
+		want = &service.VerifyReport{TitlesChecked: 3, ContentChecked: 3, AgenciesChecked: 3, Problems: problems}
+		if !reflect.DeepEqual(report, want) {
+			t.Errorf("report = %+v, want %+v", report, want)
+		}
+	})
+}
+
+// rollup is the checksum an import gives an agency with these title word
+// counts
+func rollup(wordCounts map[int]int) string {
+	var input string
+	for n := 1; n <= 50; n++ {
+		if words, ok := wordCounts[n]; ok {
+			input += fmt.Sprintf("%d:%d;", n, words)
+		}
+	}
+	return checksum([]byte(input))
+}
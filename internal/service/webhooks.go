@@ -0,0 +1,291 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/jjenkins/usds/internal/model"
+	"github.com/jjenkins/usds/internal/store"
+)
+
+const (
+	webhookTimeout   = 10 * time.Second
+	webhookBatchSize = 20
+	webhookLease     = 2 * time.Minute // Longer than a delivery attempt can take
+
+	// SignatureHeader carries "t=<unix seconds>,v1=<hex HMAC-SHA256>" where the
+	// HMAC is computed over "<unix seconds>.<request body>"
+	SignatureHeader = "X-USDS-Signature"
+)
+
+// webhookBackoff is the wait before each retry. A delivery that still fails
+// after the last retry is dead-lettered.
+var webhookBackoff = []time.Duration{
+	1 * time.Minute,
+	5 * time.Minute,
+	30 * time.Minute,
+	2 * time.Hour,
+	12 * time.Hour,
+}
+
+// WebhookStats tracks the outcome of a delivery pass
+type WebhookStats struct {
+	Delivered int
+	Retrying  int
+	Dead      int
+}
+
+// WebhookDispatcher delivers queued change events to webhook subscribers
+type WebhookDispatcher struct {
+	client       *http.Client
+	webhookStore *store.WebhookStore
+	baseURL      string // Public URL of the web UI, for links in messages
+}
+
+// NewWebhookDispatcher creates a new WebhookDispatcher. baseURL may be empty,
+// in which case messages carry no links.
+func NewWebhookDispatcher(webhookStore *store.WebhookStore, baseURL string) *WebhookDispatcher {
+	return &WebhookDispatcher{
+		client: &http.Client{
+			Timeout: webhookTimeout,
+		},
+		webhookStore: webhookStore,
+		baseURL:      strings.TrimSuffix(baseURL, "/"),
+	}
+}
+
+// Run delivers due webhooks every interval until ctx is cancelled
+func (d *WebhookDispatcher) Run(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		if _, err := d.DeliverDue(ctx); err != nil && ctx.Err() == nil {
+			slog.ErrorContext(ctx, "Webhook delivery pass failed", "error", err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// DeliverDue attempts every pending delivery whose next attempt is due
+func (d *WebhookDispatcher) DeliverDue(ctx context.Context) (*WebhookStats, error) {
+	stats := &WebhookStats{}
+
+	for {
+		deliveries, err := d.webhookStore.ClaimDueDeliveries(ctx, webhookBatchSize, webhookLease)
+		if err != nil {
+			return stats, err
+		}
+		if len(deliveries) == 0 {
+			return stats, nil
+		}
+
+		for _, delivery := range deliveries {
+			if ctx.Err() != nil {
+				return stats, ctx.Err()
+			}
+			if err := d.deliver(ctx, delivery, stats); err != nil {
+				return stats, err
+			}
+		}
+	}
+}
+
+// deliver makes one delivery attempt and records its outcome
+func (d *WebhookDispatcher) deliver(ctx context.Context, delivery model.WebhookDelivery, stats *WebhookStats) error {
+	statusCode, err := d.post(ctx, delivery)
+	if err == nil {
+		stats.Delivered++
+		slog.InfoContext(ctx, "Webhook delivered", "delivery", delivery.ID, "subscription", delivery.SubscriptionName, "status", statusCode)
+		return d.webhookStore.MarkDelivered(ctx, delivery.ID, statusCode)
+	}
+
+	var retryIn time.Duration
+	if delivery.Attempts < len(webhookBackoff) {
+		retryIn = webhookBackoff[delivery.Attempts]
+		stats.Retrying++
+		slog.WarnContext(ctx, "Webhook delivery failed, retrying",
+			"delivery", delivery.ID,
+			"subscription", delivery.SubscriptionName,
+			"attempt", delivery.Attempts+1,
+			"retry_in", retryIn.String(),
+			"error", err,
+		)
+	} else {
+		stats.Dead++
+		slog.ErrorContext(ctx, "Webhook delivery failed, giving up",
+			"delivery", delivery.ID,
+			"subscription", delivery.SubscriptionName,
+			"attempt", delivery.Attempts+1,
+			"error", err,
+		)
+	}
+
+	return d.webhookStore.MarkFailed(ctx, delivery.ID, statusCode, err.Error(), retryIn)
+}
+
+// post sends a signed delivery, returning the response status code (zero if
+// there was no response) and an error unless the receiver returned 2xx
+func (d *WebhookDispatcher) post(ctx context.Context, delivery model.WebhookDelivery) (int, error) {
+	body, err := json.Marshal(d.payload(delivery))
+	if err != nil {
+		return 0, fmt.Errorf("failed to encode payload: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, delivery.URL, bytes.NewReader(body))
+	if err != nil {
+		return 0, fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "eCFR-Analyzer-Webhooks/1.0")
+	req.Header.Set("X-USDS-Event", delivery.Event.EntityType+".changed")
+	req.Header.Set("X-USDS-Delivery", strconv.Itoa(delivery.ID))
+	req.Header.Set(SignatureHeader, SignWebhook(delivery.Secret, time.Now(), body))
+
+	resp, err := d.client.Do(req)
+	if err != nil {
+		return 0, fmt.Errorf("request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		snippet, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return resp.StatusCode, fmt.Errorf("receiver returned status %d: %s", resp.StatusCode, strings.TrimSpace(string(snippet)))
+	}
+	io.Copy(io.Discard, resp.Body)
+
+	return resp.StatusCode, nil
+}
+
+// WebhookPayload is the JSON body of a delivery. Text is a ready-made
+// message, so Slack and Teams incoming webhooks can post it unchanged.
+type WebhookPayload struct {
+	Text       string        `json:"text"`
+	Event      string        `json:"event"` // "title.changed" or "agency.changed"
+	DeliveryID int           `json:"delivery_id"`
+	Change     WebhookChange `json:"change"`
+}
+
+// WebhookChange describes the change event being delivered
+type WebhookChange struct {
+	ID                   int     `json:"id"`
+	EntityType           string  `json:"entity_type"`
+	TitleNumber          *int    `json:"title_number,omitempty"`
+	AgencySlug           string  `json:"agency_slug,omitempty"`
+	Name                 string  `json:"name"`
+	SnapshotDate         string  `json:"snapshot_date"`
+	PreviousSnapshotDate *string `json:"previous_snapshot_date"` // Null for a first snapshot
+	PreviousWordCount    *int    `json:"previous_word_count"`
+	WordCount            int     `json:"word_count"`
+	WordCountChange      int     `json:"word_count_change"`
+	Checksum             string  `json:"checksum"`
+	URL                  string  `json:"url,omitempty"`
+}
+
+func (d *WebhookDispatcher) payload(delivery model.WebhookDelivery) WebhookPayload {
+	e := delivery.Event
+	change := WebhookChange{
+		ID:              e.ID,
+		EntityType:      e.EntityType,
+		AgencySlug:      e.AgencySlug.String,
+		Name:            e.EntityName,
+		SnapshotDate:    e.SnapshotDate.Format("2006-01-02"),
+		WordCount:       e.WordCount,
+		WordCountChange: e.WordCountChange(),
+		Checksum:        e.Checksum,
+	}
+	if e.TitleNumber.Valid {
+		number := int(e.TitleNumber.Int64)
+		change.TitleNumber = &number
+	}
+	if !e.First() {
+		date, words := e.PreviousSnapshotDate.Time.Format("2006-01-02"), int(e.PreviousWordCount.Int64)
+		change.PreviousSnapshotDate, change.PreviousWordCount = &date, &words
+	}
+	if d.baseURL != "" {
+		if e.EntityType == model.ChangeEntityAgency {
+			change.URL = fmt.Sprintf("%s/agencies/%s", d.baseURL, e.AgencySlug.String)
+		} else {
+			change.URL = fmt.Sprintf("%s/titles/%d", d.baseURL, e.TitleNumber.Int64)
+		}
+	}
+
+	text := fmt.Sprintf("%s first recorded on %s (%d words)", e.EntityName, change.SnapshotDate, change.WordCount)
+	if !e.First() {
+		text = fmt.Sprintf("%s changed on %s (%+d words since %s)",
+			e.EntityName, change.SnapshotDate, change.WordCountChange, *change.PreviousSnapshotDate)
+	}
+	if change.URL != "" {
+		text += " " + change.URL
+	}
+
+	return WebhookPayload{
+		Text:       text,
+		Event:      e.EntityType + ".changed",
+		DeliveryID: delivery.ID,
+		Change:     change,
+	}
+}
+
+// SignWebhook returns the SignatureHeader value for a body sent at timestamp
+func SignWebhook(secret string, timestamp time.Time, body []byte) string {
+	ts := strconv.FormatInt(timestamp.Unix(), 10)
+	return "t=" + ts + ",v1=" + webhookMAC(secret, ts, body)
+}
+
+// VerifyWebhookSignature checks a SignatureHeader value against the body,
+// rejecting signatures older than tolerance to prevent replays. Receivers
+// written in Go can use it directly.
+func VerifyWebhookSignature(secret, header string, body []byte, tolerance time.Duration) error {
+	var ts, sig string
+	for _, part := range strings.Split(header, ",") {
+		key, value, _ := strings.Cut(strings.TrimSpace(part), "=")
+		switch key {
+		case "t":
+			ts = value
+		case "v1":
+			sig = value
+		}
+	}
+	if ts == "" || sig == "" {
+		return errors.New("malformed signature header")
+	}
+
+	unix, err := strconv.ParseInt(ts, 10, 64)
+	if err != nil {
+		return fmt.Errorf("invalid signature timestamp: %w", err)
+	}
+	if age := time.Since(time.Unix(unix, 0)); age > tolerance || age < -tolerance {
+		return fmt.Errorf("signature timestamp outside tolerance (%s old)", age.Round(time.Second))
+	}
+
+	if !hmac.Equal([]byte(sig), []byte(webhookMAC(secret, ts, body))) {
+		return errors.New("signature mismatch")
+	}
+	return nil
+}
+
+func webhookMAC(secret, ts string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(ts))
+	mac.Write([]byte("."))
+	mac.Write(body)
+	return hex.EncodeToString(mac.Sum(nil))
+}
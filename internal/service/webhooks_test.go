@@ -0,0 +1,219 @@
+package service_test
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/jjenkins/usds/internal/model"
+	"github.com/jjenkins/usds/internal/service"
+	"github.com/jjenkins/usds/internal/store"
+)
+
+// webhookReceiver is a subscriber endpoint that verifies each delivery's
+// signature and answers with the status it is set to
+type webhookReceiver struct {
+	*httptest.Server
+	t      *testing.T
+	secret string
+
+	mu       sync.Mutex
+	status   int
+	payloads []service.WebhookPayload
+}
+
+func newWebhookReceiver(t *testing.T, secret string) *webhookReceiver {
+	r := &webhookReceiver{t: t, secret: secret, status: http.StatusOK}
+	r.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		body, err := io.ReadAll(req.Body)
+		if err != nil {
+			t.Error(err)
+		}
+		if err := service.VerifyWebhookSignature(secret, req.Header.Get(service.SignatureHeader), body, time.Minute); err != nil {
+			t.Errorf("delivery signature: %v", err)
+		}
+		if err := service.VerifyWebhookSignature("wrong", req.Header.Get(service.SignatureHeader), body, time.Minute); err == nil {
+			t.Error("delivery signature verified with the wrong secret")
+		}
+
+		var payload service.WebhookPayload
+		if err := json.Unmarshal(body, &payload); err != nil {
+			t.Errorf("delivery payload: %v", err)
+		}
+
+		r.mu.Lock()
+		defer r.mu.Unlock()
+		r.payloads = append(r.payloads, payload)
+		w.WriteHeader(r.status)
+	}))
+	t.Cleanup(r.Close)
+	return r
+}
+
+func (r *webhookReceiver) respond(status int) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.status = status
+}
+
+func (r *webhookReceiver) received() []service.WebhookPayload {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([]service.WebhookPayload(nil), r.payloads...)
+}
+
+func TestSignWebhook(t *testing.T) {
+	body := []byte(`{"event":"title.changed"}`)
+	header := service.SignWebhook("secret", time.Now(), body)
+
+	if err := service.VerifyWebhookSignature("secret", header, body, time.Minute); err != nil {
+		t.Errorf("VerifyWebhookSignature = %v", err)
+	}
+	for name, err := range map[string]error{
+		"wrong secret":  service.VerifyWebhookSignature("other", header, body, time.Minute),
+		"altered body":  service.VerifyWebhookSignature("secret", header, []byte(`{"event":"agency.changed"}`), time.Minute),
+		"malformed":     service.VerifyWebhookSignature("secret", "v1=abc", body, time.Minute),
+		"replayed":      service.VerifyWebhookSignature("secret", service.SignWebhook("secret", time.Now().Add(-time.Hour), body), body, time.Minute),
+		"from a future": service.VerifyWebhookSignature("secret", service.SignWebhook("secret", time.Now().Add(time.Hour), body), body, time.Minute),
+	} {
+		if err == nil {
+			t.Errorf("%s: VerifyWebhookSignature = nil, want an error", name)
+		}
+	}
+}
+
+func TestWebhookDelivery(t *testing.T) {
+	forEachBackend(t, func(t *testing.T, r repos) {
+		if r.db == nil {
+			t.Skip("webhook deliveries need a database")
+		}
+
+		ctx := context.Background()
+		webhooks := store.NewWebhookStore(r.db)
+		dispatcher := service.NewWebhookDispatcher(webhooks, "https://ecfr.example.com/")
+		receiver := newWebhookReceiver(t, "s3cret")
+
+		// Title 1 changes between January and June with a subscriber listening
+		title := &model.Title{TitleNumber: 1, TitleName: "General Provisions", WordCount: 39, SectionCount: 2, Checksum: "jan"}
+		if _, err := r.titles.SaveTitleWithSnapshot(ctx, title, jan); err != nil {
+			t.Fatal(err)
+		}
+		sub := &model.WebhookSubscription{Name: "receiver", URL: receiver.URL, Secret: "s3cret", Active: true}
+		if err := webhooks.CreateSubscription(ctx, sub); err != nil {
+			t.Fatal(err)
+		}
+		title.WordCount, title.Checksum = 49, "jun"
+		if _, err := r.titles.SaveTitleWithSnapshot(ctx, title, jun); err != nil {
+			t.Fatal(err)
+		}
+
+		delivery := func() model.WebhookDelivery {
+			t.Helper()
+			deliveries, err := webhooks.ListDeliveries(ctx, store.DeliveryFilter{Limit: 10})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(deliveries) != 1 {
+				t.Fatalf("%d deliveries queued, want 1", len(deliveries))
+			}
+			return deliveries[0]
+		}
+		// makeDue brings the delivery's next attempt forward to now
+		makeDue := func() {
+			t.Helper()
+			if _, err := r.db.ExecContext(ctx, `UPDATE webhook_deliveries SET next_attempt_at = CURRENT_TIMESTAMP`); err != nil {
+				t.Fatal(err)
+			}
+		}
+
+		// Each 5xx schedules the next step of the backoff
+		receiver.respond(http.StatusServiceUnavailable)
+		steps := []time.Duration{time.Minute, 5 * time.Minute, 30 * time.Minute, 2 * time.Hour, 12 * time.Hour}
+		for attempt, step := range steps {
+			before := time.Now()
+			stats, err := dispatcher.DeliverDue(ctx)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if want := (service.WebhookStats{Retrying: 1}); *stats != want {
+				t.Fatalf("attempt %d stats = %+v, want %+v", attempt+1, *stats, want)
+			}
+
+			d := delivery()
+			if d.Status != model.DeliveryPending || d.Attempts != attempt+1 || d.LastStatusCode.Int64 != http.StatusServiceUnavailable {
+				t.Errorf("after attempt %d delivery = %+v, want pending with %d attempts", attempt+1, d, attempt+1)
+			}
+			if d.NextAttemptAt.Before(before.Add(step).Add(-time.Second)) || d.NextAttemptAt.After(time.Now().Add(step).Add(time.Second)) {
+				t.Errorf("after attempt %d next attempt at %s, want %s from now", attempt+1, d.NextAttemptAt, step)
+			}
+
+			// Nothing is due until the backoff passes
+			if stats, err := dispatcher.DeliverDue(ctx); err != nil || *stats != (service.WebhookStats{}) {
+				t.Errorf("DeliverDue during backoff = %+v, %v, want nothing attempted", stats, err)
+			}
+			makeDue()
+		}
+
+		// Failing once the schedule is exhausted dead-letters the delivery
+		stats, err := dispatcher.DeliverDue(ctx)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if want := (service.WebhookStats{Dead: 1}); *stats != want {
+			t.Errorf("last attempt stats = %+v, want %+v", *stats, want)
+		}
+		if d := delivery(); d.Status != model.DeliveryDead || d.Attempts != len(steps)+1 {
+			t.Errorf("delivery = %+v, want dead after %d attempts", d, len(steps)+1)
+		}
+		makeDue()
+		if stats, err := dispatcher.DeliverDue(ctx); err != nil || *stats != (service.WebhookStats{}) {
+			t.Errorf("DeliverDue after dead-lettering = %+v, %v, want nothing attempted", stats, err)
+		}
+
+		// A retried delivery goes out again, and succeeds
+		receiver.respond(http.StatusNoContent)
+		if err := webhooks.RetryDelivery(ctx, delivery().ID); err != nil {
+			t.Fatal(err)
+		}
+		stats, err = dispatcher.DeliverDue(ctx)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if want := (service.WebhookStats{Delivered: 1}); *stats != want {
+			t.Errorf("retried stats = %+v, want %+v", *stats, want)
+		}
+		if d := delivery(); d.Status != model.DeliveryDelivered || !d.DeliveredAt.Valid {
+			t.Errorf("delivery = %+v, want delivered", d)
+		}
+
+		payloads := receiver.received()
+		if len(payloads) != len(steps)+2 {
+			t.Fatalf("receiver got %d deliveries, want %d", len(payloads), len(steps)+2)
+		}
+		got := payloads[len(payloads)-1]
+		if got.Event != "title.changed" || got.Change.WordCountChange != 10 || got.Change.PreviousSnapshotDate == nil ||
+			*got.Change.PreviousSnapshotDate != "2024-01-01" || got.Change.URL != "https://ecfr.example.com/titles/1" {
+			t.Errorf("payload = %+v", got)
+		}
+
+		// A title's first snapshot is delivered with no previous snapshot
+		second := &model.Title{TitleNumber: 2, TitleName: "Grants and Agreements", WordCount: 120, SectionCount: 3, Checksum: "first"}
+		if _, err := r.titles.SaveTitleWithSnapshot(ctx, second, jun); err != nil {
+			t.Fatal(err)
+		}
+		if stats, err := dispatcher.DeliverDue(ctx); err != nil || *stats != (service.WebhookStats{Delivered: 1}) {
+			t.Fatalf("DeliverDue for a first snapshot = %+v, %v, want 1 delivered", stats, err)
+		}
+		payloads = receiver.received()
+		got = payloads[len(payloads)-1]
+		if got.Change.PreviousSnapshotDate != nil || got.Change.PreviousWordCount != nil || got.Change.WordCountChange != 120 ||
+			got.Change.Name != "Title 2: Grants and Agreements" {
+			t.Errorf("first snapshot payload = %+v", got)
+		}
+	})
+}
@@ -7,12 +7,15 @@ import (
 	"sort"
 	"time"
 
+	"github.com/jjenkins/usds/internal/cache"
 	"github.com/jjenkins/usds/internal/model"
 )
 
 // AgencyStore handles database operations for agencies
 type AgencyStore struct {
-	db *sql.DB
+	db    *sql.DB
+	asOf  time.Time    // Zero for the current state
+	cache *cache.Cache // Nil to always read the database
 }
 
 // NewAgencyStore creates a new AgencyStore
@@ -20,14 +23,30 @@ func NewAgencyStore(db *sql.DB) *AgencyStore {
 	return &AgencyStore{db: db}
 }
 
+// WithCache returns an AgencyStore whose aggregate reads are kept in c until the
+// data version changes
+func (s *AgencyStore) WithCache(c *cache.Cache) *AgencyStore {
+	return &AgencyStore{db: s.db, asOf: s.asOf, cache: c}
+}
+
+// AsOf returns an AgencyStore whose reads reconstruct agencies, their title
+// links and titles from the latest snapshots on or before the given date. A
+// zero date reads the current state. Writes are unaffected.
+func (s *AgencyStore) AsOf(date time.Time) AgencyRepository {
+	if date.IsZero() {
+		return s
+	}
+	return &AgencyStore{db: s.db, asOf: date, cache: s.cache}
+}
+
 // GetBySlug retrieves an agency by its slug
 func (s *AgencyStore) GetBySlug(ctx context.Context, slug string) (*model.Agency, error) {
-	query := `
+	query := fmt.Sprintf(`
 		SELECT id, agency_name, short_name, slug, parent_id, total_word_count,
 		       regulation_count, checksum, updated_at
-		FROM agencies
+		FROM %s AS agencies
 		WHERE slug = $1
-	`
+	`, agenciesSource(s.asOf))
 
 	var a model.Agency
 	err := s.db.QueryRowContext(ctx, query, slug).Scan(
@@ -53,12 +72,12 @@ func (s *AgencyStore) GetBySlug(ctx context.Context, slug string) (*model.Agency
 
 // GetAll retrieves all agencies
 func (s *AgencyStore) GetAll(ctx context.Context) ([]model.Agency, error) {
-	query := `
+	query := fmt.Sprintf(`
 		SELECT id, agency_name, short_name, slug, parent_id, total_word_count,
 		       regulation_count, checksum, updated_at
-		FROM agencies
+		FROM %s AS agencies
 		ORDER BY agency_name
-	`
+	`, agenciesSource(s.asOf))
 
 	rows, err := s.db.QueryContext(ctx, query)
 	if err != nil {
@@ -140,9 +159,53 @@ func (s *AgencyStore) LinkAgencyTitle(ctx context.Context, agencyID, titleNumber
 	return nil
 }
 
+// LinkAgencyChapter records that an agency owns a chapter within a title
+func (s *AgencyStore) LinkAgencyChapter(ctx context.Context, agencyID, titleNumber int, chapter string) error {
+	query := `
+		INSERT INTO agency_chapters (agency_id, title_number, chapter)
+		VALUES ($1, $2, $3)
+		ON CONFLICT (agency_id, title_number, chapter) DO NOTHING
+	`
+
+	_, err := s.db.ExecContext(ctx, query, agencyID, titleNumber, chapter)
+	if err != nil {
+		return fmt.Errorf("failed to link agency %d to title %d chapter %s: %w", agencyID, titleNumber, chapter, err)
+	}
+
+	return nil
+}
+
+// GetChaptersForAgency retrieves the title chapters an agency owns. Chapters
+// are not snapshotted, so these always reflect the latest import.
+func (s *AgencyStore) GetChaptersForAgency(ctx context.Context, agencyID int) ([]model.CFRReference, error) {
+	query := `
+		SELECT title_number, chapter
+		FROM agency_chapters
+		WHERE agency_id = $1
+		ORDER BY title_number, chapter
+	`
+
+	rows, err := s.db.QueryContext(ctx, query, agencyID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get chapters for agency %d: %w", agencyID, err)
+	}
+	defer rows.Close()
+
+	var chapters []model.CFRReference
+	for rows.Next() {
+		var ref model.CFRReference
+		if err := rows.Scan(&ref.Title, &ref.Chapter); err != nil {
+			return nil, fmt.Errorf("failed to scan chapter: %w", err)
+		}
+		chapters = append(chapters, ref)
+	}
+
+	return chapters, rows.Err()
+}
+
 // GetAgencyTitles retrieves all title numbers linked to an agency
 func (s *AgencyStore) GetAgencyTitles(ctx context.Context, agencyID int) ([]int, error) {
-	query := `SELECT title_number FROM agency_titles WHERE agency_id = $1`
+	query := fmt.Sprintf(`SELECT title_number FROM %s AS agency_titles WHERE agency_id = $1`, agencyTitlesSource(s.asOf))
 
 	rows, err := s.db.QueryContext(ctx, query, agencyID)
 	if err != nil {
@@ -164,7 +227,7 @@ func (s *AgencyStore) GetAgencyTitles(ctx context.Context, agencyID int) ([]int,
 
 // GetChildrenIDs retrieves IDs of all child agencies
 func (s *AgencyStore) GetChildrenIDs(ctx context.Context, parentID int) ([]int, error) {
-	query := `SELECT id FROM agencies WHERE parent_id = $1`
+	query := fmt.Sprintf(`SELECT id FROM %s AS agencies WHERE parent_id = $1`, agenciesSource(s.asOf))
 
 	rows, err := s.db.QueryContext(ctx, query, parentID)
 	if err != nil {
@@ -203,6 +266,12 @@ func (s *AgencyStore) UpdateWordCount(ctx context.Context, agencyID, wordCount,
 // InsertSnapshotIfChanged inserts an agency snapshot only if the checksum differs from the latest
 // Also records which titles were linked at this snapshot point
 func (s *AgencyStore) InsertSnapshotIfChanged(ctx context.Context, snap *model.AgencySnapshot, titleNumbers []int) (changed bool, err error) {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return false, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
 	// Check if there's already a snapshot for this exact date with the same checksum
 	// This allows re-imports of the same date to be idempotent, while ensuring
 	// historical imports for different dates always create snapshots
@@ -211,7 +280,7 @@ func (s *AgencyStore) InsertSnapshotIfChanged(ctx context.Context, snap *model.A
 		SELECT checksum FROM agency_snapshots
 		WHERE agency_id = $1 AND snapshot_date = $2
 	`
-	s.db.QueryRowContext(ctx, checksumQuery, snap.AgencyID, snap.SnapshotDate).Scan(&existingChecksum)
+	tx.QueryRowContext(ctx, checksumQuery, snap.AgencyID, sqlDate(snap.SnapshotDate)).Scan(&existingChecksum)
 
 	// Only skip if snapshot already exists for this date with same checksum
 	if existingChecksum.Valid && existingChecksum.String == snap.Checksum {
@@ -230,13 +299,13 @@ func (s *AgencyStore) InsertSnapshotIfChanged(ctx context.Context, snap *model.A
 		RETURNING id
 	`
 
-	err = s.db.QueryRowContext(ctx, query,
+	err = tx.QueryRowContext(ctx, query,
 		snap.AgencyID,
 		snap.AgencyName,
 		snap.TotalWordCount,
 		snap.RegulationCount,
 		snap.Checksum,
-		snap.SnapshotDate,
+		sqlDate(snap.SnapshotDate),
 	).Scan(&snap.ID)
 
 	if err != nil {
@@ -250,27 +319,38 @@ func (s *AgencyStore) InsertSnapshotIfChanged(ctx context.Context, snap *model.A
 			VALUES ($1, $2)
 			ON CONFLICT (agency_snapshot_id, title_number) DO NOTHING
 		`
-		if _, err := s.db.ExecContext(ctx, linkQuery, snap.ID, titleNum); err != nil {
-			// Log but don't fail the whole operation
-			continue
+		if _, err := tx.ExecContext(ctx, linkQuery, snap.ID, titleNum); err != nil {
+			return false, fmt.Errorf("failed to link title %d to snapshot for agency %d: %w", titleNum, snap.AgencyID, err)
 		}
 	}
 
+	if err := recordAgencyChange(ctx, tx, snap); err != nil {
+		return false, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return false, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
 	return true, nil
 }
 
-// ClearAgencyTitles removes all agency-title links (for re-import)
+// ClearAgencyTitles removes all agency-title and agency-chapter links (for re-import)
 func (s *AgencyStore) ClearAgencyTitles(ctx context.Context) error {
 	_, err := s.db.ExecContext(ctx, "DELETE FROM agency_titles")
 	if err != nil {
 		return fmt.Errorf("failed to clear agency_titles: %w", err)
 	}
+	_, err = s.db.ExecContext(ctx, "DELETE FROM agency_chapters")
+	if err != nil {
+		return fmt.Errorf("failed to clear agency_chapters: %w", err)
+	}
 	return nil
 }
 
 // GetTitleWordCount retrieves the word count for a title
 func (s *AgencyStore) GetTitleWordCount(ctx context.Context, titleNumber int) (int, error) {
-	query := `SELECT word_count FROM titles WHERE title_number = $1`
+	query := fmt.Sprintf(`SELECT word_count FROM %s AS titles WHERE title_number = $1`, titlesSource(s.asOf))
 
 	var wordCount int
 	err := s.db.QueryRowContext(ctx, query, titleNumber).Scan(&wordCount)
@@ -301,11 +381,11 @@ func (s *AgencyStore) GetDensityScoreForAgency(ctx context.Context, agency *mode
 	agencyDensity := float64(agency.TotalWordCount) / float64(agency.RegulationCount)
 
 	// Count how many agencies have lower density
-	query := `
-		SELECT COUNT(*) FROM agencies
+	query := fmt.Sprintf(`
+		SELECT COUNT(*) FROM %s AS agencies
 		WHERE regulation_count > 0
-		AND (total_word_count::float / regulation_count::float) < $1
-	`
+		AND (CAST(total_word_count AS DOUBLE PRECISION) / regulation_count) < $1
+	`, agenciesSource(s.asOf))
 	var lowerCount int
 	if err := s.db.QueryRowContext(ctx, query, agencyDensity).Scan(&lowerCount); err != nil {
 		return 0, err
@@ -313,7 +393,7 @@ func (s *AgencyStore) GetDensityScoreForAgency(ctx context.Context, agency *mode
 
 	// Count total agencies with density
 	var totalCount int
-	if err := s.db.QueryRowContext(ctx, "SELECT COUNT(*) FROM agencies WHERE regulation_count > 0").Scan(&totalCount); err != nil {
+	if err := s.db.QueryRowContext(ctx, fmt.Sprintf("SELECT COUNT(*) FROM %s AS agencies WHERE regulation_count > 0", agenciesSource(s.asOf))).Scan(&totalCount); err != nil {
 		return 0, err
 	}
 
@@ -362,21 +442,21 @@ func calculateDensityScores(agencies []AgencyWithDepth) {
 
 // GetAllHierarchical retrieves all agencies with depth information for hierarchical display
 func (s *AgencyStore) GetAllHierarchical(ctx context.Context) ([]AgencyWithDepth, error) {
+	return cache.Get(ctx, s.cache, cacheKey(s.asOf, "AgencyStore.GetAllHierarchical"), func() ([]AgencyWithDepth, error) {
+		return s.getAllHierarchical(ctx)
+	})
+}
+
+func (s *AgencyStore) getAllHierarchical(ctx context.Context) ([]AgencyWithDepth, error) {
 	// Get all agencies
 	agencies, err := s.GetAll(ctx)
 	if err != nil {
 		return nil, err
 	}
 
-	// Build a map for quick lookup
-	agencyMap := make(map[int]*model.Agency)
-	for i := range agencies {
-		agencyMap[agencies[i].ID] = &agencies[i]
-	}
-
 	// Get title counts for all agencies
 	titleCounts := make(map[int]int)
-	countQuery := `SELECT agency_id, COUNT(*) FROM agency_titles GROUP BY agency_id`
+	countQuery := fmt.Sprintf(`SELECT agency_id, COUNT(*) FROM %s AS agency_titles GROUP BY agency_id`, agencyTitlesSource(s.asOf))
 	rows, err := s.db.QueryContext(ctx, countQuery)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get title counts: %w", err)
@@ -394,23 +474,14 @@ func (s *AgencyStore) GetAllHierarchical(ctx context.Context) ([]AgencyWithDepth
 		return nil, err
 	}
 
-	// Calculate depth for each agency
-	var calculateDepth func(a *model.Agency) int
-	calculateDepth = func(a *model.Agency) int {
-		if !a.ParentID.Valid {
-			return 0
-		}
-		parent, ok := agencyMap[int(a.ParentID.Int64)]
-		if !ok {
-			return 0
-		}
-		return 1 + calculateDepth(parent)
-	}
+	return buildAgencyHierarchy(agencies, titleCounts), nil
+}
 
-	// Build result with depths, sorting parents before children
+// buildAgencyHierarchy orders agencies with each parent followed by its
+// children, recording their depth, title counts and density scores
+func buildAgencyHierarchy(agencies []model.Agency, titleCounts map[int]int) []AgencyWithDepth {
 	result := make([]AgencyWithDepth, 0, len(agencies))
 
-	// First, add all top-level agencies
 	var addAgencyWithChildren func(a *model.Agency, depth int)
 	addAgencyWithChildren = func(a *model.Agency, depth int) {
 		result = append(result, AgencyWithDepth{
@@ -436,53 +507,71 @@ func (s *AgencyStore) GetAllHierarchical(ctx context.Context) ([]AgencyWithDepth
 	// Calculate percentile-based density scores
 	calculateDensityScores(result)
 
-	return result, nil
+	return result
 }
 
 // GetAllSorted retrieves all agencies with custom sorting
 func (s *AgencyStore) GetAllSorted(ctx context.Context, sortBy, order string) ([]AgencyWithDepth, error) {
+	sortBy, sortOrder := agencySort(sortBy, order)
+	return cache.Get(ctx, s.cache, cacheKey(s.asOf, "AgencyStore.GetAllSorted", sortBy, sortOrder), func() ([]AgencyWithDepth, error) {
+		return s.getAllSorted(ctx, sortBy, sortOrder)
+	})
+}
+
+// agencySort maps a requested sort and order to one the queries support,
+// defaulting unknown values to word count and ascending, so that arbitrary
+// requests share cache entries rather than each adding one
+func agencySort(sortBy, order string) (string, string) {
+	switch sortBy {
+	case "name", "title_count":
+	default:
+		sortBy = "word_count"
+	}
+	if order == "desc" {
+		return sortBy, "DESC"
+	}
+	return sortBy, "ASC"
+}
+
+func (s *AgencyStore) getAllSorted(ctx context.Context, sortBy, sortOrder string) ([]AgencyWithDepth, error) {
 	// For name sorting with ascending order, use hierarchical view (preserves parent-child structure)
 	// For all other cases, use flat sorted list
-	if sortBy == "name" && order == "asc" {
+	if sortBy == "name" && sortOrder == "ASC" {
 		return s.GetAllHierarchical(ctx)
 	}
 
-	return s.getAllSortedFlat(ctx, sortBy, order)
+	return s.getAllSortedFlat(ctx, sortBy, sortOrder)
 }
 
-func (s *AgencyStore) getAllSortedFlat(ctx context.Context, sortBy, order string) ([]AgencyWithDepth, error) {
-	sortOrder := "ASC"
-	if order == "desc" {
-		sortOrder = "DESC"
-	}
-
+func (s *AgencyStore) getAllSortedFlat(ctx context.Context, sortBy, sortOrder string) ([]AgencyWithDepth, error) {
 	var query string
 	if sortBy == "title_count" {
 		query = fmt.Sprintf(`
 			SELECT a.id, a.agency_name, a.short_name, a.slug, a.parent_id,
 			       a.total_word_count, a.regulation_count, a.checksum, a.updated_at,
 			       COUNT(at.title_number) as title_count
-			FROM agencies a
-			LEFT JOIN agency_titles at ON a.id = at.agency_id
-			GROUP BY a.id
+			FROM %s AS a
+			LEFT JOIN %s AS at ON a.id = at.agency_id
+			GROUP BY a.id, a.agency_name, a.short_name, a.slug, a.parent_id,
+			         a.total_word_count, a.regulation_count, a.checksum, a.updated_at
 			ORDER BY title_count %s, a.agency_name ASC
-		`, sortOrder)
+		`, agenciesSource(s.asOf), agencyTitlesSource(s.asOf), sortOrder)
 	} else if sortBy == "name" {
 		query = fmt.Sprintf(`
 			SELECT a.id, a.agency_name, a.short_name, a.slug, a.parent_id,
 			       a.total_word_count, a.regulation_count, a.checksum, a.updated_at,
-			       (SELECT COUNT(*) FROM agency_titles WHERE agency_id = a.id) as title_count
-			FROM agencies a
+			       (SELECT COUNT(*) FROM %s AS at WHERE at.agency_id = a.id) as title_count
+			FROM %s AS a
 			ORDER BY a.agency_name %s
-		`, sortOrder)
+		`, agencyTitlesSource(s.asOf), agenciesSource(s.asOf), sortOrder)
 	} else {
 		query = fmt.Sprintf(`
 			SELECT a.id, a.agency_name, a.short_name, a.slug, a.parent_id,
 			       a.total_word_count, a.regulation_count, a.checksum, a.updated_at,
-			       (SELECT COUNT(*) FROM agency_titles WHERE agency_id = a.id) as title_count
-			FROM agencies a
+			       (SELECT COUNT(*) FROM %s AS at WHERE at.agency_id = a.id) as title_count
+			FROM %s AS a
 			ORDER BY a.total_word_count %s
-		`, sortOrder)
+		`, agencyTitlesSource(s.asOf), agenciesSource(s.asOf), sortOrder)
 	}
 
 	rows, err := s.db.QueryContext(ctx, query)
@@ -524,12 +613,12 @@ func (s *AgencyStore) getAllSortedFlat(ctx context.Context, sortBy, order string
 
 // GetByID retrieves an agency by its ID
 func (s *AgencyStore) GetByID(ctx context.Context, id int) (*model.Agency, error) {
-	query := `
+	query := fmt.Sprintf(`
 		SELECT id, agency_name, short_name, slug, parent_id, total_word_count,
 		       regulation_count, checksum, updated_at
-		FROM agencies
+		FROM %s AS agencies
 		WHERE id = $1
-	`
+	`, agenciesSource(s.asOf))
 
 	var a model.Agency
 	err := s.db.QueryRowContext(ctx, query, id).Scan(
@@ -555,13 +644,13 @@ func (s *AgencyStore) GetByID(ctx context.Context, id int) (*model.Agency, error
 
 // GetChildren retrieves all child agencies for a parent
 func (s *AgencyStore) GetChildren(ctx context.Context, parentID int) ([]model.Agency, error) {
-	query := `
+	query := fmt.Sprintf(`
 		SELECT id, agency_name, short_name, slug, parent_id, total_word_count,
 		       regulation_count, checksum, updated_at
-		FROM agencies
+		FROM %s AS agencies
 		WHERE parent_id = $1
 		ORDER BY agency_name
-	`
+	`, agenciesSource(s.asOf))
 
 	rows, err := s.db.QueryContext(ctx, query, parentID)
 	if err != nil {
@@ -594,14 +683,14 @@ func (s *AgencyStore) GetChildren(ctx context.Context, parentID int) ([]model.Ag
 
 // GetTitlesForAgency retrieves full title objects linked to an agency
 func (s *AgencyStore) GetTitlesForAgency(ctx context.Context, agencyID int) ([]model.Title, error) {
-	query := `
-		SELECT t.id, t.title_number, t.title_name, t.word_count,
-		       t.section_count, t.checksum, t.last_amended_date, t.fetched_at, t.created_at
-		FROM titles t
-		INNER JOIN agency_titles at ON t.title_number = at.title_number
+	query := fmt.Sprintf(`
+		SELECT t.id, t.title_number, t.title_name, t.word_count, t.section_count,
+		       t.readability_score, t.checksum, t.last_amended_date, t.fetched_at, t.created_at
+		FROM %s AS t
+		INNER JOIN %s AS at ON t.title_number = at.title_number
 		WHERE at.agency_id = $1
 		ORDER BY t.title_number
-	`
+	`, titlesSource(s.asOf), agencyTitlesSource(s.asOf))
 
 	rows, err := s.db.QueryContext(ctx, query, agencyID)
 	if err != nil {
@@ -618,6 +707,7 @@ func (s *AgencyStore) GetTitlesForAgency(ctx context.Context, agencyID int) ([]m
 			&t.TitleName,
 			&t.WordCount,
 			&t.SectionCount,
+			&t.Readability,
 			&t.Checksum,
 			&t.LastAmendedDate,
 			&t.FetchedAt,
@@ -634,13 +724,13 @@ func (s *AgencyStore) GetTitlesForAgency(ctx context.Context, agencyID int) ([]m
 
 // GetSnapshotsForAgency retrieves all snapshots for an agency
 func (s *AgencyStore) GetSnapshotsForAgency(ctx context.Context, agencyID int) ([]model.AgencySnapshot, error) {
-	query := `
+	query := fmt.Sprintf(`
 		SELECT id, agency_id, agency_name, total_word_count, regulation_count,
 		       checksum, snapshot_date, created_at
 		FROM agency_snapshots
-		WHERE agency_id = $1
+		WHERE agency_id = $1 AND %s
 		ORDER BY snapshot_date DESC
-	`
+	`, snapshotDateFilter(s.asOf))
 
 	rows, err := s.db.QueryContext(ctx, query, agencyID)
 	if err != nil {
@@ -672,8 +762,14 @@ func (s *AgencyStore) GetSnapshotsForAgency(ctx context.Context, agencyID int) (
 
 // CountAgencies returns the total number of agencies
 func (s *AgencyStore) CountAgencies(ctx context.Context) (int, error) {
+	return cache.Get(ctx, s.cache, cacheKey(s.asOf, "AgencyStore.CountAgencies"), func() (int, error) {
+		return s.countAgencies(ctx)
+	})
+}
+
+func (s *AgencyStore) countAgencies(ctx context.Context) (int, error) {
 	var count int
-	err := s.db.QueryRowContext(ctx, "SELECT COUNT(*) FROM agencies").Scan(&count)
+	err := s.db.QueryRowContext(ctx, fmt.Sprintf("SELECT COUNT(*) FROM %s AS agencies", agenciesSource(s.asOf))).Scan(&count)
 	if err != nil {
 		return 0, fmt.Errorf("failed to count agencies: %w", err)
 	}
@@ -682,7 +778,13 @@ func (s *AgencyStore) CountAgencies(ctx context.Context) (int, error) {
 
 // GetAgencySnapshotDates returns all unique snapshot dates for agencies
 func (s *AgencyStore) GetAgencySnapshotDates(ctx context.Context) ([]time.Time, error) {
-	query := `SELECT DISTINCT snapshot_date FROM agency_snapshots ORDER BY snapshot_date DESC`
+	return cache.Get(ctx, s.cache, cacheKey(s.asOf, "AgencyStore.GetAgencySnapshotDates"), func() ([]time.Time, error) {
+		return s.getAgencySnapshotDates(ctx)
+	})
+}
+
+func (s *AgencyStore) getAgencySnapshotDates(ctx context.Context) ([]time.Time, error) {
+	query := fmt.Sprintf(`SELECT DISTINCT snapshot_date FROM agency_snapshots WHERE %s ORDER BY snapshot_date DESC`, snapshotDateFilter(s.asOf))
 	rows, err := s.db.QueryContext(ctx, query)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get agency snapshot dates: %w", err)
@@ -704,7 +806,7 @@ func (s *AgencyStore) GetAgencySnapshotDates(ctx context.Context) ([]time.Time,
 // GetTitleCountForAgency returns the number of titles linked to an agency
 func (s *AgencyStore) GetTitleCountForAgency(ctx context.Context, agencyID int) (int, error) {
 	var count int
-	err := s.db.QueryRowContext(ctx, "SELECT COUNT(*) FROM agency_titles WHERE agency_id = $1", agencyID).Scan(&count)
+	err := s.db.QueryRowContext(ctx, fmt.Sprintf("SELECT COUNT(*) FROM %s AS agency_titles WHERE agency_id = $1", agencyTitlesSource(s.asOf)), agencyID).Scan(&count)
 	if err != nil {
 		return 0, fmt.Errorf("failed to count titles for agency %d: %w", agencyID, err)
 	}
@@ -0,0 +1,111 @@
+package store
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+type asOfContextKey struct{}
+
+// AsOfContextKey is the request context key holding the selected point-in-time
+// date; handlers set it and layouts read it to render the global date picker
+var AsOfContextKey = asOfContextKey{}
+
+// AsOfFromContext returns the point-in-time date stored in ctx, or the zero
+// time when the current state is being viewed
+func AsOfFromContext(ctx context.Context) time.Time {
+	if asOf, ok := ctx.Value(AsOfContextKey).(time.Time); ok {
+		return asOf
+	}
+	return time.Time{}
+}
+
+// asOfLiteral formats a date as a SQL literal; it is only ever built from a
+// time.Time so it is safe to interpolate. Postgres reads the untyped literal
+// as a DATE and SQLite compares it with dates stored as text.
+func asOfLiteral(asOf time.Time) string {
+	return fmt.Sprintf("'%s'", sqlDate(asOf))
+}
+
+// titlesSource returns the relation to read titles from: the titles table for
+// the current state, or each title's latest snapshot on or before asOf
+func titlesSource(asOf time.Time) string {
+	if asOf.IsZero() {
+		return "titles"
+	}
+	return fmt.Sprintf(`(
+		SELECT ts.id, ts.title_number, ts.title_name, ts.word_count, ts.section_count,
+		       ts.readability_score, ts.checksum, ts.last_amended_date, ts.snapshot_date AS fetched_at,
+		       ts.created_at
+		FROM title_snapshots ts
+		WHERE ts.snapshot_date = (
+			SELECT MAX(latest.snapshot_date) FROM title_snapshots latest
+			WHERE latest.title_number = ts.title_number AND latest.snapshot_date <= %s
+		)
+	)`, asOfLiteral(asOf))
+}
+
+// latestAgencySnapshots selects each agency's latest snapshot on or before
+// asOf. Snapshot dates are unique per agency, so matching the latest date
+// picks one row without Postgres's DISTINCT ON.
+func latestAgencySnapshots(asOf time.Time) string {
+	return fmt.Sprintf(`(
+		SELECT ags.*
+		FROM agency_snapshots ags
+		WHERE ags.snapshot_date = (
+			SELECT MAX(latest.snapshot_date) FROM agency_snapshots latest
+			WHERE latest.agency_id = ags.agency_id AND latest.snapshot_date <= %s
+		)
+	)`, asOfLiteral(asOf))
+}
+
+// agenciesSource returns the relation to read agencies from. Historical rows
+// take their metrics from the latest snapshot and their hierarchy from the
+// current agencies table, which the eCFR does not version.
+func agenciesSource(asOf time.Time) string {
+	if asOf.IsZero() {
+		return "agencies"
+	}
+	return fmt.Sprintf(`(
+		SELECT ag.id, snap.agency_name, ag.short_name, ag.slug, ag.parent_id,
+		       snap.total_word_count, snap.regulation_count, snap.checksum,
+		       snap.snapshot_date AS updated_at
+		FROM agencies ag
+		INNER JOIN %s snap ON snap.agency_id = ag.id
+	)`, latestAgencySnapshots(asOf))
+}
+
+// agencyTitlesSource returns the agency-title links in effect at asOf, taken
+// from the titles recorded with each agency's latest snapshot
+func agencyTitlesSource(asOf time.Time) string {
+	if asOf.IsZero() {
+		return "agency_titles"
+	}
+	return fmt.Sprintf(`(
+		SELECT snap.agency_id, ast.title_number
+		FROM %s snap
+		INNER JOIN agency_snapshot_titles ast ON ast.agency_snapshot_id = snap.id
+	)`, latestAgencySnapshots(asOf))
+}
+
+// snapshotDateFilter returns a condition restricting snapshot queries to
+// dates on or before asOf
+func snapshotDateFilter(asOf time.Time) string {
+	if asOf.IsZero() {
+		return "TRUE"
+	}
+	return "snapshot_date <= " + asOfLiteral(asOf)
+}
+
+// cacheKey names a cached read of the current state or of an as-of view
+func cacheKey(asOf time.Time, name string, args ...any) string {
+	key := name
+	if !asOf.IsZero() {
+		key += "@" + asOf.Format("2006-01-02")
+	}
+	for _, arg := range args {
+		key += fmt.Sprintf("|%v", arg)
+	}
+	return key
+}
@@ -0,0 +1,168 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/jjenkins/usds/internal/model"
+)
+
+// ChangeStore reads the change events recorded when snapshots are saved
+type ChangeStore struct {
+	db *sql.DB
+}
+
+// NewChangeStore creates a new ChangeStore
+func NewChangeStore(db *sql.DB) *ChangeStore {
+	return &ChangeStore{db: db}
+}
+
+// ChangeFilter narrows a change event listing
+type ChangeFilter struct {
+	TitleNumber int    // Zero for all titles
+	AgencySlug  string // Agency events plus events for titles linked to the agency
+	Limit       int
+}
+
+// List returns change events matching the filter, newest first
+func (s *ChangeStore) List(ctx context.Context, f ChangeFilter) ([]model.ChangeEvent, error) {
+	query := `
+		SELECT e.id, e.entity_type, e.title_number, e.agency_id, a.slug, e.entity_name,
+		       e.snapshot_date, e.previous_snapshot_date, e.previous_word_count,
+		       e.word_count, e.checksum, e.created_at
+		FROM change_events e
+		LEFT JOIN agencies a ON a.id = e.agency_id
+		WHERE ($1 = 0 OR e.title_number = $1)
+		  AND ($2 = '' OR a.slug = $2 OR e.title_number IN (
+			SELECT at.title_number FROM agency_titles at
+			INNER JOIN agencies fa ON fa.id = at.agency_id
+			WHERE fa.slug = $2
+		  ))
+		ORDER BY e.created_at DESC, e.id DESC
+		LIMIT $3
+	`
+
+	rows, err := s.db.QueryContext(ctx, query, f.TitleNumber, f.AgencySlug, f.Limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list change events: %w", err)
+	}
+	defer rows.Close()
+
+	var events []model.ChangeEvent
+	for rows.Next() {
+		var e model.ChangeEvent
+		err := rows.Scan(
+			&e.ID,
+			&e.EntityType,
+			&e.TitleNumber,
+			&e.AgencyID,
+			&e.AgencySlug,
+			&e.EntityName,
+			&e.SnapshotDate,
+			&e.PreviousSnapshotDate,
+			&e.PreviousWordCount,
+			&e.WordCount,
+			&e.Checksum,
+			&e.CreatedAt,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan change event: %w", err)
+		}
+		events = append(events, e)
+	}
+
+	return events, rows.Err()
+}
+
+// dbtx is satisfied by both *sql.DB and *sql.Tx
+type dbtx interface {
+	ExecContext(ctx context.Context, query string, args ...any) (sql.Result, error)
+	QueryContext(ctx context.Context, query string, args ...any) (*sql.Rows, error)
+	QueryRowContext(ctx context.Context, query string, args ...any) *sql.Row
+}
+
+// recordTitleChange records a change event if a title's new snapshot differs
+// from the snapshot before it, and queues it for matching webhooks. A title's
+// first snapshot is recorded too, with no previous date or word count.
+func recordTitleChange(ctx context.Context, db dbtx, t *model.Title, snapshotDate time.Time) error {
+	var prevDate sql.NullTime
+	var prevWordCount sql.NullInt64
+	var prevChecksum sql.NullString
+	err := db.QueryRowContext(ctx, `
+		SELECT snapshot_date, word_count, checksum
+		FROM title_snapshots
+		WHERE title_number = $1 AND snapshot_date < $2
+		ORDER BY snapshot_date DESC
+		LIMIT 1
+	`, t.TitleNumber, sqlDate(snapshotDate)).Scan(&prevDate, &prevWordCount, &prevChecksum)
+	if err != nil && err != sql.ErrNoRows {
+		return fmt.Errorf("failed to get previous snapshot for title %d: %w", t.TitleNumber, err)
+	}
+	if prevChecksum.Valid && prevChecksum.String == t.Checksum {
+		return nil
+	}
+
+	var eventID int
+	err = db.QueryRowContext(ctx, `
+		INSERT INTO change_events (entity_type, title_number, entity_name, snapshot_date,
+		                           previous_snapshot_date, previous_word_count, word_count, checksum)
+		VALUES ('title', $1, $2, $3, $4, $5, $6, $7)
+		ON CONFLICT (title_number, snapshot_date) WHERE entity_type = 'title' DO UPDATE SET
+			entity_name = EXCLUDED.entity_name,
+			previous_snapshot_date = EXCLUDED.previous_snapshot_date,
+			previous_word_count = EXCLUDED.previous_word_count,
+			word_count = EXCLUDED.word_count,
+			checksum = EXCLUDED.checksum
+		RETURNING id
+	`, t.TitleNumber, fmt.Sprintf("Title %d: %s", t.TitleNumber, t.TitleName), sqlDate(snapshotDate),
+		sqlNullDate(prevDate), prevWordCount, t.WordCount, t.Checksum).Scan(&eventID)
+	if err != nil {
+		return fmt.Errorf("failed to record change for title %d: %w", t.TitleNumber, err)
+	}
+
+	return enqueueWebhookDeliveries(ctx, db, eventID)
+}
+
+// recordAgencyChange records a change event if an agency's new snapshot
+// differs from the snapshot before it or is its first, as recordTitleChange
+// does for titles
+func recordAgencyChange(ctx context.Context, db dbtx, snap *model.AgencySnapshot) error {
+	var prevDate sql.NullTime
+	var prevWordCount sql.NullInt64
+	var prevChecksum sql.NullString
+	err := db.QueryRowContext(ctx, `
+		SELECT snapshot_date, total_word_count, checksum
+		FROM agency_snapshots
+		WHERE agency_id = $1 AND snapshot_date < $2
+		ORDER BY snapshot_date DESC
+		LIMIT 1
+	`, snap.AgencyID, sqlDate(snap.SnapshotDate)).Scan(&prevDate, &prevWordCount, &prevChecksum)
+	if err != nil && err != sql.ErrNoRows {
+		return fmt.Errorf("failed to get previous snapshot for agency %d: %w", snap.AgencyID, err)
+	}
+	if prevChecksum.Valid && prevChecksum.String == snap.Checksum {
+		return nil
+	}
+
+	var eventID int
+	err = db.QueryRowContext(ctx, `
+		INSERT INTO change_events (entity_type, agency_id, entity_name, snapshot_date,
+		                           previous_snapshot_date, previous_word_count, word_count, checksum)
+		VALUES ('agency', $1, $2, $3, $4, $5, $6, $7)
+		ON CONFLICT (agency_id, snapshot_date) WHERE entity_type = 'agency' DO UPDATE SET
+			entity_name = EXCLUDED.entity_name,
+			previous_snapshot_date = EXCLUDED.previous_snapshot_date,
+			previous_word_count = EXCLUDED.previous_word_count,
+			word_count = EXCLUDED.word_count,
+			checksum = EXCLUDED.checksum
+		RETURNING id
+	`, snap.AgencyID, snap.AgencyName, sqlDate(snap.SnapshotDate),
+		sqlNullDate(prevDate), prevWordCount, snap.TotalWordCount, snap.Checksum).Scan(&eventID)
+	if err != nil {
+		return fmt.Errorf("failed to record change for agency %d: %w", snap.AgencyID, err)
+	}
+
+	return enqueueWebhookDeliveries(ctx, db, eventID)
+}
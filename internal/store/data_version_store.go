@@ -0,0 +1,45 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"github.com/jjenkins/usds/internal/model"
+)
+
+// DataVersionStore reads and bumps the data version
+type DataVersionStore struct {
+	db *sql.DB
+}
+
+// NewDataVersionStore creates a new DataVersionStore
+func NewDataVersionStore(db *sql.DB) *DataVersionStore {
+	return &DataVersionStore{db: db}
+}
+
+// Get returns the current data version
+func (s *DataVersionStore) Get(ctx context.Context) (model.DataVersion, error) {
+	var v model.DataVersion
+	err := s.db.QueryRowContext(ctx, `SELECT version, updated_at FROM data_version`).Scan(&v.Version, &v.UpdatedAt)
+	if errors.Is(err, sql.ErrNoRows) {
+		return model.DataVersion{}, nil
+	}
+	if err != nil {
+		return model.DataVersion{}, fmt.Errorf("failed to get data version: %w", err)
+	}
+	return v, nil
+}
+
+// Bump records that imported data changed
+func (s *DataVersionStore) Bump(ctx context.Context) error {
+	_, err := s.db.ExecContext(ctx, `
+		INSERT INTO data_version (version, updated_at) VALUES (1, CURRENT_TIMESTAMP)
+		ON CONFLICT (id) DO UPDATE SET version = data_version.version + 1, updated_at = CURRENT_TIMESTAMP
+	`)
+	if err != nil {
+		return fmt.Errorf("failed to bump data version: %w", err)
+	}
+	return nil
+}
@@ -3,12 +3,35 @@ package store
 import (
 	"database/sql"
 	"fmt"
+	"strings"
+	"time"
 
+	"github.com/jjenkins/usds/internal/db"
 	_ "github.com/lib/pq"
+	"modernc.org/sqlite" // Registers the pure-Go "sqlite" driver, so every build runs on SQLite
 )
 
-// NewDB creates a new database connection pool
+// sqliteScheme prefixes DSNs for the SQLite backend, as in
+// sqlite:///var/lib/usds.db or sqlite://usds.db for a relative path
+const sqliteScheme = "sqlite:"
+
+// sqliteDriver names the registered SQLite driver
+const sqliteDriver = "sqlite"
+
+// sqliteParams enforce foreign keys as Postgres does, wait on writers rather
+// than failing with SQLITE_BUSY, and take the write lock when a transaction
+// begins so concurrent read-then-write transactions cannot deadlock
+const sqliteParams = "_pragma=foreign_keys(1)&_pragma=busy_timeout(5000)&_pragma=journal_mode(WAL)" +
+	"&_txlock=immediate&_time_format=sqlite"
+
+// NewDB creates a new database connection pool. A sqlite: DSN opens a SQLite
+// database file, creating it and applying the schema as needed; any other DSN
+// is a Postgres connection string. Every store works on both.
 func NewDB(dsn string) (*sql.DB, error) {
+	if path, ok := strings.CutPrefix(dsn, sqliteScheme); ok {
+		return openSQLite(strings.TrimPrefix(path, "//"))
+	}
+
 	db, err := sql.Open("postgres", dsn)
 	if err != nil {
 		return nil, fmt.Errorf("failed to open database: %w", err)
@@ -20,3 +43,43 @@ func NewDB(dsn string) (*sql.DB, error) {
 
 	return db, nil
 }
+
+func openSQLite(path string) (*sql.DB, error) {
+	if path == "" {
+		return nil, fmt.Errorf("failed to open database: %s DSN has no file path", sqliteScheme)
+	}
+
+	conn, err := sql.Open(sqliteDriver, "file:"+path+"?"+sqliteParams)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open database: %w", err)
+	}
+
+	if _, err := conn.Exec(db.SQLiteSchema); err != nil {
+		conn.Close()
+		return nil, fmt.Errorf("failed to apply SQLite schema: %w", err)
+	}
+
+	return conn, nil
+}
+
+// isSQLite reports whether conn is a SQLite database, for the few queries
+// that cannot be written once for both backends
+func isSQLite(conn *sql.DB) bool {
+	_, ok := conn.Driver().(*sqlite.Driver)
+	return ok
+}
+
+// sqlDate formats t as a date parameter. DATE columns are compared and kept
+// unique as text on SQLite, so dates must not carry a time of day; Postgres
+// parses the same text into a DATE.
+func sqlDate(t time.Time) string {
+	return t.Format("2006-01-02")
+}
+
+// sqlNullDate is sqlDate for nullable dates
+func sqlNullDate(t sql.NullTime) sql.NullString {
+	if !t.Valid {
+		return sql.NullString{}
+	}
+	return sql.NullString{String: sqlDate(t.Time), Valid: true}
+}
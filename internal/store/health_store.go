@@ -0,0 +1,58 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"github.com/lib/pq"
+)
+
+// SchemaVersion is the version internal/db/schema.sql records. Bump both
+// together whenever the schema changes.
+const SchemaVersion = 5
+
+// HealthStore answers the readiness checks
+type HealthStore struct {
+	db *sql.DB
+}
+
+// NewHealthStore creates a new HealthStore
+func NewHealthStore(db *sql.DB) *HealthStore {
+	return &HealthStore{db: db}
+}
+
+// Ping checks that the database accepts connections
+func (s *HealthStore) Ping(ctx context.Context) error {
+	if err := s.db.PingContext(ctx); err != nil {
+		return fmt.Errorf("failed to ping database: %w", err)
+	}
+	return nil
+}
+
+// SchemaVersion returns the version of the applied schema, or 0 if the schema
+// predates versioning
+func (s *HealthStore) SchemaVersion(ctx context.Context) (int, error) {
+	var version int
+	err := s.db.QueryRowContext(ctx, `SELECT version FROM schema_version`).Scan(&version)
+
+	var pqErr *pq.Error
+	if errors.Is(err, sql.ErrNoRows) || (errors.As(err, &pqErr) && pqErr.Code == "42P01") {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, fmt.Errorf("failed to get schema version: %w", err)
+	}
+	return version, nil
+}
+
+// NewestFetchedAt returns when a title was last fetched from the eCFR
+func (s *HealthStore) NewestFetchedAt(ctx context.Context) (sql.NullTime, error) {
+	var fetchedAt sql.NullTime
+	err := s.db.QueryRowContext(ctx, `SELECT MAX(fetched_at) FROM titles`).Scan(&fetchedAt)
+	if err != nil {
+		return fetchedAt, fmt.Errorf("failed to get newest fetch time: %w", err)
+	}
+	return fetchedAt, nil
+}
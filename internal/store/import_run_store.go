@@ -0,0 +1,253 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	"github.com/jjenkins/usds/internal/model"
+)
+
+// ImportRunStore handles the import run history and failure log
+type ImportRunStore struct {
+	db *sql.DB
+}
+
+// NewImportRunStore creates a new ImportRunStore
+func NewImportRunStore(db *sql.DB) *ImportRunStore {
+	return &ImportRunStore{db: db}
+}
+
+// StartRun inserts a running import and sets its ID and start time
+func (s *ImportRunStore) StartRun(ctx context.Context, run *model.ImportRun) error {
+	query := `
+		INSERT INTO import_runs (source, mode, snapshot_date, title_number, from_date, to_date, started_by)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)
+		RETURNING id, status, started_at
+	`
+
+	err := s.db.QueryRowContext(ctx, query,
+		run.Source,
+		run.Mode,
+		run.SnapshotDate,
+		run.TitleNumber,
+		run.FromDate,
+		run.ToDate,
+		run.StartedBy,
+	).Scan(&run.ID, &run.Status, &run.StartedAt)
+	if err != nil {
+		return fmt.Errorf("failed to record import run: %w", err)
+	}
+
+	return nil
+}
+
+// FinishRun stores a run's final status and counts along with its failures
+func (s *ImportRunStore) FinishRun(ctx context.Context, run *model.ImportRun, failures []model.ImportFailure) error {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	query := `
+		UPDATE import_runs SET
+			status = $2, error = $3,
+			titles_total = $4, titles_imported = $5, titles_changed = $6,
+			titles_unchanged = $7, titles_skipped = $8, titles_failed = $9,
+			agencies_total = $10, agencies_imported = $11, agencies_failed = $12,
+			versions_processed = $13, snapshots_created = $14,
+			finished_at = CURRENT_TIMESTAMP
+		WHERE id = $1
+		RETURNING finished_at
+	`
+
+	err = tx.QueryRowContext(ctx, query,
+		run.ID,
+		run.Status,
+		run.Error,
+		run.TitlesTotal,
+		run.TitlesImported,
+		run.TitlesChanged,
+		run.TitlesUnchanged,
+		run.TitlesSkipped,
+		run.TitlesFailed,
+		run.AgenciesTotal,
+		run.AgenciesImported,
+		run.AgenciesFailed,
+		run.VersionsProcessed,
+		run.SnapshotsCreated,
+	).Scan(&run.FinishedAt)
+	if err != nil {
+		return fmt.Errorf("failed to finish import run %d: %w", run.ID, err)
+	}
+
+	for _, f := range failures {
+		_, err := tx.ExecContext(ctx, `
+			INSERT INTO import_failures (run_id, title_number, version_date, agency_slug, message)
+			VALUES ($1, $2, $3, $4, $5)
+		`, run.ID, f.TitleNumber, f.VersionDate, f.AgencySlug, f.Message)
+		if err != nil {
+			return fmt.Errorf("failed to record import failure: %w", err)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return nil
+}
+
+// ImportRunFilter narrows the run history
+type ImportRunFilter struct {
+	Source string // Empty for every source
+	Status string // Empty for every status
+	Limit  int
+}
+
+const importRunColumns = `
+	id, source, mode, snapshot_date, title_number, from_date, to_date,
+	started_by, status, error,
+	titles_total, titles_imported, titles_changed, titles_unchanged, titles_skipped, titles_failed,
+	agencies_total, agencies_imported, agencies_failed,
+	versions_processed, snapshots_created, started_at, finished_at
+`
+
+// ListRuns returns import runs, newest first
+func (s *ImportRunStore) ListRuns(ctx context.Context, f ImportRunFilter) ([]model.ImportRun, error) {
+	query := fmt.Sprintf(`
+		SELECT %s
+		FROM import_runs
+		WHERE ($1 = '' OR source = $1)
+		  AND ($2 = '' OR status = $2)
+		ORDER BY started_at DESC, id DESC
+		LIMIT $3
+	`, importRunColumns)
+
+	rows, err := s.db.QueryContext(ctx, query, f.Source, f.Status, f.Limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list import runs: %w", err)
+	}
+	defer rows.Close()
+
+	var runs []model.ImportRun
+	for rows.Next() {
+		run, err := scanImportRun(rows)
+		if err != nil {
+			return nil, err
+		}
+		runs = append(runs, *run)
+	}
+
+	return runs, rows.Err()
+}
+
+// GetRun returns an import run, or nil if it does not exist
+func (s *ImportRunStore) GetRun(ctx context.Context, id int) (*model.ImportRun, error) {
+	query := fmt.Sprintf(`SELECT %s FROM import_runs WHERE id = $1`, importRunColumns)
+
+	run, err := scanImportRun(s.db.QueryRowContext(ctx, query, id))
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	return run, err
+}
+
+// FailureFilter narrows the failure log
+type FailureFilter struct {
+	RunID       int // Zero for every run
+	TitleNumber int // Zero for every title and agency
+	Limit       int
+}
+
+// ListFailures returns recorded import failures, newest first
+func (s *ImportRunStore) ListFailures(ctx context.Context, f FailureFilter) ([]model.ImportFailure, error) {
+	query := `
+		SELECT id, run_id, title_number, version_date, agency_slug, message, created_at
+		FROM import_failures
+		WHERE ($1 = 0 OR run_id = $1)
+		  AND ($2 = 0 OR title_number = $2)
+		ORDER BY created_at DESC, id DESC
+		LIMIT $3
+	`
+
+	rows, err := s.db.QueryContext(ctx, query, f.RunID, f.TitleNumber, f.Limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list import failures: %w", err)
+	}
+	defer rows.Close()
+
+	var failures []model.ImportFailure
+	for rows.Next() {
+		var failure model.ImportFailure
+		err := rows.Scan(
+			&failure.ID,
+			&failure.RunID,
+			&failure.TitleNumber,
+			&failure.VersionDate,
+			&failure.AgencySlug,
+			&failure.Message,
+			&failure.CreatedAt,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan import failure: %w", err)
+		}
+		failures = append(failures, failure)
+	}
+
+	return failures, rows.Err()
+}
+
+// scanner is satisfied by *sql.Row and *sql.Rows
+type scanner interface {
+	Scan(dest ...any) error
+}
+
+func scanImportRun(row scanner) (*model.ImportRun, error) {
+	var run model.ImportRun
+	err := row.Scan(
+		&run.ID,
+		&run.Source,
+		&run.Mode,
+		&run.SnapshotDate,
+		&run.TitleNumber,
+		&run.FromDate,
+		&run.ToDate,
+		&run.StartedBy,
+		&run.Status,
+		&run.Error,
+		&run.TitlesTotal,
+		&run.TitlesImported,
+		&run.TitlesChanged,
+		&run.TitlesUnchanged,
+		&run.TitlesSkipped,
+		&run.TitlesFailed,
+		&run.AgenciesTotal,
+		&run.AgenciesImported,
+		&run.AgenciesFailed,
+		&run.VersionsProcessed,
+		&run.SnapshotsCreated,
+		&run.StartedAt,
+		&run.FinishedAt,
+	)
+	if err == sql.ErrNoRows {
+		return nil, err
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to scan import run: %w", err)
+	}
+	return &run, nil
+}
+
+// LastSucceededAt returns when the most recent successful run finished
+func (s *ImportRunStore) LastSucceededAt(ctx context.Context) (sql.NullTime, error) {
+	var finishedAt sql.NullTime
+	err := s.db.QueryRowContext(ctx, `
+		SELECT MAX(finished_at) FROM import_runs WHERE status = $1
+	`, model.ImportSucceeded).Scan(&finishedAt)
+	if err != nil {
+		return finishedAt, fmt.Errorf("failed to get last successful import: %w", err)
+	}
+	return finishedAt, nil
+}
@@ -0,0 +1,164 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+)
+
+// AgencyTitleLink is an agency_titles row
+type AgencyTitleLink struct {
+	AgencyID    int
+	TitleNumber int
+}
+
+// OrphanSnapshot counts rows on one date that belong to a title snapshot
+// missing from the database
+type OrphanSnapshot struct {
+	Table        string // title_snapshots, agency_snapshot_titles or sections
+	TitleNumber  int
+	SnapshotDate time.Time
+	Rows         int
+}
+
+// IntegrityStore answers the questions verify asks that the title and agency
+// stores do not: what content and sections are stored for a snapshot, and
+// which rows point at titles or agencies that no longer exist
+type IntegrityStore struct {
+	db *sql.DB
+}
+
+// NewIntegrityStore creates a new IntegrityStore
+func NewIntegrityStore(db *sql.DB) *IntegrityStore {
+	return &IntegrityStore{db: db}
+}
+
+// SnapshotContent returns the XML stored with a title snapshot, or nil when
+// none was kept
+func (s *IntegrityStore) SnapshotContent(ctx context.Context, titleNumber int, snapshotDate time.Time) ([]byte, error) {
+	query := `
+		SELECT COALESCE(ts.full_content, t.full_content)
+		FROM title_snapshots ts
+		LEFT JOIN titles t ON t.title_number = ts.title_number AND t.checksum = ts.checksum
+		WHERE ts.title_number = $1 AND ts.snapshot_date = $2
+	`
+
+	var content sql.NullString
+	err := s.db.QueryRowContext(ctx, query, titleNumber, sqlDate(snapshotDate)).Scan(&content)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get content for title %d snapshot %s: %w", titleNumber, sqlDate(snapshotDate), err)
+	}
+	if !content.Valid {
+		return nil, nil
+	}
+
+	return []byte(content.String), nil
+}
+
+// CountSections returns how many sections are stored for a title snapshot
+func (s *IntegrityStore) CountSections(ctx context.Context, titleNumber int, snapshotDate time.Time) (int, error) {
+	query := `SELECT COUNT(*) FROM sections WHERE title_number = $1 AND snapshot_date = $2`
+
+	var count int
+	if err := s.db.QueryRowContext(ctx, query, titleNumber, sqlDate(snapshotDate)).Scan(&count); err != nil {
+		return 0, fmt.Errorf("failed to count sections for title %d: %w", titleNumber, err)
+	}
+
+	return count, nil
+}
+
+// OrphanAgencyTitles returns agency_titles rows whose agency or title is
+// missing, which the foreign keys should prevent but a restore or manual
+// edit with them disabled does not
+func (s *IntegrityStore) OrphanAgencyTitles(ctx context.Context) ([]AgencyTitleLink, error) {
+	query := `
+		SELECT at.agency_id, at.title_number
+		FROM agency_titles at
+		WHERE NOT EXISTS (SELECT 1 FROM agencies a WHERE a.id = at.agency_id)
+		   OR NOT EXISTS (SELECT 1 FROM titles t WHERE t.title_number = at.title_number)
+		ORDER BY at.agency_id, at.title_number
+	`
+
+	rows, err := s.db.QueryContext(ctx, query)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query orphan agency titles: %w", err)
+	}
+	defer rows.Close()
+
+	var links []AgencyTitleLink
+	for rows.Next() {
+		var link AgencyTitleLink
+		if err := rows.Scan(&link.AgencyID, &link.TitleNumber); err != nil {
+			return nil, fmt.Errorf("failed to scan agency title: %w", err)
+		}
+		links = append(links, link)
+	}
+
+	return links, rows.Err()
+}
+
+// orphanSnapshotQueries find each kind of orphan snapshot row, grouped by
+// title and date, by the table they are in
+var orphanSnapshotQueries = []struct {
+	table string
+	query string
+}{
+	{"title_snapshots", `
+		SELECT ts.title_number, ts.snapshot_date, COUNT(*)
+		FROM title_snapshots ts
+		WHERE NOT EXISTS (SELECT 1 FROM titles t WHERE t.title_number = ts.title_number)
+		GROUP BY ts.title_number, ts.snapshot_date
+		ORDER BY ts.title_number, ts.snapshot_date
+	`},
+	{"agency_snapshot_titles", `
+		SELECT ast.title_number, s.snapshot_date, COUNT(*)
+		FROM agency_snapshot_titles ast
+		JOIN agency_snapshots s ON s.id = ast.agency_snapshot_id
+		WHERE NOT EXISTS (SELECT 1 FROM titles t WHERE t.title_number = ast.title_number)
+		GROUP BY ast.title_number, s.snapshot_date
+		ORDER BY ast.title_number, s.snapshot_date
+	`},
+	{"sections", `
+		SELECT sec.title_number, sec.snapshot_date, COUNT(*)
+		FROM sections sec
+		WHERE NOT EXISTS (
+			SELECT 1 FROM title_snapshots ts
+			WHERE ts.title_number = sec.title_number AND ts.snapshot_date = sec.snapshot_date
+		)
+		GROUP BY sec.title_number, sec.snapshot_date
+		ORDER BY sec.title_number, sec.snapshot_date
+	`},
+}
+
+// OrphanSnapshots returns title snapshots and agency snapshot titles for
+// titles missing from titles, and sections whose title snapshot is missing.
+// None of these tables has a foreign key to enforce it.
+func (s *IntegrityStore) OrphanSnapshots(ctx context.Context) ([]OrphanSnapshot, error) {
+	var orphans []OrphanSnapshot
+	for _, q := range orphanSnapshotQueries {
+		rows, err := s.db.QueryContext(ctx, q.query)
+		if err != nil {
+			return nil, fmt.Errorf("failed to query orphan %s: %w", q.table, err)
+		}
+
+		for rows.Next() {
+			o := OrphanSnapshot{Table: q.table}
+			if err := rows.Scan(&o.TitleNumber, &o.SnapshotDate, &o.Rows); err != nil {
+				rows.Close()
+				return nil, fmt.Errorf("failed to scan orphan %s: %w", q.table, err)
+			}
+			orphans = append(orphans, o)
+		}
+		err = rows.Err()
+		rows.Close()
+		if err != nil {
+			return nil, fmt.Errorf("failed to read orphan %s: %w", q.table, err)
+		}
+	}
+
+	return orphans, nil
+}
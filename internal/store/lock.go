@@ -0,0 +1,137 @@
+package store
+
+import (
+	"context"
+	"crypto/rand"
+	"database/sql"
+	"encoding/hex"
+	"fmt"
+	"log/slog"
+	"time"
+)
+
+// ImportLockKey is the advisory lock key held while any import runs, so that
+// the CLI, the admin pages and every daemon replica import one at a time
+const ImportLockKey int64 = 0x75736473 // "usds"
+
+// SQLite has no advisory locks, so there the lock is a row in advisory_locks
+// leased for sqliteLockLease and renewed while held; a crashed holder's
+// lease simply runs out
+const (
+	sqliteLockLease = time.Minute
+	sqliteLockRenew = sqliteLockLease / 3
+)
+
+// AdvisoryLock is a held advisory lock. On Postgres it is a session-level
+// advisory lock, which belongs to a connection, so the lock keeps its
+// connection out of the pool until released.
+type AdvisoryLock struct {
+	key int64
+
+	conn *sql.Conn // Postgres
+
+	db     *sql.DB // SQLite
+	holder string
+	stop   chan struct{}
+	done   chan struct{}
+}
+
+// TryAdvisoryLock takes the advisory lock without waiting. It returns nil,
+// nil if another session already holds it.
+func TryAdvisoryLock(ctx context.Context, db *sql.DB, key int64) (*AdvisoryLock, error) {
+	if isSQLite(db) {
+		return trySQLiteLock(ctx, db, key)
+	}
+
+	conn, err := db.Conn(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get connection for advisory lock: %w", err)
+	}
+
+	var acquired bool
+	if err := conn.QueryRowContext(ctx, `SELECT pg_try_advisory_lock($1)`, key).Scan(&acquired); err != nil {
+		conn.Close()
+		return nil, fmt.Errorf("failed to take advisory lock %d: %w", key, err)
+	}
+	if !acquired {
+		conn.Close()
+		return nil, nil
+	}
+
+	return &AdvisoryLock{conn: conn, key: key}, nil
+}
+
+// trySQLiteLock leases the lock's row unless another holder's lease is
+// still current, then renews the lease until the lock is released
+func trySQLiteLock(ctx context.Context, db *sql.DB, key int64) (*AdvisoryLock, error) {
+	token := make([]byte, 16)
+	if _, err := rand.Read(token); err != nil {
+		return nil, fmt.Errorf("failed to generate advisory lock holder: %w", err)
+	}
+	holder := hex.EncodeToString(token)
+
+	query := `
+		INSERT INTO advisory_locks (lock_key, holder, expires_at)
+		VALUES ($1, $2, $3)
+		ON CONFLICT (lock_key) DO UPDATE SET holder = excluded.holder, expires_at = excluded.expires_at
+		WHERE advisory_locks.expires_at < $4
+	`
+	now := time.Now().UTC()
+	result, err := db.ExecContext(ctx, query, key, holder, now.Add(sqliteLockLease), now)
+	if err != nil {
+		return nil, fmt.Errorf("failed to take advisory lock %d: %w", key, err)
+	}
+	n, err := result.RowsAffected()
+	if err != nil {
+		return nil, fmt.Errorf("failed to take advisory lock %d: %w", key, err)
+	}
+	if n == 0 {
+		return nil, nil
+	}
+
+	l := &AdvisoryLock{key: key, db: db, holder: holder, stop: make(chan struct{}), done: make(chan struct{})}
+	go l.renew()
+	return l, nil
+}
+
+// renew extends a SQLite lock's lease until Release
+func (l *AdvisoryLock) renew() {
+	defer close(l.done)
+
+	ticker := time.NewTicker(sqliteLockRenew)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-l.stop:
+			return
+		case <-ticker.C:
+			_, err := l.db.Exec(`UPDATE advisory_locks SET expires_at = $1 WHERE lock_key = $2 AND holder = $3`,
+				time.Now().UTC().Add(sqliteLockLease), l.key, l.holder)
+			if err != nil {
+				slog.Error("Failed to renew advisory lock", "key", l.key, "error", err)
+			}
+		}
+	}
+}
+
+// Release unlocks and returns the connection to the pool. It runs even if
+// the caller's context was cancelled, since a shutdown is the usual reason
+// to release mid-import.
+func (l *AdvisoryLock) Release() error {
+	if l.db != nil {
+		close(l.stop)
+		<-l.done
+		if _, err := l.db.Exec(`DELETE FROM advisory_locks WHERE lock_key = $1 AND holder = $2`, l.key, l.holder); err != nil {
+			return fmt.Errorf("failed to release advisory lock %d: %w", l.key, err)
+		}
+		return nil
+	}
+
+	defer l.conn.Close()
+
+	if _, err := l.conn.ExecContext(context.Background(), `SELECT pg_advisory_unlock($1)`, l.key); err != nil {
+		return fmt.Errorf("failed to release advisory lock %d: %w", l.key, err)
+	}
+	return nil
+}
@@ -0,0 +1,348 @@
+package store
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/jjenkins/usds/internal/model"
+)
+
+// memoryAgencies is the AgencyRepository view of a MemoryStore
+type memoryAgencies struct {
+	m    *MemoryStore
+	asOf time.Time // Zero for the current state
+}
+
+func (r *memoryAgencies) AsOf(date time.Time) AgencyRepository {
+	if date.IsZero() {
+		return r
+	}
+	return &memoryAgencies{m: r.m, asOf: date}
+}
+
+func (r *memoryAgencies) GetBySlug(ctx context.Context, slug string) (*model.Agency, error) {
+	r.m.mu.Lock()
+	defer r.m.mu.Unlock()
+
+	for _, a := range r.m.agenciesView(r.asOf) {
+		if a.Slug == slug {
+			return &a, nil
+		}
+	}
+	return nil, nil
+}
+
+func (r *memoryAgencies) GetByID(ctx context.Context, id int) (*model.Agency, error) {
+	r.m.mu.Lock()
+	defer r.m.mu.Unlock()
+
+	a, ok := r.m.agenciesView(r.asOf)[id]
+	if !ok {
+		return nil, nil
+	}
+	return &a, nil
+}
+
+func (r *memoryAgencies) GetAll(ctx context.Context) ([]model.Agency, error) {
+	r.m.mu.Lock()
+	defer r.m.mu.Unlock()
+
+	return sortedAgencies(r.m.agenciesView(r.asOf), nil), nil
+}
+
+func (r *memoryAgencies) GetAllHierarchical(ctx context.Context) ([]AgencyWithDepth, error) {
+	r.m.mu.Lock()
+	defer r.m.mu.Unlock()
+
+	agencies := sortedAgencies(r.m.agenciesView(r.asOf), nil)
+	return buildAgencyHierarchy(agencies, r.titleCounts()), nil
+}
+
+func (r *memoryAgencies) GetAllSorted(ctx context.Context, sortBy, order string) ([]AgencyWithDepth, error) {
+	if sortBy == "name" && order == "asc" {
+		return r.GetAllHierarchical(ctx)
+	}
+
+	r.m.mu.Lock()
+	defer r.m.mu.Unlock()
+
+	counts := r.titleCounts()
+	var result []AgencyWithDepth
+	for _, a := range sortedAgencies(r.m.agenciesView(r.asOf), nil) {
+		result = append(result, AgencyWithDepth{Agency: a, TitleCount: counts[a.ID]})
+	}
+
+	desc := order == "desc"
+	sort.SliceStable(result, func(i, j int) bool {
+		a, b := result[i], result[j]
+		if desc {
+			a, b = b, a
+		}
+		switch sortBy {
+		case "title_count":
+			// Ties stay in name order either way
+			if a.TitleCount == b.TitleCount {
+				return false
+			}
+			return a.TitleCount < b.TitleCount
+		case "name":
+			return a.AgencyName < b.AgencyName
+		default:
+			return a.TotalWordCount < b.TotalWordCount
+		}
+	})
+
+	calculateDensityScores(result)
+	return result, nil
+}
+
+// titleCounts counts each agency's linked titles; callers hold the lock
+func (r *memoryAgencies) titleCounts() map[int]int {
+	counts := make(map[int]int)
+	for id, titles := range r.m.agencyTitlesView(r.asOf) {
+		counts[id] = len(titles)
+	}
+	return counts
+}
+
+func (r *memoryAgencies) GetChildren(ctx context.Context, parentID int) ([]model.Agency, error) {
+	r.m.mu.Lock()
+	defer r.m.mu.Unlock()
+
+	return sortedAgencies(r.m.agenciesView(r.asOf), func(a model.Agency) bool {
+		return a.ParentID.Valid && int(a.ParentID.Int64) == parentID
+	}), nil
+}
+
+func (r *memoryAgencies) GetTitlesForAgency(ctx context.Context, agencyID int) ([]model.Title, error) {
+	r.m.mu.Lock()
+	defer r.m.mu.Unlock()
+
+	links := r.m.agencyTitlesView(r.asOf)[agencyID]
+	var titles []model.Title
+	for _, t := range sortedTitles(r.m.titlesView(r.asOf)) {
+		if links[t.TitleNumber] {
+			titles = append(titles, t)
+		}
+	}
+	return titles, nil
+}
+
+func (r *memoryAgencies) GetSnapshotsForAgency(ctx context.Context, agencyID int) ([]model.AgencySnapshot, error) {
+	r.m.mu.Lock()
+	defer r.m.mu.Unlock()
+
+	var snapshots []model.AgencySnapshot
+	for _, snap := range r.m.agencySnapshots {
+		if snap.AgencyID == agencyID && onOrBefore(snap.SnapshotDate, r.asOf) {
+			snapshots = append(snapshots, *snap)
+		}
+	}
+	sort.Slice(snapshots, func(i, j int) bool {
+		return snapshots[i].SnapshotDate.After(snapshots[j].SnapshotDate)
+	})
+	return snapshots, nil
+}
+
+func (r *memoryAgencies) GetDensityScoreForAgency(ctx context.Context, agency *model.Agency) (float64, error) {
+	if agency.RegulationCount == 0 {
+		return 0, nil
+	}
+
+	r.m.mu.Lock()
+	defer r.m.mu.Unlock()
+
+	var densities []float64
+	for _, a := range r.m.agenciesView(r.asOf) {
+		if a.RegulationCount > 0 {
+			densities = append(densities, float64(a.TotalWordCount)/float64(a.RegulationCount))
+		}
+	}
+	return percentileBelow(float64(agency.TotalWordCount)/float64(agency.RegulationCount), densities), nil
+}
+
+func (r *memoryAgencies) GetChaptersForAgency(ctx context.Context, agencyID int) ([]model.CFRReference, error) {
+	r.m.mu.Lock()
+	defer r.m.mu.Unlock()
+
+	var chapters []model.CFRReference
+	for ref := range r.m.agencyChapters[agencyID] {
+		chapters = append(chapters, ref)
+	}
+	sort.Slice(chapters, func(i, j int) bool {
+		if chapters[i].Title != chapters[j].Title {
+			return chapters[i].Title < chapters[j].Title
+		}
+		return chapters[i].Chapter < chapters[j].Chapter
+	})
+	return chapters, nil
+}
+
+func (r *memoryAgencies) GetAgencyTitles(ctx context.Context, agencyID int) ([]int, error) {
+	r.m.mu.Lock()
+	defer r.m.mu.Unlock()
+
+	var titles []int
+	for n := range r.m.agencyTitlesView(r.asOf)[agencyID] {
+		titles = append(titles, n)
+	}
+	sort.Ints(titles)
+	return titles, nil
+}
+
+func (r *memoryAgencies) GetTitleWordCount(ctx context.Context, titleNumber int) (int, error) {
+	r.m.mu.Lock()
+	defer r.m.mu.Unlock()
+
+	return r.m.titlesView(r.asOf)[titleNumber].WordCount, nil
+}
+
+func (r *memoryAgencies) CountAgencies(ctx context.Context) (int, error) {
+	r.m.mu.Lock()
+	defer r.m.mu.Unlock()
+
+	return len(r.m.agenciesView(r.asOf)), nil
+}
+
+func (r *memoryAgencies) GetAgencySnapshotDates(ctx context.Context) ([]time.Time, error) {
+	r.m.mu.Lock()
+	defer r.m.mu.Unlock()
+
+	dates := make(map[time.Time]bool)
+	for _, snap := range r.m.agencySnapshots {
+		if onOrBefore(snap.SnapshotDate, r.asOf) {
+			dates[snap.SnapshotDate] = true
+		}
+	}
+	return sortedDates(dates), nil
+}
+
+func (r *memoryAgencies) UpsertAgency(ctx context.Context, a *model.Agency) error {
+	r.m.mu.Lock()
+	defer r.m.mu.Unlock()
+
+	var stored *model.Agency
+	for _, existing := range r.m.agencies {
+		if existing.Slug == a.Slug {
+			stored = existing
+		}
+	}
+	for _, existing := range r.m.agencies {
+		if existing != stored && existing.AgencyName == a.AgencyName {
+			return fmt.Errorf("failed to upsert agency %s: name %q is taken by %s", a.Slug, a.AgencyName, existing.Slug)
+		}
+	}
+
+	if stored == nil {
+		r.m.nextAgencyID++
+		stored = &model.Agency{ID: r.m.nextAgencyID}
+		r.m.agencies[stored.ID] = stored
+	}
+	id := stored.ID
+	*stored = *a
+	stored.ID = id
+	stored.UpdatedAt = time.Now()
+	a.ID = id
+	return nil
+}
+
+func (r *memoryAgencies) LinkAgencyTitle(ctx context.Context, agencyID, titleNumber int) error {
+	r.m.mu.Lock()
+	defer r.m.mu.Unlock()
+
+	if _, ok := r.m.agencies[agencyID]; !ok {
+		return fmt.Errorf("failed to link agency %d to title %d: %w", agencyID, titleNumber, errNoAgency(agencyID))
+	}
+	if _, ok := r.m.titles[titleNumber]; !ok {
+		return fmt.Errorf("failed to link agency %d to title %d: title does not exist", agencyID, titleNumber)
+	}
+	if r.m.agencyTitles[agencyID] == nil {
+		r.m.agencyTitles[agencyID] = make(map[int]bool)
+	}
+	r.m.agencyTitles[agencyID][titleNumber] = true
+	return nil
+}
+
+func (r *memoryAgencies) LinkAgencyChapter(ctx context.Context, agencyID, titleNumber int, chapter string) error {
+	r.m.mu.Lock()
+	defer r.m.mu.Unlock()
+
+	if _, ok := r.m.agencies[agencyID]; !ok {
+		return fmt.Errorf("failed to link agency %d to title %d chapter %s: %w", agencyID, titleNumber, chapter, errNoAgency(agencyID))
+	}
+	if r.m.agencyChapters[agencyID] == nil {
+		r.m.agencyChapters[agencyID] = make(map[model.CFRReference]bool)
+	}
+	r.m.agencyChapters[agencyID][model.CFRReference{Title: titleNumber, Chapter: chapter}] = true
+	return nil
+}
+
+func (r *memoryAgencies) ClearAgencyTitles(ctx context.Context) error {
+	r.m.mu.Lock()
+	defer r.m.mu.Unlock()
+
+	r.m.agencyTitles = make(map[int]map[int]bool)
+	r.m.agencyChapters = make(map[int]map[model.CFRReference]bool)
+	return nil
+}
+
+func (r *memoryAgencies) UpdateWordCount(ctx context.Context, agencyID, wordCount, regulationCount int, checksum string) error {
+	r.m.mu.Lock()
+	defer r.m.mu.Unlock()
+
+	if a, ok := r.m.agencies[agencyID]; ok {
+		a.TotalWordCount = wordCount
+		a.RegulationCount = regulationCount
+		a.Checksum = checksum
+		a.UpdatedAt = time.Now()
+	}
+	return nil
+}
+
+func (r *memoryAgencies) InsertSnapshotIfChanged(ctx context.Context, snap *model.AgencySnapshot, titleNumbers []int) (bool, error) {
+	r.m.mu.Lock()
+	defer r.m.mu.Unlock()
+
+	date := dateOnly(snap.SnapshotDate)
+	var existing *model.AgencySnapshot
+	for _, s := range r.m.agencySnapshots {
+		if s.AgencyID == snap.AgencyID && s.SnapshotDate.Equal(date) {
+			existing = s
+		}
+	}
+	if existing != nil && existing.Checksum == snap.Checksum {
+		return false, nil
+	}
+	if _, ok := r.m.agencies[snap.AgencyID]; !ok {
+		return false, fmt.Errorf("failed to insert snapshot for agency %d: %w", snap.AgencyID, errNoAgency(snap.AgencyID))
+	}
+
+	if existing == nil {
+		r.m.nextAgencySnap++
+		existing = &model.AgencySnapshot{ID: r.m.nextAgencySnap, CreatedAt: time.Now()}
+		r.m.agencySnapshots = append(r.m.agencySnapshots, existing)
+	}
+	*existing = model.AgencySnapshot{
+		ID:              existing.ID,
+		AgencyID:        snap.AgencyID,
+		AgencyName:      snap.AgencyName,
+		TotalWordCount:  snap.TotalWordCount,
+		RegulationCount: snap.RegulationCount,
+		Checksum:        snap.Checksum,
+		SnapshotDate:    date,
+		CreatedAt:       existing.CreatedAt,
+	}
+	snap.ID = existing.ID
+
+	// Links accumulate when a date is snapshotted again, as ON CONFLICT DO
+	// NOTHING leaves earlier rows in place
+	if r.m.snapshotTitles[snap.ID] == nil {
+		r.m.snapshotTitles[snap.ID] = make(map[int]bool)
+	}
+	for _, n := range titleNumbers {
+		r.m.snapshotTitles[snap.ID][n] = true
+	}
+	return true, nil
+}
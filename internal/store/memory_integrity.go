@@ -0,0 +1,90 @@
+package store
+
+import (
+	"context"
+	"sort"
+	"time"
+)
+
+// memoryIntegrity is the IntegrityRepository view of a MemoryStore
+type memoryIntegrity struct {
+	m *MemoryStore
+}
+
+// SnapshotContent always returns nil: a MemoryStore keeps no title XML
+func (r *memoryIntegrity) SnapshotContent(ctx context.Context, titleNumber int, snapshotDate time.Time) ([]byte, error) {
+	return nil, nil
+}
+
+func (r *memoryIntegrity) CountSections(ctx context.Context, titleNumber int, snapshotDate time.Time) (int, error) {
+	r.m.mu.Lock()
+	defer r.m.mu.Unlock()
+
+	return len(r.m.sections[sectionKey{titleNumber: titleNumber, snapshotDate: dateOnly(snapshotDate)}]), nil
+}
+
+func (r *memoryIntegrity) OrphanAgencyTitles(ctx context.Context) ([]AgencyTitleLink, error) {
+	r.m.mu.Lock()
+	defer r.m.mu.Unlock()
+
+	var links []AgencyTitleLink
+	for agencyID, titleNumbers := range r.m.agencyTitles {
+		for n := range titleNumbers {
+			if r.m.agencies[agencyID] == nil || r.m.titles[n] == nil {
+				links = append(links, AgencyTitleLink{AgencyID: agencyID, TitleNumber: n})
+			}
+		}
+	}
+	sort.Slice(links, func(i, j int) bool {
+		if links[i].AgencyID != links[j].AgencyID {
+			return links[i].AgencyID < links[j].AgencyID
+		}
+		return links[i].TitleNumber < links[j].TitleNumber
+	})
+	return links, nil
+}
+
+func (r *memoryIntegrity) OrphanSnapshots(ctx context.Context) ([]OrphanSnapshot, error) {
+	r.m.mu.Lock()
+	defer r.m.mu.Unlock()
+
+	counts := make(map[OrphanSnapshot]int)
+	snapshotted := make(map[sectionKey]bool)
+	for _, snap := range r.m.titleSnapshots {
+		snapshotted[sectionKey{titleNumber: snap.TitleNumber, snapshotDate: snap.SnapshotDate}] = true
+		if r.m.titles[snap.TitleNumber] == nil {
+			counts[OrphanSnapshot{Table: "title_snapshots", TitleNumber: snap.TitleNumber, SnapshotDate: snap.SnapshotDate}]++
+		}
+	}
+	for _, snap := range r.m.agencySnapshots {
+		for n := range r.m.snapshotTitles[snap.ID] {
+			if r.m.titles[n] == nil {
+				counts[OrphanSnapshot{Table: "agency_snapshot_titles", TitleNumber: n, SnapshotDate: snap.SnapshotDate}]++
+			}
+		}
+	}
+	for key, sections := range r.m.sections {
+		if !snapshotted[key] && len(sections) > 0 {
+			counts[OrphanSnapshot{Table: "sections", TitleNumber: key.titleNumber, SnapshotDate: key.snapshotDate}] += len(sections)
+		}
+	}
+
+	// Tables in the order IntegrityStore queries them
+	order := map[string]int{"title_snapshots": 0, "agency_snapshot_titles": 1, "sections": 2}
+	var orphans []OrphanSnapshot
+	for o, rows := range counts {
+		o.Rows = rows
+		orphans = append(orphans, o)
+	}
+	sort.Slice(orphans, func(i, j int) bool {
+		a, b := orphans[i], orphans[j]
+		if a.Table != b.Table {
+			return order[a.Table] < order[b.Table]
+		}
+		if a.TitleNumber != b.TitleNumber {
+			return a.TitleNumber < b.TitleNumber
+		}
+		return a.SnapshotDate.Before(b.SnapshotDate)
+	})
+	return orphans, nil
+}
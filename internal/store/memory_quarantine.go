@@ -0,0 +1,90 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/jjenkins/usds/internal/model"
+)
+
+// memoryQuarantine is the QuarantineRepository view of a MemoryStore
+type memoryQuarantine struct {
+	m *MemoryStore
+}
+
+func (r *memoryQuarantine) Quarantine(ctx context.Context, q *model.QuarantinedVersion) error {
+	r.m.mu.Lock()
+	defer r.m.mu.Unlock()
+
+	date := dateOnly(q.VersionDate)
+	var stored *model.QuarantinedVersion
+	for _, existing := range r.m.quarantined {
+		if existing.TitleNumber == q.TitleNumber && existing.VersionDate.Equal(date) && existing.Checksum == q.Checksum {
+			stored = existing
+		}
+	}
+	if stored == nil {
+		r.m.nextQuarantineID++
+		stored = &model.QuarantinedVersion{
+			ID:          r.m.nextQuarantineID,
+			TitleNumber: q.TitleNumber,
+			VersionDate: date,
+			Checksum:    q.Checksum,
+			Status:      model.QuarantinePending,
+			CreatedAt:   time.Now(),
+		}
+		r.m.quarantined = append(r.m.quarantined, stored)
+	}
+	stored.SizeBytes = q.SizeBytes
+	stored.WordCount = q.WordCount
+	stored.PreviousWordCount = q.PreviousWordCount
+	stored.Reason = q.Reason
+
+	q.ID, q.Status, q.CreatedAt = stored.ID, stored.Status, stored.CreatedAt
+	return nil
+}
+
+func (r *memoryQuarantine) IsAccepted(ctx context.Context, titleNumber int, checksum string) (bool, error) {
+	r.m.mu.Lock()
+	defer r.m.mu.Unlock()
+
+	for _, q := range r.m.quarantined {
+		if q.TitleNumber == titleNumber && q.Checksum == checksum && q.Status == model.QuarantineAccepted {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+func (r *memoryQuarantine) List(ctx context.Context, status string) ([]model.QuarantinedVersion, error) {
+	r.m.mu.Lock()
+	defer r.m.mu.Unlock()
+
+	var versions []model.QuarantinedVersion
+	for _, q := range r.m.quarantined {
+		if status == "" || q.Status == status {
+			versions = append(versions, *q)
+		}
+	}
+	sort.Slice(versions, func(i, j int) bool {
+		return versions[i].ID > versions[j].ID
+	})
+	return versions, nil
+}
+
+func (r *memoryQuarantine) Review(ctx context.Context, id int, status string) error {
+	r.m.mu.Lock()
+	defer r.m.mu.Unlock()
+
+	for _, q := range r.m.quarantined {
+		if q.ID == id {
+			q.Status = status
+			q.ReviewedAt = sql.NullTime{Time: time.Now(), Valid: true}
+			return nil
+		}
+	}
+	return fmt.Errorf("quarantined version %d not found", id)
+}
@@ -0,0 +1,51 @@
+package store
+
+import (
+	"context"
+	"time"
+
+	"github.com/jjenkins/usds/internal/model"
+)
+
+// sectionKey identifies the sections of one title snapshot
+type sectionKey struct {
+	titleNumber  int
+	snapshotDate time.Time
+}
+
+// memorySections is the SectionRepository view of a MemoryStore
+type memorySections struct {
+	m *MemoryStore
+}
+
+func (r *memorySections) ReplaceSections(ctx context.Context, titleNumber int, snapshotDate time.Time, sections []model.Section) error {
+	r.m.mu.Lock()
+	defer r.m.mu.Unlock()
+
+	key := sectionKey{titleNumber: titleNumber, snapshotDate: dateOnly(snapshotDate)}
+	stored := make([]model.Section, len(sections))
+	for i, sec := range sections {
+		r.m.nextSectionID++
+		sec.ID = r.m.nextSectionID
+		sec.TitleNumber = key.titleNumber
+		sec.SnapshotDate = key.snapshotDate
+		stored[i] = sec
+	}
+	r.m.sections[key] = stored
+	return nil
+}
+
+func (r *memorySections) GetSections(ctx context.Context, titleNumber int, snapshotDate time.Time) ([]model.Section, error) {
+	r.m.mu.Lock()
+	defer r.m.mu.Unlock()
+
+	stored := r.m.sections[sectionKey{titleNumber: titleNumber, snapshotDate: dateOnly(snapshotDate)}]
+	return append([]model.Section(nil), stored...), nil
+}
+
+func (r *memorySections) HasSections(ctx context.Context, titleNumber int, snapshotDate time.Time) (bool, error) {
+	r.m.mu.Lock()
+	defer r.m.mu.Unlock()
+
+	return len(r.m.sections[sectionKey{titleNumber: titleNumber, snapshotDate: dateOnly(snapshotDate)}]) > 0, nil
+}
@@ -0,0 +1,246 @@
+package store
+
+import (
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/jjenkins/usds/internal/model"
+)
+
+// MemoryStore keeps titles, agencies, section text and quarantined versions
+// in memory with the same semantics as the Postgres stores, for tests that
+// should not need a database. It does not search sections or record change
+// events or webhook deliveries, which live in other stores.
+type MemoryStore struct {
+	mu sync.Mutex
+
+	titles         map[int]*model.Title // By title number
+	titleSnapshots []*model.TitleSnapshot
+	nextTitleID    int
+	nextTitleSnap  int
+
+	agencies        map[int]*model.Agency // By ID
+	agencyTitles    map[int]map[int]bool  // Agency ID to title numbers
+	agencyChapters  map[int]map[model.CFRReference]bool
+	agencySnapshots []*model.AgencySnapshot
+	snapshotTitles  map[int]map[int]bool // Agency snapshot ID to title numbers
+	nextAgencyID    int
+	nextAgencySnap  int
+
+	sections      map[sectionKey][]model.Section
+	nextSectionID int
+
+	quarantined      []*model.QuarantinedVersion
+	nextQuarantineID int
+}
+
+// NewMemoryStore creates an empty MemoryStore
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{
+		titles:         make(map[int]*model.Title),
+		agencies:       make(map[int]*model.Agency),
+		agencyTitles:   make(map[int]map[int]bool),
+		agencyChapters: make(map[int]map[model.CFRReference]bool),
+		snapshotTitles: make(map[int]map[int]bool),
+		sections:       make(map[sectionKey][]model.Section),
+	}
+}
+
+// Titles returns a TitleRepository over the store's titles
+func (m *MemoryStore) Titles() TitleRepository {
+	return &memoryTitles{m: m}
+}
+
+// Agencies returns an AgencyRepository over the store's agencies
+func (m *MemoryStore) Agencies() AgencyRepository {
+	return &memoryAgencies{m: m}
+}
+
+// Sections returns a SectionRepository over the store's section text
+func (m *MemoryStore) Sections() SectionRepository {
+	return &memorySections{m: m}
+}
+
+// Quarantine returns a QuarantineRepository over the store's quarantined
+// versions
+func (m *MemoryStore) Quarantine() QuarantineRepository {
+	return &memoryQuarantine{m: m}
+}
+
+// Integrity returns an IntegrityRepository over the whole store
+func (m *MemoryStore) Integrity() IntegrityRepository {
+	return &memoryIntegrity{m: m}
+}
+
+// dateOnly truncates t to its date, as a Postgres DATE column does
+func dateOnly(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+}
+
+// onOrBefore reports whether date falls within an as-of view
+func onOrBefore(date, asOf time.Time) bool {
+	return asOf.IsZero() || !date.After(dateOnly(asOf))
+}
+
+// titlesView returns the titles as they stood at asOf, by title number, as
+// titlesSource does; callers hold the lock
+func (m *MemoryStore) titlesView(asOf time.Time) map[int]model.Title {
+	view := make(map[int]model.Title)
+	if asOf.IsZero() {
+		for n, t := range m.titles {
+			view[n] = *t
+		}
+		return view
+	}
+
+	for _, snap := range m.latestTitleSnapshots(asOf) {
+		view[snap.TitleNumber] = model.Title{
+			ID:              snap.ID,
+			TitleNumber:     snap.TitleNumber,
+			TitleName:       snap.TitleName,
+			WordCount:       snap.WordCount,
+			SectionCount:    snap.SectionCount,
+			Readability:     snap.Readability,
+			Checksum:        snap.Checksum,
+			LastAmendedDate: snap.LastAmendedDate,
+			FetchedAt:       snap.SnapshotDate,
+			CreatedAt:       snap.CreatedAt,
+		}
+	}
+	return view
+}
+
+// latestTitleSnapshots returns each title's latest snapshot on or before
+// date; callers hold the lock
+func (m *MemoryStore) latestTitleSnapshots(date time.Time) map[int]*model.TitleSnapshot {
+	latest := make(map[int]*model.TitleSnapshot)
+	for _, snap := range m.titleSnapshots {
+		if !onOrBefore(snap.SnapshotDate, date) {
+			continue
+		}
+		if prev, ok := latest[snap.TitleNumber]; !ok || snap.SnapshotDate.After(prev.SnapshotDate) {
+			latest[snap.TitleNumber] = snap
+		}
+	}
+	return latest
+}
+
+// latestAgencySnapshots returns each agency's latest snapshot on or before
+// asOf; callers hold the lock
+func (m *MemoryStore) latestAgencySnapshots(asOf time.Time) map[int]*model.AgencySnapshot {
+	latest := make(map[int]*model.AgencySnapshot)
+	for _, snap := range m.agencySnapshots {
+		if !onOrBefore(snap.SnapshotDate, asOf) {
+			continue
+		}
+		if prev, ok := latest[snap.AgencyID]; !ok || snap.SnapshotDate.After(prev.SnapshotDate) {
+			latest[snap.AgencyID] = snap
+		}
+	}
+	return latest
+}
+
+// agenciesView returns the agencies as they stood at asOf, by ID, as
+// agenciesSource does; callers hold the lock
+func (m *MemoryStore) agenciesView(asOf time.Time) map[int]model.Agency {
+	view := make(map[int]model.Agency)
+	if asOf.IsZero() {
+		for id, a := range m.agencies {
+			view[id] = *a
+		}
+		return view
+	}
+
+	for id, snap := range m.latestAgencySnapshots(asOf) {
+		a, ok := m.agencies[id]
+		if !ok {
+			continue
+		}
+		view[id] = model.Agency{
+			ID:              a.ID,
+			AgencyName:      snap.AgencyName,
+			ShortName:       a.ShortName,
+			Slug:            a.Slug,
+			ParentID:        a.ParentID,
+			TotalWordCount:  snap.TotalWordCount,
+			RegulationCount: snap.RegulationCount,
+			Checksum:        snap.Checksum,
+			UpdatedAt:       snap.SnapshotDate,
+		}
+	}
+	return view
+}
+
+// agencyTitlesView returns the agency-title links in effect at asOf, as
+// agencyTitlesSource does; callers hold the lock
+func (m *MemoryStore) agencyTitlesView(asOf time.Time) map[int]map[int]bool {
+	if asOf.IsZero() {
+		return m.agencyTitles
+	}
+
+	view := make(map[int]map[int]bool)
+	for id, snap := range m.latestAgencySnapshots(asOf) {
+		view[id] = m.snapshotTitles[snap.ID]
+	}
+	return view
+}
+
+// sortedTitles returns a view's titles ordered by title number
+func sortedTitles(view map[int]model.Title) []model.Title {
+	titles := make([]model.Title, 0, len(view))
+	for _, t := range view {
+		titles = append(titles, t)
+	}
+	sort.Slice(titles, func(i, j int) bool {
+		return titles[i].TitleNumber < titles[j].TitleNumber
+	})
+	return titles
+}
+
+// sortedAgencies returns agencies ordered by name
+func sortedAgencies(view map[int]model.Agency, keep func(model.Agency) bool) []model.Agency {
+	var agencies []model.Agency
+	for _, a := range view {
+		if keep == nil || keep(a) {
+			agencies = append(agencies, a)
+		}
+	}
+	sort.Slice(agencies, func(i, j int) bool {
+		return agencies[i].AgencyName < agencies[j].AgencyName
+	})
+	return agencies
+}
+
+// sortedDates returns the distinct dates, newest first
+func sortedDates(dates map[time.Time]bool) []time.Time {
+	var list []time.Time
+	for d := range dates {
+		list = append(list, d)
+	}
+	sort.Slice(list, func(i, j int) bool {
+		return list[i].After(list[j])
+	})
+	return list
+}
+
+// percentileBelow ranks density among densities as the Postgres density
+// score queries do
+func percentileBelow(density float64, densities []float64) float64 {
+	if len(densities) <= 1 {
+		return 0.5
+	}
+	lower := 0
+	for _, d := range densities {
+		if d < density {
+			lower++
+		}
+	}
+	return float64(lower) / float64(len(densities)-1)
+}
+
+// errNoAgency matches the foreign key violation Postgres reports
+func errNoAgency(agencyID int) error {
+	return fmt.Errorf("agency %d does not exist", agencyID)
+}
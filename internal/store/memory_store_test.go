@@ -0,0 +1,15 @@
+package store_test
+
+import (
+	"testing"
+
+	"github.com/jjenkins/usds/internal/store"
+	"github.com/jjenkins/usds/internal/store/storetest"
+)
+
+func TestMemoryStore(t *testing.T) {
+	storetest.Run(t, func(t *testing.T) (store.TitleRepository, store.AgencyRepository) {
+		m := store.NewMemoryStore()
+		return m.Titles(), m.Agencies()
+	})
+}
@@ -0,0 +1,272 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+	"sort"
+	"time"
+
+	"github.com/jjenkins/usds/internal/model"
+)
+
+// memoryTitles is the TitleRepository view of a MemoryStore
+type memoryTitles struct {
+	m    *MemoryStore
+	asOf time.Time // Zero for the current state
+}
+
+func (r *memoryTitles) AsOf(date time.Time) TitleRepository {
+	if date.IsZero() {
+		return r
+	}
+	return &memoryTitles{m: r.m, asOf: date}
+}
+
+func (r *memoryTitles) GetByNumber(ctx context.Context, titleNumber int) (*model.Title, error) {
+	r.m.mu.Lock()
+	defer r.m.mu.Unlock()
+
+	t, ok := r.m.titlesView(r.asOf)[titleNumber]
+	if !ok {
+		return nil, nil
+	}
+	return &t, nil
+}
+
+func (r *memoryTitles) GetAll(ctx context.Context) ([]model.Title, error) {
+	r.m.mu.Lock()
+	defer r.m.mu.Unlock()
+
+	titles := sortedTitles(r.m.titlesView(r.asOf))
+	for i := range titles {
+		titles[i].Readability = sql.NullFloat64{} // Not selected for listings
+	}
+	return titles, nil
+}
+
+func (r *memoryTitles) GetAllSortedWithDensity(ctx context.Context, sortBy, order string) ([]TitleWithDensity, error) {
+	titles, err := r.GetAll(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	less := map[string]func(a, b model.Title) bool{
+		"name":          func(a, b model.Title) bool { return a.TitleName < b.TitleName },
+		"word_count":    func(a, b model.Title) bool { return a.WordCount < b.WordCount },
+		"section_count": func(a, b model.Title) bool { return a.SectionCount < b.SectionCount },
+		"last_amended": func(a, b model.Title) bool {
+			return a.LastAmendedDate.Time.Before(b.LastAmendedDate.Time)
+		},
+	}[sortBy]
+	if less == nil {
+		less = func(a, b model.Title) bool { return a.TitleNumber < b.TitleNumber }
+	}
+	sort.SliceStable(titles, func(i, j int) bool {
+		// Titles never amended sort last in either order, as NULLS LAST does
+		if sortBy == "last_amended" && titles[i].LastAmendedDate.Valid != titles[j].LastAmendedDate.Valid {
+			return titles[i].LastAmendedDate.Valid
+		}
+		if order == "desc" {
+			return less(titles[j], titles[i])
+		}
+		return less(titles[i], titles[j])
+	})
+
+	result := make([]TitleWithDensity, len(titles))
+	for i, t := range titles {
+		result[i] = TitleWithDensity{Title: t}
+	}
+	calculateTitleDensityScores(result)
+	return result, nil
+}
+
+func (r *memoryTitles) GetSnapshots(ctx context.Context, titleNumber int) ([]model.TitleSnapshot, error) {
+	r.m.mu.Lock()
+	defer r.m.mu.Unlock()
+
+	var snapshots []model.TitleSnapshot
+	for _, snap := range r.m.titleSnapshots {
+		if snap.TitleNumber == titleNumber && onOrBefore(snap.SnapshotDate, r.asOf) {
+			snapshots = append(snapshots, *snap)
+		}
+	}
+	sort.Slice(snapshots, func(i, j int) bool {
+		return snapshots[i].SnapshotDate.After(snapshots[j].SnapshotDate)
+	})
+	return snapshots, nil
+}
+
+func (r *memoryTitles) GetAgenciesForTitle(ctx context.Context, titleNumber int) ([]model.Agency, error) {
+	r.m.mu.Lock()
+	defer r.m.mu.Unlock()
+
+	links := r.m.agencyTitlesView(r.asOf)
+	return sortedAgencies(r.m.agenciesView(r.asOf), func(a model.Agency) bool {
+		return links[a.ID][titleNumber]
+	}), nil
+}
+
+func (r *memoryTitles) GetDensityScoreForTitle(ctx context.Context, title *model.Title) (float64, error) {
+	if title.SectionCount == 0 {
+		return 0, nil
+	}
+
+	r.m.mu.Lock()
+	defer r.m.mu.Unlock()
+
+	var densities []float64
+	for _, t := range r.m.titlesView(r.asOf) {
+		if t.SectionCount > 0 {
+			densities = append(densities, float64(t.WordCount)/float64(t.SectionCount))
+		}
+	}
+	return percentileBelow(float64(title.WordCount)/float64(title.SectionCount), densities), nil
+}
+
+func (r *memoryTitles) CountTitles(ctx context.Context) (int, error) {
+	r.m.mu.Lock()
+	defer r.m.mu.Unlock()
+
+	return len(r.m.titlesView(r.asOf)), nil
+}
+
+func (r *memoryTitles) GetTotalWordCount(ctx context.Context) (int, error) {
+	r.m.mu.Lock()
+	defer r.m.mu.Unlock()
+
+	total := 0
+	for _, t := range r.m.titlesView(r.asOf) {
+		total += t.WordCount
+	}
+	return total, nil
+}
+
+func (r *memoryTitles) GetAverageDensity(ctx context.Context) (float64, error) {
+	r.m.mu.Lock()
+	defer r.m.mu.Unlock()
+
+	view := r.m.titlesView(r.asOf)
+	if len(view) == 0 {
+		return 0, nil
+	}
+	sum := 0.0
+	for _, t := range view {
+		if t.SectionCount > 0 {
+			sum += float64(t.WordCount) / float64(t.SectionCount)
+		}
+	}
+	return sum / float64(len(view)), nil
+}
+
+func (r *memoryTitles) GetSnapshotDates(ctx context.Context) ([]time.Time, error) {
+	r.m.mu.Lock()
+	defer r.m.mu.Unlock()
+
+	dates := make(map[time.Time]bool)
+	for _, snap := range r.m.titleSnapshots {
+		if onOrBefore(snap.SnapshotDate, r.asOf) {
+			dates[snap.SnapshotDate] = true
+		}
+	}
+	return sortedDates(dates), nil
+}
+
+func (r *memoryTitles) GetSnapshotTotals(ctx context.Context) ([]SnapshotTotals, error) {
+	dates, err := r.GetSnapshotDates(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	r.m.mu.Lock()
+	defer r.m.mu.Unlock()
+
+	var totals []SnapshotTotals
+	for i := len(dates) - 1; i >= 0; i-- {
+		t := SnapshotTotals{Date: dates[i]}
+		for _, snap := range r.m.latestTitleSnapshots(dates[i]) {
+			t.TitleCount++
+			t.WordCount += snap.WordCount
+			t.SectionCount += snap.SectionCount
+		}
+		totals = append(totals, t)
+	}
+	return totals, nil
+}
+
+func (r *memoryTitles) SaveTitleWithSnapshot(ctx context.Context, t *model.Title, snapshotDate time.Time) (bool, error) {
+	r.m.mu.Lock()
+	defer r.m.mu.Unlock()
+
+	date := dateOnly(snapshotDate)
+	var existing *model.TitleSnapshot
+	historical := false
+	for _, snap := range r.m.titleSnapshots {
+		if snap.TitleNumber != t.TitleNumber {
+			continue
+		}
+		if snap.SnapshotDate.Equal(date) {
+			existing = snap
+		}
+		if snap.SnapshotDate.After(date) {
+			historical = true
+		}
+	}
+	changed := existing == nil || existing.Checksum != t.Checksum
+	if !changed {
+		existing.WordCount, existing.SectionCount, existing.Readability = t.WordCount, t.SectionCount, t.Readability
+	}
+
+	stored, ok := r.m.titles[t.TitleNumber]
+	if !ok {
+		r.m.nextTitleID++
+		stored = &model.Title{ID: r.m.nextTitleID, CreatedAt: time.Now()}
+		r.m.titles[t.TitleNumber] = stored
+		historical = false
+	}
+	t.ID = stored.ID
+	if !historical {
+		id, createdAt := stored.ID, stored.CreatedAt
+		*stored = *t
+		stored.ID, stored.CreatedAt = id, createdAt
+		stored.LastAmendedDate = nullDateOnly(t.LastAmendedDate)
+	}
+
+	if changed {
+		if existing == nil {
+			r.m.nextTitleSnap++
+			existing = &model.TitleSnapshot{ID: r.m.nextTitleSnap, CreatedAt: time.Now()}
+			r.m.titleSnapshots = append(r.m.titleSnapshots, existing)
+		}
+		*existing = model.TitleSnapshot{
+			ID:              existing.ID,
+			TitleNumber:     t.TitleNumber,
+			TitleName:       t.TitleName,
+			WordCount:       t.WordCount,
+			SectionCount:    t.SectionCount,
+			Readability:     t.Readability,
+			Checksum:        t.Checksum,
+			LastAmendedDate: nullDateOnly(t.LastAmendedDate),
+			SnapshotDate:    date,
+			CreatedAt:       existing.CreatedAt,
+		}
+	}
+	return changed, nil
+}
+
+func (r *memoryTitles) MarkFetched(ctx context.Context, titleNumber int) error {
+	r.m.mu.Lock()
+	defer r.m.mu.Unlock()
+
+	if t, ok := r.m.titles[titleNumber]; ok {
+		t.FetchedAt = time.Now()
+	}
+	return nil
+}
+
+// nullDateOnly truncates a nullable date as a DATE column does
+func nullDateOnly(t sql.NullTime) sql.NullTime {
+	if !t.Valid {
+		return t
+	}
+	return sql.NullTime{Time: dateOnly(t.Time), Valid: true}
+}
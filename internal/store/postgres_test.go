@@ -0,0 +1,41 @@
+package store_test
+
+import (
+	"os"
+	"testing"
+
+	"github.com/jjenkins/usds/internal/store"
+	"github.com/jjenkins/usds/internal/store/storetest"
+)
+
+// TestPostgresStore runs the conformance suite against the database in
+// TEST_DATABASE_URL, which it wipes. It is skipped when that is unset.
+func TestPostgresStore(t *testing.T) {
+	dsn := os.Getenv("TEST_DATABASE_URL")
+	if dsn == "" {
+		t.Skip("TEST_DATABASE_URL is not set")
+	}
+
+	db, err := store.NewDB(dsn)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { db.Close() })
+
+	schema, err := os.ReadFile("../db/schema.sql")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := db.Exec(string(schema)); err != nil {
+		t.Fatalf("failed to apply schema: %v", err)
+	}
+
+	storetest.Run(t, func(t *testing.T) (store.TitleRepository, store.AgencyRepository) {
+		_, err := db.Exec(`TRUNCATE titles, title_snapshots, agencies, agency_titles,
+			agency_chapters, agency_snapshots, agency_snapshot_titles RESTART IDENTITY CASCADE`)
+		if err != nil {
+			t.Fatalf("failed to reset tables: %v", err)
+		}
+		return store.NewTitleStore(db), store.NewAgencyStore(db)
+	})
+}
@@ -0,0 +1,118 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	"github.com/jjenkins/usds/internal/model"
+)
+
+// QuarantineStore handles title versions held back from import for review
+type QuarantineStore struct {
+	db *sql.DB
+}
+
+// NewQuarantineStore creates a new QuarantineStore
+func NewQuarantineStore(db *sql.DB) *QuarantineStore {
+	return &QuarantineStore{db: db}
+}
+
+// Quarantine records a version that failed validation and sets its ID,
+// status and creation time. Quarantining the same content again refreshes
+// the reason but keeps any review.
+func (s *QuarantineStore) Quarantine(ctx context.Context, q *model.QuarantinedVersion) error {
+	query := `
+		INSERT INTO quarantined_versions (title_number, version_date, checksum, size_bytes,
+		                                  word_count, previous_word_count, reason)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)
+		ON CONFLICT (title_number, version_date, checksum) DO UPDATE SET
+			size_bytes = EXCLUDED.size_bytes,
+			word_count = EXCLUDED.word_count,
+			previous_word_count = EXCLUDED.previous_word_count,
+			reason = EXCLUDED.reason
+		RETURNING id, status, created_at
+	`
+
+	err := s.db.QueryRowContext(ctx, query,
+		q.TitleNumber,
+		sqlDate(q.VersionDate),
+		q.Checksum,
+		q.SizeBytes,
+		q.WordCount,
+		q.PreviousWordCount,
+		q.Reason,
+	).Scan(&q.ID, &q.Status, &q.CreatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to quarantine title %d version %s: %w", q.TitleNumber, sqlDate(q.VersionDate), err)
+	}
+
+	return nil
+}
+
+// IsAccepted reports whether a reviewer accepted the title content with this
+// checksum, on any version date
+func (s *QuarantineStore) IsAccepted(ctx context.Context, titleNumber int, checksum string) (bool, error) {
+	query := `
+		SELECT COUNT(*) FROM quarantined_versions
+		WHERE title_number = $1 AND checksum = $2 AND status = $3
+	`
+
+	var count int
+	err := s.db.QueryRowContext(ctx, query, titleNumber, checksum, model.QuarantineAccepted).Scan(&count)
+	if err != nil {
+		return false, fmt.Errorf("failed to check quarantine for title %d: %w", titleNumber, err)
+	}
+	return count > 0, nil
+}
+
+// List returns quarantined versions with the given status, or every status
+// when it is empty, newest first
+func (s *QuarantineStore) List(ctx context.Context, status string) ([]model.QuarantinedVersion, error) {
+	query := `
+		SELECT id, title_number, version_date, checksum, size_bytes, word_count, previous_word_count,
+		       reason, status, created_at, reviewed_at
+		FROM quarantined_versions
+		WHERE $1 = '' OR status = $1
+		ORDER BY created_at DESC, id DESC
+	`
+
+	rows, err := s.db.QueryContext(ctx, query, status)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query quarantined versions: %w", err)
+	}
+	defer rows.Close()
+
+	var versions []model.QuarantinedVersion
+	for rows.Next() {
+		var q model.QuarantinedVersion
+		if err := rows.Scan(&q.ID, &q.TitleNumber, &q.VersionDate, &q.Checksum, &q.SizeBytes, &q.WordCount,
+			&q.PreviousWordCount, &q.Reason, &q.Status, &q.CreatedAt, &q.ReviewedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan quarantined version: %w", err)
+		}
+		versions = append(versions, q)
+	}
+
+	return versions, rows.Err()
+}
+
+// Review sets a quarantined version's status
+func (s *QuarantineStore) Review(ctx context.Context, id int, status string) error {
+	result, err := s.db.ExecContext(ctx, `
+		UPDATE quarantined_versions SET status = $2, reviewed_at = CURRENT_TIMESTAMP
+		WHERE id = $1
+	`, id, status)
+	if err != nil {
+		return fmt.Errorf("failed to review quarantined version %d: %w", id, err)
+	}
+
+	n, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to review quarantined version %d: %w", id, err)
+	}
+	if n == 0 {
+		return fmt.Errorf("quarantined version %d not found", id)
+	}
+
+	return nil
+}
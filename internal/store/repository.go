@@ -0,0 +1,116 @@
+package store
+
+import (
+	"context"
+	"time"
+
+	"github.com/jjenkins/usds/internal/model"
+)
+
+// TitleRepository reads and writes titles and their snapshots. TitleStore
+// implements it on Postgres and MemoryStore in memory; both must pass the
+// suite in internal/store/storetest.
+type TitleRepository interface {
+	// AsOf returns a repository whose reads reconstruct titles from the
+	// latest snapshots on or before date; a zero date reads the current state
+	AsOf(date time.Time) TitleRepository
+
+	GetByNumber(ctx context.Context, titleNumber int) (*model.Title, error)
+	GetAll(ctx context.Context) ([]model.Title, error)
+	GetAllSortedWithDensity(ctx context.Context, sortBy, order string) ([]TitleWithDensity, error)
+	GetSnapshots(ctx context.Context, titleNumber int) ([]model.TitleSnapshot, error)
+	GetAgenciesForTitle(ctx context.Context, titleNumber int) ([]model.Agency, error)
+	GetDensityScoreForTitle(ctx context.Context, title *model.Title) (float64, error)
+	CountTitles(ctx context.Context) (int, error)
+	GetTotalWordCount(ctx context.Context) (int, error)
+	GetAverageDensity(ctx context.Context) (float64, error)
+	GetSnapshotDates(ctx context.Context) ([]time.Time, error)
+	GetSnapshotTotals(ctx context.Context) ([]SnapshotTotals, error)
+
+	// SaveTitleWithSnapshot upserts the title and snapshots it on
+	// snapshotDate unless that date already has a snapshot with the same
+	// checksum, reporting whether it did. A snapshot with the same checksum
+	// takes t's counts, so re-imports apply changes to the counting rules.
+	// When the title has a later snapshot, t is a historical version and
+	// the title is left as it is.
+	SaveTitleWithSnapshot(ctx context.Context, t *model.Title, snapshotDate time.Time) (changed bool, err error)
+	MarkFetched(ctx context.Context, titleNumber int) error
+}
+
+// AgencyRepository reads and writes agencies, their title and chapter links
+// and their snapshots. AgencyStore implements it on Postgres and MemoryStore
+// in memory.
+type AgencyRepository interface {
+	// AsOf returns a repository whose reads reconstruct agencies and their
+	// title links from the latest snapshots on or before date
+	AsOf(date time.Time) AgencyRepository
+
+	GetBySlug(ctx context.Context, slug string) (*model.Agency, error)
+	GetByID(ctx context.Context, id int) (*model.Agency, error)
+	GetAll(ctx context.Context) ([]model.Agency, error)
+	GetAllHierarchical(ctx context.Context) ([]AgencyWithDepth, error)
+	GetAllSorted(ctx context.Context, sortBy, order string) ([]AgencyWithDepth, error)
+	GetChildren(ctx context.Context, parentID int) ([]model.Agency, error)
+	GetTitlesForAgency(ctx context.Context, agencyID int) ([]model.Title, error)
+	GetSnapshotsForAgency(ctx context.Context, agencyID int) ([]model.AgencySnapshot, error)
+	GetDensityScoreForAgency(ctx context.Context, agency *model.Agency) (float64, error)
+	GetChaptersForAgency(ctx context.Context, agencyID int) ([]model.CFRReference, error)
+	GetAgencyTitles(ctx context.Context, agencyID int) ([]int, error)
+	GetTitleWordCount(ctx context.Context, titleNumber int) (int, error)
+	CountAgencies(ctx context.Context) (int, error)
+	GetAgencySnapshotDates(ctx context.Context) ([]time.Time, error)
+
+	UpsertAgency(ctx context.Context, a *model.Agency) error
+	LinkAgencyTitle(ctx context.Context, agencyID, titleNumber int) error
+	LinkAgencyChapter(ctx context.Context, agencyID, titleNumber int, chapter string) error
+	ClearAgencyTitles(ctx context.Context) error
+	UpdateWordCount(ctx context.Context, agencyID, wordCount, regulationCount int, checksum string) error
+
+	// InsertSnapshotIfChanged snapshots the agency with the titles linked to
+	// it unless its date already has a snapshot with the same checksum,
+	// reporting whether it did
+	InsertSnapshotIfChanged(ctx context.Context, snap *model.AgencySnapshot, titleNumbers []int) (changed bool, err error)
+}
+
+// SectionRepository stores the section text of title snapshots that search
+// indexes. SectionStore implements it on Postgres and MemoryStore in memory;
+// searching still needs SectionStore.
+type SectionRepository interface {
+	// ReplaceSections stores a snapshot's sections in place of any already
+	// stored for the same title and date
+	ReplaceSections(ctx context.Context, titleNumber int, snapshotDate time.Time, sections []model.Section) error
+	GetSections(ctx context.Context, titleNumber int, snapshotDate time.Time) ([]model.Section, error)
+	// HasSections reports whether any sections are stored for a snapshot
+	HasSections(ctx context.Context, titleNumber int, snapshotDate time.Time) (bool, error)
+}
+
+// QuarantineRepository holds fetched title versions that failed validation
+// for review. QuarantineStore implements it in the database and MemoryStore
+// in memory.
+type QuarantineRepository interface {
+	Quarantine(ctx context.Context, q *model.QuarantinedVersion) error
+	IsAccepted(ctx context.Context, titleNumber int, checksum string) (bool, error)
+	List(ctx context.Context, status string) ([]model.QuarantinedVersion, error)
+	Review(ctx context.Context, id int, status string) error
+}
+
+// IntegrityRepository reports what verify checks beyond the title and agency
+// repositories: stored snapshot content and sections, and rows left pointing
+// at missing titles or agencies. IntegrityStore implements it in the database
+// and MemoryStore in memory.
+type IntegrityRepository interface {
+	// SnapshotContent returns the XML stored with a title snapshot, or nil
+	// when none was kept
+	SnapshotContent(ctx context.Context, titleNumber int, snapshotDate time.Time) ([]byte, error)
+	CountSections(ctx context.Context, titleNumber int, snapshotDate time.Time) (int, error)
+	OrphanAgencyTitles(ctx context.Context) ([]AgencyTitleLink, error)
+	OrphanSnapshots(ctx context.Context) ([]OrphanSnapshot, error)
+}
+
+var (
+	_ TitleRepository      = (*TitleStore)(nil)
+	_ AgencyRepository     = (*AgencyStore)(nil)
+	_ SectionRepository    = (*SectionStore)(nil)
+	_ QuarantineRepository = (*QuarantineStore)(nil)
+	_ IntegrityRepository  = (*IntegrityStore)(nil)
+)
@@ -0,0 +1,385 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/jjenkins/usds/internal/model"
+)
+
+// SectionStore handles section text storage and full-text search
+type SectionStore struct {
+	db   *sql.DB
+	asOf time.Time // Zero for the current state
+}
+
+// NewSectionStore creates a new SectionStore
+func NewSectionStore(db *sql.DB) *SectionStore {
+	return &SectionStore{db: db}
+}
+
+// AsOf returns a SectionStore whose searches cover each title's latest
+// snapshot on or before the given date. A zero date searches the latest.
+func (s *SectionStore) AsOf(date time.Time) *SectionStore {
+	if date.IsZero() {
+		return s
+	}
+	return &SectionStore{db: s.db, asOf: date}
+}
+
+// sectionBatchSize is how many sections go into one INSERT, which keeps each
+// statement's parameters under the limits of both Postgres and SQLite
+const sectionBatchSize = 500
+
+// ReplaceSections stores the sections of a title snapshot, replacing any
+// previously stored for the same title and date
+func (s *SectionStore) ReplaceSections(ctx context.Context, titleNumber int, snapshotDate time.Time, sections []model.Section) error {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	_, err = tx.ExecContext(ctx, `DELETE FROM sections WHERE title_number = $1 AND snapshot_date = $2`, titleNumber, sqlDate(snapshotDate))
+	if err != nil {
+		return fmt.Errorf("failed to clear sections for title %d: %w", titleNumber, err)
+	}
+
+	for start := 0; start < len(sections); start += sectionBatchSize {
+		batch := sections[start:min(start+sectionBatchSize, len(sections))]
+
+		var query strings.Builder
+		query.WriteString(`INSERT INTO sections (title_number, snapshot_date, identifier, heading, chapter, text) VALUES `)
+		args := make([]any, 0, len(batch)*6)
+		for i, sec := range batch {
+			if i > 0 {
+				query.WriteString(", ")
+			}
+			n := len(args)
+			fmt.Fprintf(&query, "($%d, $%d, $%d, $%d, $%d, $%d)", n+1, n+2, n+3, n+4, n+5, n+6)
+			args = append(args, titleNumber, sqlDate(snapshotDate), sec.Identifier, sec.Heading, sec.Chapter, sec.Text)
+		}
+
+		if _, err := tx.ExecContext(ctx, query.String(), args...); err != nil {
+			return fmt.Errorf("failed to insert sections %s to %s of title %d: %w",
+				batch[0].Identifier, batch[len(batch)-1].Identifier, titleNumber, err)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return nil
+}
+
+// GetSections returns the sections stored for a title snapshot in document
+// order
+func (s *SectionStore) GetSections(ctx context.Context, titleNumber int, snapshotDate time.Time) ([]model.Section, error) {
+	query := `
+		SELECT id, title_number, snapshot_date, identifier, COALESCE(heading, ''), COALESCE(chapter, ''), text
+		FROM sections
+		WHERE title_number = $1 AND snapshot_date = $2
+		ORDER BY id
+	`
+
+	rows, err := s.db.QueryContext(ctx, query, titleNumber, sqlDate(snapshotDate))
+	if err != nil {
+		return nil, fmt.Errorf("failed to query sections for title %d: %w", titleNumber, err)
+	}
+	defer rows.Close()
+
+	var sections []model.Section
+	for rows.Next() {
+		var sec model.Section
+		if err := rows.Scan(&sec.ID, &sec.TitleNumber, &sec.SnapshotDate, &sec.Identifier, &sec.Heading, &sec.Chapter, &sec.Text); err != nil {
+			return nil, fmt.Errorf("failed to scan section: %w", err)
+		}
+		sections = append(sections, sec)
+	}
+
+	return sections, rows.Err()
+}
+
+// HasSections reports whether any sections are stored for a title snapshot
+func (s *SectionStore) HasSections(ctx context.Context, titleNumber int, snapshotDate time.Time) (bool, error) {
+	query := `SELECT EXISTS (SELECT 1 FROM sections WHERE title_number = $1 AND snapshot_date = $2)`
+
+	var exists bool
+	if err := s.db.QueryRowContext(ctx, query, titleNumber, sqlDate(snapshotDate)).Scan(&exists); err != nil {
+		return false, fmt.Errorf("failed to check sections for title %d: %w", titleNumber, err)
+	}
+	return exists, nil
+}
+
+// SearchQuery describes a full-text search over section text
+type SearchQuery struct {
+	Query       string // Web search syntax: words, "quoted phrases", -exclusions and OR
+	TitleNumber int    // Zero for all titles
+	AgencySlug  string // Empty for all agencies; includes sub-agencies' chapters
+	Limit       int
+	Offset      int
+}
+
+// SnippetPart is a run of snippet text, either matching the query or not
+type SnippetPart struct {
+	Text  string
+	Match bool
+}
+
+// SearchHit is a ranked section matching a search
+type SearchHit struct {
+	TitleNumber  int
+	TitleName    string
+	SnapshotDate time.Time
+	Identifier   string
+	Heading      string
+	Chapter      string
+	Rank         float64
+	Snippet      []SnippetPart
+}
+
+// Snippet delimiters passed to ts_headline and snippet; private-use code points never
+// appear in regulation text, so snippets can be split without parsing HTML
+const (
+	snippetStart = "\ue000"
+	snippetStop  = "\ue001"
+)
+
+// Search returns the sections matching q, best match first, along with the
+// total number of matches. Each title is searched at its latest snapshot, or
+// its latest on or before the store's as-of date.
+func (s *SectionStore) Search(ctx context.Context, q SearchQuery) ([]SearchHit, int, error) {
+	var query string
+	var args []any
+	if isSQLite(s.db) {
+		match := ftsQuery(q.Query)
+		if match == "" {
+			return nil, 0, nil
+		}
+		query = fmt.Sprintf(sqliteSearchQuery, snapshotDateFilter(s.asOf))
+		args = []any{match, q.TitleNumber, q.AgencySlug, q.Limit, q.Offset, snippetStart, snippetStop}
+	} else {
+		query = fmt.Sprintf(postgresSearchQuery, snapshotDateFilter(s.asOf))
+		options := fmt.Sprintf("StartSel=%s, StopSel=%s, MaxWords=35, MinWords=15, MaxFragments=2, FragmentDelimiter=\" ... \"", snippetStart, snippetStop)
+		args = []any{q.Query, q.TitleNumber, q.AgencySlug, q.Limit, q.Offset, options}
+	}
+
+	rows, err := s.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to search sections: %w", err)
+	}
+	defer rows.Close()
+
+	var hits []SearchHit
+	var total int
+	for rows.Next() {
+		var hit SearchHit
+		var snippet string
+		err := rows.Scan(
+			&hit.TitleNumber,
+			&hit.TitleName,
+			&hit.SnapshotDate,
+			&hit.Identifier,
+			&hit.Heading,
+			&hit.Chapter,
+			&hit.Rank,
+			&total,
+			&snippet,
+		)
+		if err != nil {
+			return nil, 0, fmt.Errorf("failed to scan search hit: %w", err)
+		}
+		hit.Snippet = splitSnippet(snippet)
+		hits = append(hits, hit)
+	}
+
+	return hits, total, rows.Err()
+}
+
+// searchScope selects the agency "agency_tree" named by slug $3 with its
+// sub-agencies, and the "latest" snapshot of each title with sections that
+// the as-of filter formatted into it allows
+const searchScope = `
+	WITH RECURSIVE agency_tree AS (
+		SELECT id FROM agencies WHERE slug = $3
+		UNION
+		SELECT a.id FROM agencies a INNER JOIN agency_tree tree ON a.parent_id = tree.id
+	),
+	latest AS (
+		SELECT title_number, MAX(snapshot_date) AS snapshot_date
+		FROM sections
+		WHERE %s
+		GROUP BY title_number
+	)
+`
+
+// searchFilter limits the section "s" to title $2 and agency $3, when set
+const searchFilter = `
+	($2 = 0 OR s.title_number = $2)
+	AND ($3 = '' OR EXISTS (
+		SELECT 1 FROM agency_chapters ac
+		INNER JOIN agency_tree tree ON tree.id = ac.agency_id
+		WHERE ac.title_number = s.title_number AND ac.chapter = s.chapter
+	))
+`
+
+// postgresSearchQuery matches $1 in web search syntax against the
+// search_vector column and highlights with ts_headline options $6
+var postgresSearchQuery = searchScope + `,
+	hits AS (
+		SELECT s.id, ts_rank_cd(s.search_vector, q) AS rank, COUNT(*) OVER () AS total
+		FROM sections s
+		INNER JOIN latest l ON l.title_number = s.title_number AND l.snapshot_date = s.snapshot_date
+		CROSS JOIN websearch_to_tsquery('english', $1) q
+		WHERE s.search_vector @@ q AND ` + searchFilter + `
+		ORDER BY rank DESC, s.title_number, s.identifier
+		LIMIT $4 OFFSET $5
+	)
+	SELECT s.title_number, COALESCE(t.title_name, ''), s.snapshot_date, s.identifier,
+	       COALESCE(s.heading, ''), COALESCE(s.chapter, ''), hits.rank, hits.total,
+	       ts_headline('english', s.text, websearch_to_tsquery('english', $1), $6)
+	FROM hits
+	INNER JOIN sections s ON s.id = hits.id
+	LEFT JOIN titles t ON t.title_number = s.title_number
+	ORDER BY hits.rank DESC, s.title_number, s.identifier
+`
+
+// sqliteSearchQuery matches the FTS5 query $1 against sections_fts, ranking
+// headings above text as the search_vector weights do, and marks snippets
+// with $6 and $7. bm25 scores better matches lower, so rank negates it.
+var sqliteSearchQuery = searchScope + `,
+	matches AS (
+		SELECT rowid AS id, -bm25(sections_fts, 2.0, 1.0) AS rank
+		FROM sections_fts
+		WHERE sections_fts MATCH $1
+	),
+	hits AS (
+		SELECT s.id, m.rank, COUNT(*) OVER () AS total
+		FROM matches m
+		INNER JOIN sections s ON s.id = m.id
+		INNER JOIN latest l ON l.title_number = s.title_number AND l.snapshot_date = s.snapshot_date
+		WHERE ` + searchFilter + `
+		ORDER BY m.rank DESC, s.title_number, s.identifier
+		LIMIT $4 OFFSET $5
+	)
+	SELECT s.title_number, COALESCE(t.title_name, ''), s.snapshot_date, s.identifier,
+	       COALESCE(s.heading, ''), COALESCE(s.chapter, ''), hits.rank, hits.total,
+	       snippet(sections_fts, 1, $6, $7, ' ... ', 35)
+	FROM hits
+	INNER JOIN sections s ON s.id = hits.id
+	INNER JOIN sections_fts ON sections_fts.rowid = hits.id AND sections_fts MATCH $1
+	LEFT JOIN titles t ON t.title_number = s.title_number
+	ORDER BY hits.rank DESC, s.title_number, s.identifier
+`
+
+// ftsQuery translates web search syntax, as websearch_to_tsquery reads it,
+// into an FTS5 query: words and "quoted phrases" must all match, OR between
+// two terms matches either, and -term excludes. Every term is quoted, so
+// punctuation in it cannot be read as FTS5 syntax. It returns "" when
+// nothing is left to match.
+func ftsQuery(web string) string {
+	var groups [][]string // Terms ANDed within a group, groups ORed
+	var excluded []string
+	current := []string{}
+	or := false
+
+	for rest := strings.TrimSpace(web); rest != ""; rest = strings.TrimSpace(rest) {
+		negate := false
+		if rest[0] == '-' {
+			negate, rest = true, rest[1:]
+		}
+
+		var term string
+		if strings.HasPrefix(rest, `"`) {
+			end := strings.Index(rest[1:], `"`)
+			if end < 0 {
+				term, rest = rest[1:], ""
+			} else {
+				term, rest = rest[1:end+1], rest[end+2:]
+			}
+		} else {
+			end := strings.IndexFunc(rest, unicode.IsSpace)
+			if end < 0 {
+				end = len(rest)
+			}
+			term, rest = rest[:end], rest[end:]
+			if term == "OR" && !negate {
+				or = len(current) > 0
+				continue
+			}
+		}
+
+		if strings.IndexFunc(term, func(r rune) bool { return unicode.IsLetter(r) || unicode.IsNumber(r) }) < 0 {
+			continue
+		}
+		quoted := `"` + strings.ReplaceAll(term, `"`, `""`) + `"`
+
+		switch {
+		case negate:
+			excluded = append(excluded, quoted)
+		case or:
+			groups = append(groups, current)
+			current = []string{quoted}
+			or = false
+		default:
+			current = append(current, quoted)
+		}
+	}
+	if len(current) > 0 {
+		groups = append(groups, current)
+	}
+	if len(groups) == 0 {
+		return ""
+	}
+
+	var alternatives []string
+	for _, g := range groups {
+		alternatives = append(alternatives, strings.Join(g, " AND "))
+	}
+	query := strings.Join(alternatives, " OR ")
+	if len(excluded) > 0 {
+		query = "(" + query + ") NOT " + strings.Join(excluded, " NOT ")
+	}
+	return query
+}
+
+// CountSections returns the number of stored sections, so callers can tell an
+// empty index from a search with no matches
+func (s *SectionStore) CountSections(ctx context.Context) (int, error) {
+	var count int
+	err := s.db.QueryRowContext(ctx, fmt.Sprintf("SELECT COUNT(*) FROM sections WHERE %s", snapshotDateFilter(s.asOf))).Scan(&count)
+	if err != nil {
+		return 0, fmt.Errorf("failed to count sections: %w", err)
+	}
+	return count, nil
+}
+
+// splitSnippet splits a search snippet at its match delimiters
+func splitSnippet(snippet string) []SnippetPart {
+	var parts []SnippetPart
+	for snippet != "" {
+		start := strings.Index(snippet, snippetStart)
+		if start < 0 {
+			parts = append(parts, SnippetPart{Text: snippet})
+			break
+		}
+		if start > 0 {
+			parts = append(parts, SnippetPart{Text: snippet[:start]})
+		}
+		snippet = snippet[start+len(snippetStart):]
+
+		stop := strings.Index(snippet, snippetStop)
+		if stop < 0 {
+			parts = append(parts, SnippetPart{Text: snippet, Match: true})
+			break
+		}
+		parts = append(parts, SnippetPart{Text: snippet[:stop], Match: true})
+		snippet = snippet[stop+len(snippetStop):]
+	}
+	return parts
+}
@@ -0,0 +1,305 @@
+package store_test
+
+import (
+	"context"
+	"database/sql"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/jjenkins/usds/internal/model"
+	"github.com/jjenkins/usds/internal/store"
+)
+
+// newSQLiteDB opens a fresh SQLite database
+func newSQLiteDB(t *testing.T) *sql.DB {
+	db, err := store.NewDB("sqlite://" + filepath.Join(t.TempDir(), "usds.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { db.Close() })
+	return db
+}
+
+var (
+	jan = time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	jun = time.Date(2024, 6, 1, 0, 0, 0, 0, time.UTC)
+)
+
+// TestSQLiteQueries runs the stores whose queries differ between Postgres
+// and SQLite against SQLite
+func TestSQLiteQueries(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("Search", func(t *testing.T) {
+		db := newSQLiteDB(t)
+		titles, sections := store.NewTitleStore(db), store.NewSectionStore(db)
+
+		title := &model.Title{TitleNumber: 1, TitleName: "General Provisions", WordCount: 12, SectionCount: 2, Checksum: "a"}
+		if _, err := titles.SaveTitleWithSnapshot(ctx, title, jun); err != nil {
+			t.Fatal(err)
+		}
+		err := sections.ReplaceSections(ctx, 1, jun, []model.Section{
+			{Identifier: "§ 1.1", Heading: "Definitions", Chapter: "I", Text: "The Federal Register publishes documents."},
+			{Identifier: "§ 1.2", Heading: "Scope", Chapter: "I", Text: "Documents are filed daily."},
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		for _, tc := range []struct {
+			query string
+			want  []string
+		}{
+			{"documents", []string{"§ 1.1", "§ 1.2"}},
+			{`"federal register"`, []string{"§ 1.1"}},
+			{"documents -daily", []string{"§ 1.1"}},
+			{"definitions OR filed", []string{"§ 1.1", "§ 1.2"}},
+			{"-documents", nil},
+		} {
+			hits, total, err := sections.Search(ctx, store.SearchQuery{Query: tc.query, Limit: 10})
+			if err != nil {
+				t.Fatalf("Search(%q): %v", tc.query, err)
+			}
+			var got []string
+			for _, h := range hits {
+				got = append(got, h.Identifier)
+			}
+			if !sameSet(got, tc.want) || total != len(tc.want) {
+				t.Errorf("Search(%q) = %v (%d), want %v", tc.query, got, total, tc.want)
+			}
+		}
+
+		hits, _, err := sections.Search(ctx, store.SearchQuery{Query: "register", Limit: 10})
+		if err != nil || len(hits) != 1 {
+			t.Fatalf("Search(register) = %v, %v", hits, err)
+		}
+		var matched string
+		for _, p := range hits[0].Snippet {
+			if p.Match {
+				matched += p.Text
+			}
+		}
+		if matched != "Register" || !hits[0].SnapshotDate.Equal(jun) {
+			t.Errorf("hit = %+v, want Register highlighted on %s", hits[0], jun)
+		}
+	})
+
+	t.Run("Watchlist", func(t *testing.T) {
+		db := newSQLiteDB(t)
+		titles, sections, watchlist := store.NewTitleStore(db), store.NewSectionStore(db), store.NewWatchlistStore(db)
+
+		title := &model.Title{TitleNumber: 1, TitleName: "General Provisions", WordCount: 10, SectionCount: 1, Checksum: "a"}
+		if _, err := titles.SaveTitleWithSnapshot(ctx, title, jan); err != nil {
+			t.Fatal(err)
+		}
+		if err := sections.ReplaceSections(ctx, 1, jan, []model.Section{{Identifier: "§ 1.1", Heading: "Definitions", Text: "one two"}}); err != nil {
+			t.Fatal(err)
+		}
+		for _, target := range []model.WatchTarget{model.TitleTarget(1), model.SectionTarget(1, "§ 1.1")} {
+			if err := watchlist.Watch(ctx, "alice", target); err != nil {
+				t.Fatal(err)
+			}
+			// Watching twice does nothing
+			if err := watchlist.Watch(ctx, "alice", target); err != nil {
+				t.Fatal(err)
+			}
+		}
+
+		title.WordCount, title.Checksum = 12, "b"
+		if _, err := titles.SaveTitleWithSnapshot(ctx, title, jun); err != nil {
+			t.Fatal(err)
+		}
+		if err := sections.ReplaceSections(ctx, 1, jun, []model.Section{{Identifier: "§ 1.1", Heading: "Definitions", Text: "one two three"}}); err != nil {
+			t.Fatal(err)
+		}
+
+		items, err := watchlist.List(ctx, "alice")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(items) != 2 {
+			t.Fatalf("List = %+v, want 2 items", items)
+		}
+		for _, i := range items {
+			if !i.ChangedSinceSeen() || !i.LastChangedOn.Valid || !i.LastChangedOn.Time.Equal(jun) {
+				t.Errorf("%s = %+v, want changed on %s", i.Key(), i, jun)
+			}
+		}
+		// Both changed on the same day, so they are listed by name
+		if items[0].Name != "Definitions" || items[0].WordCount != 3 || items[0].LastChange.Int64 != 1 {
+			t.Errorf("section = %+v, want Definitions with 3 words, 1 more", items[0])
+		}
+
+		if err := watchlist.MarkAllSeen(ctx, "alice"); err != nil {
+			t.Fatal(err)
+		}
+		items, err = watchlist.List(ctx, "alice")
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, i := range items {
+			if i.ChangedSinceSeen() {
+				t.Errorf("%s still changed after MarkAllSeen", i.Key())
+			}
+		}
+
+		if err := watchlist.Unwatch(ctx, "alice", model.TitleTarget(1)); err != nil {
+			t.Fatal(err)
+		}
+		if watching, err := watchlist.IsWatching(ctx, "alice", model.TitleTarget(1)); err != nil || watching {
+			t.Errorf("IsWatching after Unwatch = %v, %v", watching, err)
+		}
+	})
+
+	t.Run("Webhooks", func(t *testing.T) {
+		db := newSQLiteDB(t)
+		titles, webhooks := store.NewTitleStore(db), store.NewWebhookStore(db)
+
+		if err := webhooks.CreateSubscription(ctx, &model.WebhookSubscription{Name: "all", URL: "http://example.com", Secret: "s", Active: true}); err != nil {
+			t.Fatal(err)
+		}
+		title := &model.Title{TitleNumber: 1, TitleName: "General Provisions", WordCount: 10, SectionCount: 1, Checksum: "a"}
+		if _, err := titles.SaveTitleWithSnapshot(ctx, title, jan); err != nil {
+			t.Fatal(err)
+		}
+		title.WordCount, title.Checksum = 12, "b"
+		if _, err := titles.SaveTitleWithSnapshot(ctx, title, jun); err != nil {
+			t.Fatal(err)
+		}
+
+		claimed, err := webhooks.ClaimDueDeliveries(ctx, 10, time.Minute)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(claimed) != 2 || !claimed[0].Event.First() || !claimed[1].Event.SnapshotDate.Equal(jun) ||
+			!claimed[1].Event.PreviousSnapshotDate.Time.Equal(jan) || claimed[1].Event.WordCountChange() != 2 {
+			t.Fatalf("ClaimDueDeliveries = %+v, want the first snapshot and the %s change", claimed, jun)
+		}
+
+		// Claimed deliveries are leased
+		again, err := webhooks.ClaimDueDeliveries(ctx, 10, time.Minute)
+		if err != nil || len(again) != 0 {
+			t.Errorf("second ClaimDueDeliveries = %+v, %v, want none", again, err)
+		}
+
+		d := claimed[len(claimed)-1]
+		if err := webhooks.MarkFailed(ctx, d.ID, 500, "boom", time.Minute); err != nil {
+			t.Fatal(err)
+		}
+		if early, err := webhooks.ClaimDueDeliveries(ctx, 10, time.Minute); err != nil || len(early) != 0 {
+			t.Errorf("ClaimDueDeliveries before the retry = %+v, %v, want none", early, err)
+		}
+		if _, err := db.ExecContext(ctx, `UPDATE webhook_deliveries SET next_attempt_at = datetime('now', '-1 seconds') WHERE id = $1`, d.ID); err != nil {
+			t.Fatal(err)
+		}
+		retried, err := webhooks.ClaimDueDeliveries(ctx, 10, time.Minute)
+		if err != nil || len(retried) != 1 || retried[0].Attempts != 1 {
+			t.Errorf("ClaimDueDeliveries after failure = %+v, %v, want the failed delivery", retried, err)
+		}
+
+		failed, err := webhooks.ListDeliveries(ctx, store.DeliveryFilter{SubscriptionID: d.SubscriptionID, Limit: 10})
+		if err != nil || len(failed) != len(claimed) {
+			t.Errorf("ListDeliveries = %+v, %v", failed, err)
+		}
+
+		// Re-recording the snapshot with new content queues its delivery again
+		if err := webhooks.MarkDelivered(ctx, d.ID, 200); err != nil {
+			t.Fatal(err)
+		}
+		title.WordCount, title.Checksum = 14, "c"
+		if _, err := titles.SaveTitleWithSnapshot(ctx, title, jun); err != nil {
+			t.Fatal(err)
+		}
+		requeued, err := webhooks.ClaimDueDeliveries(ctx, 10, time.Minute)
+		if err != nil || len(requeued) != 1 || requeued[0].ID != d.ID || requeued[0].Attempts != 0 || requeued[0].Event.Checksum != "c" {
+			t.Errorf("ClaimDueDeliveries after re-recording = %+v, %v, want delivery %d afresh", requeued, err, d.ID)
+		}
+
+		// An agency's first snapshot is recorded along with the snapshot
+		agencies := store.NewAgencyStore(db)
+		agency := &model.Agency{AgencyName: "Office of Management and Budget", Slug: "omb"}
+		if err := agencies.UpsertAgency(ctx, agency); err != nil {
+			t.Fatal(err)
+		}
+		snap := &model.AgencySnapshot{AgencyID: agency.ID, AgencyName: agency.AgencyName, TotalWordCount: 30, Checksum: "c", SnapshotDate: jun}
+		if changed, err := agencies.InsertSnapshotIfChanged(ctx, snap, []int{1}); err != nil || !changed {
+			t.Fatalf("InsertSnapshotIfChanged = %v, %v", changed, err)
+		}
+		events, err := store.NewChangeStore(db).List(ctx, store.ChangeFilter{AgencySlug: "omb", Limit: 10})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(events) != 1 || events[0].EntityType != model.ChangeEntityAgency || !events[0].First() || events[0].WordCountChange() != 30 {
+			t.Errorf("agency change events = %+v, want the agency's first snapshot", events)
+		}
+	})
+
+	t.Run("ImportRuns", func(t *testing.T) {
+		db := newSQLiteDB(t)
+		runs := store.NewImportRunStore(db)
+
+		run := &model.ImportRun{Source: model.ImportSourceCLI, Mode: model.ImportModeFull}
+		if err := runs.StartRun(ctx, run); err != nil {
+			t.Fatal(err)
+		}
+		run.Status = model.ImportFailed
+		failures := []model.ImportFailure{{TitleNumber: sql.NullInt64{Int64: 1, Valid: true}, Message: "boom"}}
+		if err := runs.FinishRun(ctx, run, failures); err != nil {
+			t.Fatal(err)
+		}
+
+		got, err := runs.GetRun(ctx, run.ID)
+		if err != nil || got.Status != model.ImportFailed || !got.FinishedAt.Valid {
+			t.Errorf("GetRun = %+v, %v, want a finished failed run", got, err)
+		}
+		for _, f := range []store.FailureFilter{{Limit: 10}, {RunID: run.ID, Limit: 10}, {TitleNumber: 1, Limit: 10}} {
+			listed, err := runs.ListFailures(ctx, f)
+			if err != nil || len(listed) != 1 {
+				t.Errorf("ListFailures(%+v) = %+v, %v, want 1 failure", f, listed, err)
+			}
+		}
+		if listed, err := runs.ListFailures(ctx, store.FailureFilter{TitleNumber: 2, Limit: 10}); err != nil || len(listed) != 0 {
+			t.Errorf("ListFailures for title 2 = %+v, %v, want none", listed, err)
+		}
+	})
+
+	t.Run("AdvisoryLock", func(t *testing.T) {
+		db := newSQLiteDB(t)
+
+		lock, err := store.TryAdvisoryLock(ctx, db, store.ImportLockKey)
+		if err != nil || lock == nil {
+			t.Fatalf("TryAdvisoryLock = %v, %v", lock, err)
+		}
+		if other, err := store.TryAdvisoryLock(ctx, db, store.ImportLockKey); err != nil || other != nil {
+			t.Errorf("TryAdvisoryLock while held = %v, %v, want nil", other, err)
+		}
+		if err := lock.Release(); err != nil {
+			t.Fatal(err)
+		}
+		lock, err = store.TryAdvisoryLock(ctx, db, store.ImportLockKey)
+		if err != nil || lock == nil {
+			t.Fatalf("TryAdvisoryLock after Release = %v, %v", lock, err)
+		}
+		lock.Release()
+	})
+}
+
+func sameSet(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	seen := make(map[string]int)
+	for _, s := range a {
+		seen[s]++
+	}
+	for _, s := range b {
+		seen[s]--
+	}
+	for _, n := range seen {
+		if n != 0 {
+			return false
+		}
+	}
+	return true
+}
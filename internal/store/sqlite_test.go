@@ -0,0 +1,22 @@
+package store_test
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/jjenkins/usds/internal/store"
+	"github.com/jjenkins/usds/internal/store/storetest"
+)
+
+// TestSQLiteStore runs the conformance suite against a fresh SQLite database
+// for each case
+func TestSQLiteStore(t *testing.T) {
+	storetest.Run(t, func(t *testing.T) (store.TitleRepository, store.AgencyRepository) {
+		db, err := store.NewDB("sqlite://" + filepath.Join(t.TempDir(), "usds.db"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		t.Cleanup(func() { db.Close() })
+		return store.NewTitleStore(db), store.NewAgencyStore(db)
+	})
+}
@@ -0,0 +1,552 @@
+// Package storetest is the conformance suite every TitleRepository and
+// AgencyRepository implementation must pass, so the in-memory store can stand
+// in for Postgres in tests.
+package storetest
+
+import (
+	"context"
+	"database/sql"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/jjenkins/usds/internal/model"
+	"github.com/jjenkins/usds/internal/store"
+)
+
+// Factory returns empty repositories sharing one backing store
+type Factory func(t *testing.T) (store.TitleRepository, store.AgencyRepository)
+
+var (
+	jan = time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	feb = time.Date(2024, 2, 1, 0, 0, 0, 0, time.UTC)
+	mar = time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC)
+)
+
+// Run runs the suite against the repositories newRepos returns
+func Run(t *testing.T, newRepos Factory) {
+	tests := map[string]func(t *testing.T, titles store.TitleRepository, agencies store.AgencyRepository){
+		"TitleSnapshotDedupe":  testTitleSnapshotDedupe,
+		"TitleAsOf":            testTitleAsOf,
+		"TitleAggregates":      testTitleAggregates,
+		"TitleSorting":         testTitleSorting,
+		"AgencySnapshotDedupe": testAgencySnapshotDedupe,
+		"AgencyUpsert":         testAgencyUpsert,
+		"AgencyLinks":          testAgencyLinks,
+		"AgencyHierarchy":      testAgencyHierarchy,
+		"AgencySorting":        testAgencySorting,
+		"AgencyAsOf":           testAgencyAsOf,
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			defer func() {
+				if r := recover(); r != nil {
+					f, ok := r.(failure)
+					if !ok {
+						panic(r)
+					}
+					t.Fatal(f.err)
+				}
+			}()
+			titles, agencies := newRepos(t)
+			test(t, titles, agencies)
+		})
+	}
+}
+
+func saveTitle(t *testing.T, titles store.TitleRepository, number, words, sections int, checksum string, date time.Time) bool {
+	t.Helper()
+	title := &model.Title{
+		TitleNumber:     number,
+		TitleName:       "Title " + string(rune('A'+number-1)),
+		WordCount:       words,
+		SectionCount:    sections,
+		Checksum:        checksum,
+		LastAmendedDate: sql.NullTime{Time: date, Valid: true},
+		FetchedAt:       date,
+	}
+	changed, err := titles.SaveTitleWithSnapshot(context.Background(), title, date)
+	if err != nil {
+		t.Fatalf("SaveTitleWithSnapshot(%d, %s): %v", number, checksum, err)
+	}
+	if title.ID == 0 {
+		t.Fatalf("SaveTitleWithSnapshot(%d) did not set the ID", number)
+	}
+	return changed
+}
+
+func upsertAgency(t *testing.T, agencies store.AgencyRepository, name, slug string, parent *model.Agency) *model.Agency {
+	t.Helper()
+	a := &model.Agency{AgencyName: name, Slug: slug}
+	if parent != nil {
+		a.ParentID = sql.NullInt64{Int64: int64(parent.ID), Valid: true}
+	}
+	if err := agencies.UpsertAgency(context.Background(), a); err != nil {
+		t.Fatalf("UpsertAgency(%s): %v", slug, err)
+	}
+	if a.ID == 0 {
+		t.Fatalf("UpsertAgency(%s) did not set the ID", slug)
+	}
+	return a
+}
+
+func snapshotAgency(t *testing.T, agencies store.AgencyRepository, a *model.Agency, words int, checksum string, date time.Time, titleNumbers ...int) bool {
+	t.Helper()
+	snap := &model.AgencySnapshot{
+		AgencyID:        a.ID,
+		AgencyName:      a.AgencyName,
+		TotalWordCount:  words,
+		RegulationCount: len(titleNumbers),
+		Checksum:        checksum,
+		SnapshotDate:    date,
+	}
+	changed, err := agencies.InsertSnapshotIfChanged(context.Background(), snap, titleNumbers)
+	if err != nil {
+		t.Fatalf("InsertSnapshotIfChanged(%s, %s): %v", a.Slug, checksum, err)
+	}
+	return changed
+}
+
+// failure carries a repository error from must to the subtest running it
+type failure struct{ err error }
+
+// must unwraps a repository result, failing the subtest on error
+func must[T any](v T, err error) T {
+	if err != nil {
+		panic(failure{err})
+	}
+	return v
+}
+
+func dates(ts []time.Time) []string {
+	var out []string
+	for _, d := range ts {
+		out = append(out, d.Format("2006-01-02"))
+	}
+	return out
+}
+
+func testTitleSnapshotDedupe(t *testing.T, titles store.TitleRepository, _ store.AgencyRepository) {
+	ctx := context.Background()
+
+	if !saveTitle(t, titles, 1, 100, 10, "a", jan) {
+		t.Error("first save reported no change")
+	}
+	if saveTitle(t, titles, 1, 100, 10, "a", jan) {
+		t.Error("re-saving the same checksum on the same date reported a change")
+	}
+	if !saveTitle(t, titles, 1, 120, 10, "b", jan) {
+		t.Error("a new checksum on the same date reported no change")
+	}
+	if !saveTitle(t, titles, 1, 120, 10, "b", feb) {
+		t.Error("the same checksum on a new date reported no change")
+	}
+
+	snaps := must(titles.GetSnapshots(ctx, 1))
+	if len(snaps) != 2 {
+		t.Fatalf("got %d snapshots, want 2", len(snaps))
+	}
+	if got := snaps[0].SnapshotDate.Format("2006-01-02"); got != "2024-02-01" {
+		t.Errorf("newest snapshot is %s, want 2024-02-01", got)
+	}
+	if snaps[1].Checksum != "b" || snaps[1].WordCount != 120 {
+		t.Errorf("January snapshot = %s/%d, want the replacement b/120", snaps[1].Checksum, snaps[1].WordCount)
+	}
+
+	title := must(titles.GetByNumber(ctx, 1))
+	if title == nil || title.WordCount != 120 || title.Checksum != "b" {
+		t.Errorf("GetByNumber = %+v, want the latest save", title)
+	}
+	if missing := must(titles.GetByNumber(ctx, 2)); missing != nil {
+		t.Errorf("GetByNumber(2) = %+v, want nil", missing)
+	}
+}
+
+func testTitleAsOf(t *testing.T, titles store.TitleRepository, _ store.AgencyRepository) {
+	ctx := context.Background()
+	saveTitle(t, titles, 1, 100, 10, "a", jan)
+	saveTitle(t, titles, 2, 200, 10, "c", jan)
+	saveTitle(t, titles, 1, 150, 10, "b", mar)
+	saveTitle(t, titles, 3, 300, 10, "d", mar)
+
+	past := titles.AsOf(feb)
+	if n := must(past.CountTitles(ctx)); n != 2 {
+		t.Errorf("CountTitles as of February = %d, want 2", n)
+	}
+	if total := must(past.GetTotalWordCount(ctx)); total != 300 {
+		t.Errorf("GetTotalWordCount as of February = %d, want 300", total)
+	}
+	if title := must(past.GetByNumber(ctx, 1)); title == nil || title.WordCount != 100 {
+		t.Errorf("GetByNumber(1) as of February = %+v, want 100 words", title)
+	}
+	if title := must(past.GetByNumber(ctx, 3)); title != nil {
+		t.Errorf("GetByNumber(3) as of February = %+v, want nil", title)
+	}
+	if snaps := must(past.GetSnapshots(ctx, 1)); len(snaps) != 1 {
+		t.Errorf("GetSnapshots(1) as of February returned %d, want 1", len(snaps))
+	}
+	if got := dates(must(past.GetSnapshotDates(ctx))); !reflect.DeepEqual(got, []string{"2024-01-01"}) {
+		t.Errorf("GetSnapshotDates as of February = %v", got)
+	}
+
+	if got := dates(must(titles.GetSnapshotDates(ctx))); !reflect.DeepEqual(got, []string{"2024-03-01", "2024-01-01"}) {
+		t.Errorf("GetSnapshotDates = %v, want newest first", got)
+	}
+	if titles.AsOf(time.Time{}) == nil {
+		t.Error("AsOf(zero) returned nil")
+	}
+}
+
+func testTitleAggregates(t *testing.T, titles store.TitleRepository, _ store.AgencyRepository) {
+	ctx := context.Background()
+	saveTitle(t, titles, 1, 100, 10, "a", jan)
+	saveTitle(t, titles, 2, 400, 10, "b", jan)
+	saveTitle(t, titles, 3, 900, 10, "c", feb)
+	saveTitle(t, titles, 1, 200, 10, "d", feb)
+
+	if n := must(titles.CountTitles(ctx)); n != 3 {
+		t.Errorf("CountTitles = %d, want 3", n)
+	}
+	if total := must(titles.GetTotalWordCount(ctx)); total != 1500 {
+		t.Errorf("GetTotalWordCount = %d, want 1500", total)
+	}
+	if avg := must(titles.GetAverageDensity(ctx)); avg != 50 {
+		t.Errorf("GetAverageDensity = %v, want 50", avg)
+	}
+
+	totals := must(titles.GetSnapshotTotals(ctx))
+	want := []struct {
+		date         string
+		count, words int
+	}{{"2024-01-01", 2, 500}, {"2024-02-01", 3, 1500}}
+	if len(totals) != len(want) {
+		t.Fatalf("GetSnapshotTotals returned %d dates, want %d", len(totals), len(want))
+	}
+	for i, w := range want {
+		got := totals[i]
+		if got.Date.Format("2006-01-02") != w.date || got.TitleCount != w.count || got.WordCount != w.words {
+			t.Errorf("totals[%d] = %s %d titles %d words, want %s %d titles %d words",
+				i, got.Date.Format("2006-01-02"), got.TitleCount, got.WordCount, w.date, w.count, w.words)
+		}
+	}
+
+	low := must(titles.GetByNumber(ctx, 2))
+	if score := must(titles.GetDensityScoreForTitle(ctx, low)); score != 0.5 {
+		t.Errorf("GetDensityScoreForTitle(2) = %v, want 0.5", score)
+	}
+	if score := must(titles.GetDensityScoreForTitle(ctx, &model.Title{})); score != 0 {
+		t.Errorf("GetDensityScoreForTitle with no sections = %v, want 0", score)
+	}
+}
+
+func testTitleSorting(t *testing.T, titles store.TitleRepository, _ store.AgencyRepository) {
+	ctx := context.Background()
+	saveTitle(t, titles, 1, 300, 10, "a", jan)
+	saveTitle(t, titles, 2, 100, 20, "b", mar)
+	saveTitle(t, titles, 3, 200, 30, "c", feb)
+
+	// Title 4 has never been amended
+	unamended := &model.Title{TitleNumber: 4, TitleName: "Title D", WordCount: 400, SectionCount: 40, Checksum: "d", FetchedAt: jan}
+	if _, err := titles.SaveTitleWithSnapshot(ctx, unamended, jan); err != nil {
+		t.Fatalf("SaveTitleWithSnapshot(4): %v", err)
+	}
+
+	for _, tc := range []struct {
+		sortBy, order string
+		want          []int
+	}{
+		{"number", "asc", []int{1, 2, 3, 4}},
+		{"number", "desc", []int{4, 3, 2, 1}},
+		{"word_count", "asc", []int{2, 3, 1, 4}},
+		{"section_count", "desc", []int{4, 3, 2, 1}},
+		{"last_amended", "asc", []int{1, 3, 2, 4}},
+		{"last_amended", "desc", []int{2, 3, 1, 4}},
+		{"unknown; DROP TABLE titles", "asc", []int{1, 2, 3, 4}},
+	} {
+		sorted := must(titles.GetAllSortedWithDensity(ctx, tc.sortBy, tc.order))
+		var got []int
+		for _, title := range sorted {
+			got = append(got, title.TitleNumber)
+		}
+		if !reflect.DeepEqual(got, tc.want) {
+			t.Errorf("GetAllSortedWithDensity(%q, %q) = %v, want %v", tc.sortBy, tc.order, got, tc.want)
+		}
+	}
+
+	sorted := must(titles.GetAllSortedWithDensity(ctx, "number", "asc"))
+	if sorted[0].DensityScore != 1 || sorted[1].DensityScore != 0 {
+		t.Errorf("density scores = %v, %v, want 1 for title 1 and 0 for title 2",
+			sorted[0].DensityScore, sorted[1].DensityScore)
+	}
+}
+
+func testAgencySnapshotDedupe(t *testing.T, titles store.TitleRepository, agencies store.AgencyRepository) {
+	ctx := context.Background()
+	saveTitle(t, titles, 1, 100, 10, "a", jan)
+	saveTitle(t, titles, 2, 200, 10, "b", jan)
+	epa := upsertAgency(t, agencies, "Environmental Protection Agency", "epa", nil)
+
+	if !snapshotAgency(t, agencies, epa, 100, "x", jan, 1) {
+		t.Error("first snapshot reported no change")
+	}
+	if snapshotAgency(t, agencies, epa, 100, "x", jan, 1) {
+		t.Error("the same checksum on the same date reported a change")
+	}
+	if !snapshotAgency(t, agencies, epa, 300, "y", jan, 1, 2) {
+		t.Error("a new checksum on the same date reported no change")
+	}
+	if !snapshotAgency(t, agencies, epa, 300, "y", feb, 1, 2) {
+		t.Error("the same checksum on a new date reported no change")
+	}
+
+	snaps := must(agencies.GetSnapshotsForAgency(ctx, epa.ID))
+	if len(snaps) != 2 {
+		t.Fatalf("got %d snapshots, want 2", len(snaps))
+	}
+	if snaps[0].SnapshotDate.Format("2006-01-02") != "2024-02-01" || snaps[1].Checksum != "y" {
+		t.Errorf("snapshots = %+v, want February first and January replaced", snaps)
+	}
+
+	_, err := agencies.InsertSnapshotIfChanged(ctx, &model.AgencySnapshot{AgencyID: epa.ID + 100, Checksum: "z", SnapshotDate: jan}, nil)
+	if err == nil {
+		t.Error("snapshotting a missing agency succeeded")
+	}
+}
+
+func testAgencyUpsert(t *testing.T, _ store.TitleRepository, agencies store.AgencyRepository) {
+	ctx := context.Background()
+	epa := upsertAgency(t, agencies, "Environmental Protection Agency", "epa", nil)
+	id := epa.ID
+
+	renamed := upsertAgency(t, agencies, "Environment Agency", "epa", nil)
+	if renamed.ID != id {
+		t.Errorf("upserting the same slug changed the ID from %d to %d", id, renamed.ID)
+	}
+	if got := must(agencies.GetBySlug(ctx, "epa")); got == nil || got.AgencyName != "Environment Agency" {
+		t.Errorf("GetBySlug(epa) = %+v, want the new name", got)
+	}
+	if got := must(agencies.GetByID(ctx, id)); got == nil || got.Slug != "epa" {
+		t.Errorf("GetByID = %+v", got)
+	}
+	if got := must(agencies.GetBySlug(ctx, "missing")); got != nil {
+		t.Errorf("GetBySlug(missing) = %+v, want nil", got)
+	}
+
+	if err := agencies.UpsertAgency(ctx, &model.Agency{AgencyName: "Environment Agency", Slug: "other"}); err == nil {
+		t.Error("upserting a duplicate name under another slug succeeded")
+	}
+
+	if err := agencies.UpdateWordCount(ctx, id, 500, 2, "sum"); err != nil {
+		t.Fatal(err)
+	}
+	got := must(agencies.GetByID(ctx, id))
+	if got.TotalWordCount != 500 || got.RegulationCount != 2 || got.Checksum != "sum" {
+		t.Errorf("after UpdateWordCount = %+v", got)
+	}
+	if n := must(agencies.CountAgencies(ctx)); n != 1 {
+		t.Errorf("CountAgencies = %d, want 1", n)
+	}
+}
+
+func testAgencyLinks(t *testing.T, titles store.TitleRepository, agencies store.AgencyRepository) {
+	ctx := context.Background()
+	saveTitle(t, titles, 1, 100, 10, "a", jan)
+	saveTitle(t, titles, 2, 200, 10, "b", jan)
+	saveTitle(t, titles, 3, 300, 10, "c", jan)
+	epa := upsertAgency(t, agencies, "Environmental Protection Agency", "epa", nil)
+	usda := upsertAgency(t, agencies, "Agriculture Department", "usda", nil)
+
+	for _, link := range []struct{ agency, title int }{{epa.ID, 3}, {epa.ID, 1}, {usda.ID, 1}} {
+		if err := agencies.LinkAgencyTitle(ctx, link.agency, link.title); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := agencies.LinkAgencyTitle(ctx, epa.ID, 3); err != nil {
+		t.Errorf("linking twice: %v", err)
+	}
+	if err := agencies.LinkAgencyTitle(ctx, epa.ID+100, 1); err == nil {
+		t.Error("linking a missing agency succeeded")
+	}
+	if err := agencies.LinkAgencyChapter(ctx, epa.ID, 40, "I"); err != nil {
+		t.Fatal(err)
+	}
+	if err := agencies.LinkAgencyChapter(ctx, epa.ID, 1, "V"); err != nil {
+		t.Fatal(err)
+	}
+
+	if got := must(agencies.GetAgencyTitles(ctx, epa.ID)); !sameInts(got, []int{1, 3}) {
+		t.Errorf("GetAgencyTitles = %v, want [1 3]", got)
+	}
+	var numbers []int
+	for _, title := range must(agencies.GetTitlesForAgency(ctx, epa.ID)) {
+		numbers = append(numbers, title.TitleNumber)
+	}
+	if !reflect.DeepEqual(numbers, []int{1, 3}) {
+		t.Errorf("GetTitlesForAgency = %v, want [1 3]", numbers)
+	}
+	var slugs []string
+	for _, a := range must(titles.GetAgenciesForTitle(ctx, 1)) {
+		slugs = append(slugs, a.Slug)
+	}
+	if !reflect.DeepEqual(slugs, []string{"usda", "epa"}) {
+		t.Errorf("GetAgenciesForTitle(1) = %v, want [usda epa] by name", slugs)
+	}
+	want := []model.CFRReference{{Title: 1, Chapter: "V"}, {Title: 40, Chapter: "I"}}
+	if got := must(agencies.GetChaptersForAgency(ctx, epa.ID)); !reflect.DeepEqual(got, want) {
+		t.Errorf("GetChaptersForAgency = %v, want %v", got, want)
+	}
+	if words := must(agencies.GetTitleWordCount(ctx, 3)); words != 300 {
+		t.Errorf("GetTitleWordCount(3) = %d, want 300", words)
+	}
+	if words := must(agencies.GetTitleWordCount(ctx, 9)); words != 0 {
+		t.Errorf("GetTitleWordCount(9) = %d, want 0", words)
+	}
+
+	if err := agencies.ClearAgencyTitles(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if got := must(agencies.GetAgencyTitles(ctx, epa.ID)); len(got) != 0 {
+		t.Errorf("GetAgencyTitles after clearing = %v", got)
+	}
+	if got := must(agencies.GetChaptersForAgency(ctx, epa.ID)); len(got) != 0 {
+		t.Errorf("GetChaptersForAgency after clearing = %v", got)
+	}
+}
+
+func testAgencyHierarchy(t *testing.T, titles store.TitleRepository, agencies store.AgencyRepository) {
+	ctx := context.Background()
+	saveTitle(t, titles, 7, 100, 10, "a", jan)
+	usda := upsertAgency(t, agencies, "Agriculture Department", "usda", nil)
+	upsertAgency(t, agencies, "Forest Service", "fs", usda)
+	ams := upsertAgency(t, agencies, "Agricultural Marketing Service", "ams", usda)
+	upsertAgency(t, agencies, "Commerce Department", "doc", nil)
+	if err := agencies.LinkAgencyTitle(ctx, ams.ID, 7); err != nil {
+		t.Fatal(err)
+	}
+
+	var got []string
+	for _, a := range must(agencies.GetAllHierarchical(ctx)) {
+		got = append(got, a.Slug+":"+string(rune('0'+a.Depth))+":"+string(rune('0'+a.TitleCount)))
+	}
+	want := []string{"usda:0:0", "ams:1:1", "fs:1:0", "doc:0:0"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("GetAllHierarchical = %v, want %v", got, want)
+	}
+
+	var children []string
+	for _, a := range must(agencies.GetChildren(ctx, usda.ID)) {
+		children = append(children, a.Slug)
+	}
+	if !reflect.DeepEqual(children, []string{"ams", "fs"}) {
+		t.Errorf("GetChildren = %v, want [ams fs]", children)
+	}
+
+	var all []string
+	for _, a := range must(agencies.GetAll(ctx)) {
+		all = append(all, a.Slug)
+	}
+	if !reflect.DeepEqual(all, []string{"ams", "usda", "doc", "fs"}) {
+		t.Errorf("GetAll = %v, want name order", all)
+	}
+}
+
+func testAgencySorting(t *testing.T, titles store.TitleRepository, agencies store.AgencyRepository) {
+	ctx := context.Background()
+	for n := 1; n <= 3; n++ {
+		saveTitle(t, titles, n, 100, 10, string(rune('a'+n)), jan)
+	}
+	for _, spec := range []struct {
+		name, slug  string
+		words, regs int
+		titles      []int
+	}{
+		{"Alpha", "alpha", 900, 3, []int{1}},
+		{"Bravo", "bravo", 100, 1, []int{1, 2, 3}},
+		{"Charlie", "charlie", 500, 1, []int{1, 2}},
+	} {
+		a := upsertAgency(t, agencies, spec.name, spec.slug, nil)
+		if err := agencies.UpdateWordCount(ctx, a.ID, spec.words, spec.regs, "sum"+spec.slug); err != nil {
+			t.Fatal(err)
+		}
+		for _, n := range spec.titles {
+			if err := agencies.LinkAgencyTitle(ctx, a.ID, n); err != nil {
+				t.Fatal(err)
+			}
+		}
+	}
+
+	for _, tc := range []struct {
+		sortBy, order string
+		want          []string
+	}{
+		{"name", "asc", []string{"alpha", "bravo", "charlie"}},
+		{"name", "desc", []string{"charlie", "bravo", "alpha"}},
+		{"title_count", "desc", []string{"bravo", "charlie", "alpha"}},
+		{"total_word_count", "asc", []string{"bravo", "charlie", "alpha"}},
+		{"total_word_count", "desc", []string{"alpha", "charlie", "bravo"}},
+	} {
+		var got []string
+		for _, a := range must(agencies.GetAllSorted(ctx, tc.sortBy, tc.order)) {
+			got = append(got, a.Slug)
+		}
+		if !reflect.DeepEqual(got, tc.want) {
+			t.Errorf("GetAllSorted(%q, %q) = %v, want %v", tc.sortBy, tc.order, got, tc.want)
+		}
+	}
+
+	charlie := must(agencies.GetBySlug(ctx, "charlie"))
+	if score := must(agencies.GetDensityScoreForAgency(ctx, charlie)); score != 1 {
+		t.Errorf("GetDensityScoreForAgency(charlie) = %v, want 1", score)
+	}
+}
+
+func testAgencyAsOf(t *testing.T, titles store.TitleRepository, agencies store.AgencyRepository) {
+	ctx := context.Background()
+	saveTitle(t, titles, 1, 100, 10, "a", jan)
+	saveTitle(t, titles, 2, 200, 10, "b", jan)
+	epa := upsertAgency(t, agencies, "Environmental Protection Agency", "epa", nil)
+	usda := upsertAgency(t, agencies, "Agriculture Department", "usda", nil)
+	snapshotAgency(t, agencies, epa, 100, "x", jan, 1)
+	snapshotAgency(t, agencies, epa, 300, "y", mar, 1, 2)
+	snapshotAgency(t, agencies, usda, 200, "z", mar, 2)
+
+	past := agencies.AsOf(feb)
+	if n := must(past.CountAgencies(ctx)); n != 1 {
+		t.Errorf("CountAgencies as of February = %d, want 1", n)
+	}
+	got := must(past.GetBySlug(ctx, "epa"))
+	if got == nil || got.TotalWordCount != 100 || got.Checksum != "x" {
+		t.Errorf("GetBySlug(epa) as of February = %+v, want the January snapshot", got)
+	}
+	if got := must(past.GetBySlug(ctx, "usda")); got != nil {
+		t.Errorf("GetBySlug(usda) as of February = %+v, want nil", got)
+	}
+	if linked := must(past.GetAgencyTitles(ctx, epa.ID)); !sameInts(linked, []int{1}) {
+		t.Errorf("GetAgencyTitles as of February = %v, want [1]", linked)
+	}
+	if snaps := must(past.GetSnapshotsForAgency(ctx, epa.ID)); len(snaps) != 1 {
+		t.Errorf("GetSnapshotsForAgency as of February returned %d, want 1", len(snaps))
+	}
+	if got := dates(must(agencies.GetAgencySnapshotDates(ctx))); !reflect.DeepEqual(got, []string{"2024-03-01", "2024-01-01"}) {
+		t.Errorf("GetAgencySnapshotDates = %v, want newest first", got)
+	}
+	if got := dates(must(past.GetAgencySnapshotDates(ctx))); !reflect.DeepEqual(got, []string{"2024-01-01"}) {
+		t.Errorf("GetAgencySnapshotDates as of February = %v", got)
+	}
+}
+
+func sameInts(a, b []int) bool {
+	seen := make(map[int]int)
+	for _, n := range a {
+		seen[n]++
+	}
+	for _, n := range b {
+		seen[n]--
+	}
+	for _, c := range seen {
+		if c != 0 {
+			return false
+		}
+	}
+	return len(a) == len(b)
+}
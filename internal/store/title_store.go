@@ -7,6 +7,7 @@ import (
 	"sort"
 	"time"
 
+	"github.com/jjenkins/usds/internal/cache"
 	"github.com/jjenkins/usds/internal/model"
 )
 
@@ -52,7 +53,9 @@ func calculateTitleDensityScores(titles []TitleWithDensity) {
 
 // TitleStore handles database operations for titles
 type TitleStore struct {
-	db *sql.DB
+	db    *sql.DB
+	asOf  time.Time    // Zero for the current state
+	cache *cache.Cache // Nil to always read the database
 }
 
 // NewTitleStore creates a new TitleStore
@@ -60,14 +63,30 @@ func NewTitleStore(db *sql.DB) *TitleStore {
 	return &TitleStore{db: db}
 }
 
+// WithCache returns a TitleStore whose aggregate reads are kept in c until the
+// data version changes
+func (s *TitleStore) WithCache(c *cache.Cache) *TitleStore {
+	return &TitleStore{db: s.db, asOf: s.asOf, cache: c}
+}
+
+// AsOf returns a TitleStore whose reads reconstruct titles and agencies from
+// the latest snapshots on or before the given date. A zero date reads the
+// current state. Writes are unaffected.
+func (s *TitleStore) AsOf(date time.Time) TitleRepository {
+	if date.IsZero() {
+		return s
+	}
+	return &TitleStore{db: s.db, asOf: date, cache: s.cache}
+}
+
 // GetByNumber retrieves a title by its number
 func (s *TitleStore) GetByNumber(ctx context.Context, titleNumber int) (*model.Title, error) {
-	query := `
+	query := fmt.Sprintf(`
 		SELECT id, title_number, title_name, word_count, section_count,
-		       checksum, last_amended_date, fetched_at, created_at
-		FROM titles
+		       readability_score, checksum, last_amended_date, fetched_at, created_at
+		FROM %s AS titles
 		WHERE title_number = $1
-	`
+	`, titlesSource(s.asOf))
 
 	var t model.Title
 	err := s.db.QueryRowContext(ctx, query, titleNumber).Scan(
@@ -76,6 +95,7 @@ func (s *TitleStore) GetByNumber(ctx context.Context, titleNumber int) (*model.T
 		&t.TitleName,
 		&t.WordCount,
 		&t.SectionCount,
+		&t.Readability,
 		&t.Checksum,
 		&t.LastAmendedDate,
 		&t.FetchedAt,
@@ -95,12 +115,13 @@ func (s *TitleStore) GetByNumber(ctx context.Context, titleNumber int) (*model.T
 func (s *TitleStore) UpsertTitle(ctx context.Context, t *model.Title) error {
 	query := `
 		INSERT INTO titles (title_number, title_name, word_count, section_count,
-		                    checksum, last_amended_date, fetched_at)
-		VALUES ($1, $2, $3, $4, $5, $6, $7)
+		                    readability_score, checksum, last_amended_date, fetched_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
 		ON CONFLICT (title_number) DO UPDATE SET
 			title_name = EXCLUDED.title_name,
 			word_count = EXCLUDED.word_count,
 			section_count = EXCLUDED.section_count,
+			readability_score = EXCLUDED.readability_score,
 			checksum = EXCLUDED.checksum,
 			last_amended_date = EXCLUDED.last_amended_date,
 			fetched_at = EXCLUDED.fetched_at
@@ -112,8 +133,9 @@ func (s *TitleStore) UpsertTitle(ctx context.Context, t *model.Title) error {
 		t.TitleName,
 		t.WordCount,
 		t.SectionCount,
+		t.Readability,
 		t.Checksum,
-		t.LastAmendedDate,
+		sqlNullDate(t.LastAmendedDate),
 		t.FetchedAt,
 	).Scan(&t.ID)
 
@@ -124,16 +146,27 @@ func (s *TitleStore) UpsertTitle(ctx context.Context, t *model.Title) error {
 	return nil
 }
 
+// MarkFetched records that a title was checked against the eCFR and found
+// current, without rewriting its content
+func (s *TitleStore) MarkFetched(ctx context.Context, titleNumber int) error {
+	_, err := s.db.ExecContext(ctx, `UPDATE titles SET fetched_at = CURRENT_TIMESTAMP WHERE title_number = $1`, titleNumber)
+	if err != nil {
+		return fmt.Errorf("failed to mark title %d fetched: %w", titleNumber, err)
+	}
+	return nil
+}
+
 // InsertSnapshot inserts a title snapshot
 func (s *TitleStore) InsertSnapshot(ctx context.Context, snap *model.TitleSnapshot) error {
 	query := `
-		INSERT INTO title_snapshots (title_number, title_name, word_count,
-		                             section_count, checksum, last_amended_date, snapshot_date)
-		VALUES ($1, $2, $3, $4, $5, $6, $7)
+		INSERT INTO title_snapshots (title_number, title_name, word_count, section_count,
+		                             readability_score, checksum, last_amended_date, snapshot_date)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
 		ON CONFLICT (title_number, snapshot_date) DO UPDATE SET
 			title_name = EXCLUDED.title_name,
 			word_count = EXCLUDED.word_count,
 			section_count = EXCLUDED.section_count,
+			readability_score = EXCLUDED.readability_score,
 			checksum = EXCLUDED.checksum,
 			last_amended_date = EXCLUDED.last_amended_date
 		RETURNING id
@@ -144,9 +177,10 @@ func (s *TitleStore) InsertSnapshot(ctx context.Context, snap *model.TitleSnapsh
 		snap.TitleName,
 		snap.WordCount,
 		snap.SectionCount,
+		snap.Readability,
 		snap.Checksum,
-		snap.LastAmendedDate,
-		snap.SnapshotDate,
+		sqlNullDate(snap.LastAmendedDate),
+		sqlDate(snap.SnapshotDate),
 	).Scan(&snap.ID)
 
 	if err != nil {
@@ -156,7 +190,8 @@ func (s *TitleStore) InsertSnapshot(ctx context.Context, snap *model.TitleSnapsh
 	return nil
 }
 
-// SaveTitleWithSnapshot saves the current title and only creates a snapshot if content changed
+// SaveTitleWithSnapshot saves the current title and only creates a snapshot if content changed.
+// A version older than the title's latest snapshot only adds its snapshot.
 func (s *TitleStore) SaveTitleWithSnapshot(ctx context.Context, t *model.Title, snapshotDate time.Time) (changed bool, err error) {
 	tx, err := s.db.BeginTx(ctx, nil)
 	if err != nil {
@@ -168,53 +203,95 @@ func (s *TitleStore) SaveTitleWithSnapshot(ctx context.Context, t *model.Title,
 	// This allows re-imports of the same date to be idempotent, while ensuring
 	// historical imports for different dates always create snapshots
 	var existingChecksum sql.NullString
+	var existingWords, existingSections int
 	checksumQuery := `
-		SELECT checksum FROM title_snapshots
+		SELECT checksum, word_count, section_count FROM title_snapshots
 		WHERE title_number = $1 AND snapshot_date = $2
 	`
-	tx.QueryRowContext(ctx, checksumQuery, t.TitleNumber, snapshotDate).Scan(&existingChecksum)
+	tx.QueryRowContext(ctx, checksumQuery, t.TitleNumber, sqlDate(snapshotDate)).
+		Scan(&existingChecksum, &existingWords, &existingSections)
 
 	// Create snapshot if: no snapshot exists for this date, OR checksum differs (re-import with changes)
 	changed = !existingChecksum.Valid || existingChecksum.String != t.Checksum
 
-	// Upsert title (always update current state)
+	// The same content counted differently means the parser's counting
+	// rules changed, so the snapshot is recounted in place rather than
+	// recorded as a change
+	if !changed && (existingWords != t.WordCount || existingSections != t.SectionCount) {
+		recountQuery := `
+			UPDATE title_snapshots SET word_count = $3, section_count = $4, readability_score = $5
+			WHERE title_number = $1 AND snapshot_date = $2
+		`
+		_, err := tx.ExecContext(ctx, recountQuery, t.TitleNumber, sqlDate(snapshotDate), t.WordCount, t.SectionCount, t.Readability)
+		if err != nil {
+			return false, fmt.Errorf("failed to recount snapshot for title %d: %w", t.TitleNumber, err)
+		}
+	}
+
+	// A version older than the title's latest snapshot is history: it gets
+	// a snapshot but must not replace the current title
+	var historical bool
+	laterQuery := `
+		SELECT EXISTS (
+			SELECT 1 FROM title_snapshots
+			WHERE title_number = $1 AND snapshot_date > $2
+		)
+	`
+	if err := tx.QueryRowContext(ctx, laterQuery, t.TitleNumber, sqlDate(snapshotDate)).Scan(&historical); err != nil {
+		return false, fmt.Errorf("failed to check later snapshots for title %d: %w", t.TitleNumber, err)
+	}
+	if historical {
+		err = tx.QueryRowContext(ctx, `SELECT id FROM titles WHERE title_number = $1`, t.TitleNumber).Scan(&t.ID)
+		if err == sql.ErrNoRows {
+			historical = false
+		} else if err != nil {
+			return false, fmt.Errorf("failed to get title %d: %w", t.TitleNumber, err)
+		}
+	}
+
+	// Upsert title to the current state
 	upsertQuery := `
 		INSERT INTO titles (title_number, title_name, word_count, section_count,
-		                    checksum, last_amended_date, fetched_at)
-		VALUES ($1, $2, $3, $4, $5, $6, $7)
+		                    readability_score, checksum, last_amended_date, fetched_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
 		ON CONFLICT (title_number) DO UPDATE SET
 			title_name = EXCLUDED.title_name,
 			word_count = EXCLUDED.word_count,
 			section_count = EXCLUDED.section_count,
+			readability_score = EXCLUDED.readability_score,
 			checksum = EXCLUDED.checksum,
 			last_amended_date = EXCLUDED.last_amended_date,
 			fetched_at = EXCLUDED.fetched_at
 		RETURNING id
 	`
 
-	err = tx.QueryRowContext(ctx, upsertQuery,
-		t.TitleNumber,
-		t.TitleName,
-		t.WordCount,
-		t.SectionCount,
-		t.Checksum,
-		t.LastAmendedDate,
-		t.FetchedAt,
-	).Scan(&t.ID)
-	if err != nil {
-		return false, fmt.Errorf("failed to upsert title %d: %w", t.TitleNumber, err)
+	if !historical {
+		err = tx.QueryRowContext(ctx, upsertQuery,
+			t.TitleNumber,
+			t.TitleName,
+			t.WordCount,
+			t.SectionCount,
+			t.Readability,
+			t.Checksum,
+			sqlNullDate(t.LastAmendedDate),
+			t.FetchedAt,
+		).Scan(&t.ID)
+		if err != nil {
+			return false, fmt.Errorf("failed to upsert title %d: %w", t.TitleNumber, err)
+		}
 	}
 
 	// Only insert snapshot if content changed
 	if changed {
 		snapshotQuery := `
-			INSERT INTO title_snapshots (title_number, title_name, word_count,
-			                             section_count, checksum, last_amended_date, snapshot_date)
-			VALUES ($1, $2, $3, $4, $5, $6, $7)
+			INSERT INTO title_snapshots (title_number, title_name, word_count, section_count,
+			                             readability_score, checksum, last_amended_date, snapshot_date)
+			VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
 			ON CONFLICT (title_number, snapshot_date) DO UPDATE SET
 				title_name = EXCLUDED.title_name,
 				word_count = EXCLUDED.word_count,
 				section_count = EXCLUDED.section_count,
+				readability_score = EXCLUDED.readability_score,
 				checksum = EXCLUDED.checksum,
 				last_amended_date = EXCLUDED.last_amended_date
 		`
@@ -224,13 +301,18 @@ func (s *TitleStore) SaveTitleWithSnapshot(ctx context.Context, t *model.Title,
 			t.TitleName,
 			t.WordCount,
 			t.SectionCount,
+			t.Readability,
 			t.Checksum,
-			t.LastAmendedDate,
-			snapshotDate,
+			sqlNullDate(t.LastAmendedDate),
+			sqlDate(snapshotDate),
 		)
 		if err != nil {
 			return false, fmt.Errorf("failed to insert snapshot for title %d: %w", t.TitleNumber, err)
 		}
+
+		if err := recordTitleChange(ctx, tx, t, snapshotDate); err != nil {
+			return false, err
+		}
 	}
 
 	if err := tx.Commit(); err != nil {
@@ -242,12 +324,12 @@ func (s *TitleStore) SaveTitleWithSnapshot(ctx context.Context, t *model.Title,
 
 // GetAll retrieves all titles ordered by title number (excludes full_content for performance)
 func (s *TitleStore) GetAll(ctx context.Context) ([]model.Title, error) {
-	query := `
+	query := fmt.Sprintf(`
 		SELECT id, title_number, title_name, word_count, section_count,
 		       checksum, last_amended_date, fetched_at, created_at
-		FROM titles
+		FROM %s AS titles
 		ORDER BY title_number
-	`
+	`, titlesSource(s.asOf))
 
 	rows, err := s.db.QueryContext(ctx, query)
 	if err != nil {
@@ -278,33 +360,40 @@ func (s *TitleStore) GetAll(ctx context.Context) ([]model.Title, error) {
 	return titles, rows.Err()
 }
 
-// GetAllSorted retrieves all titles with custom sorting (excludes full_content for performance)
-func (s *TitleStore) GetAllSorted(ctx context.Context, sortBy, order string) ([]model.Title, error) {
-	// Whitelist valid sort columns to prevent SQL injection
-	validColumns := map[string]string{
-		"number":        "title_number",
-		"name":          "title_name",
-		"word_count":    "word_count",
-		"section_count": "section_count",
-		"last_amended":  "last_amended_date",
-	}
+// titleSortColumns whitelists the columns titles can be sorted by, keeping
+// request values out of SQL
+var titleSortColumns = map[string]string{
+	"number":        "title_number",
+	"name":          "title_name",
+	"word_count":    "word_count",
+	"section_count": "section_count",
+	"last_amended":  "last_amended_date",
+}
 
-	column, ok := validColumns[sortBy]
+// titleSort maps a requested sort and order to the column and direction the
+// query uses, defaulting unknown values, so that arbitrary requests share
+// cache entries rather than each adding one
+func titleSort(sortBy, order string) (column, direction string) {
+	column, ok := titleSortColumns[sortBy]
 	if !ok {
 		column = "title_number"
 	}
-
-	sortOrder := "ASC"
 	if order == "desc" {
-		sortOrder = "DESC"
+		return column, "DESC"
 	}
+	return column, "ASC"
+}
+
+// GetAllSorted retrieves all titles with custom sorting (excludes full_content for performance)
+func (s *TitleStore) GetAllSorted(ctx context.Context, sortBy, order string) ([]model.Title, error) {
+	column, sortOrder := titleSort(sortBy, order)
 
 	query := fmt.Sprintf(`
 		SELECT id, title_number, title_name, word_count, section_count,
 		       checksum, last_amended_date, fetched_at, created_at
-		FROM titles
-		ORDER BY %s %s
-	`, column, sortOrder)
+		FROM %s AS titles
+		ORDER BY %s %s NULLS LAST, title_number
+	`, titlesSource(s.asOf), column, sortOrder)
 
 	rows, err := s.db.QueryContext(ctx, query)
 	if err != nil {
@@ -337,13 +426,13 @@ func (s *TitleStore) GetAllSorted(ctx context.Context, sortBy, order string) ([]
 
 // GetSnapshots retrieves all snapshots for a title ordered by date descending
 func (s *TitleStore) GetSnapshots(ctx context.Context, titleNumber int) ([]model.TitleSnapshot, error) {
-	query := `
+	query := fmt.Sprintf(`
 		SELECT id, title_number, title_name, word_count, section_count,
-		       checksum, last_amended_date, snapshot_date, created_at
+		       readability_score, checksum, last_amended_date, snapshot_date, created_at
 		FROM title_snapshots
-		WHERE title_number = $1
+		WHERE title_number = $1 AND %s
 		ORDER BY snapshot_date DESC
-	`
+	`, snapshotDateFilter(s.asOf))
 
 	rows, err := s.db.QueryContext(ctx, query, titleNumber)
 	if err != nil {
@@ -360,6 +449,7 @@ func (s *TitleStore) GetSnapshots(ctx context.Context, titleNumber int) ([]model
 			&snap.TitleName,
 			&snap.WordCount,
 			&snap.SectionCount,
+			&snap.Readability,
 			&snap.Checksum,
 			&snap.LastAmendedDate,
 			&snap.SnapshotDate,
@@ -376,14 +466,14 @@ func (s *TitleStore) GetSnapshots(ctx context.Context, titleNumber int) ([]model
 
 // GetAgenciesForTitle retrieves all agencies linked to a title
 func (s *TitleStore) GetAgenciesForTitle(ctx context.Context, titleNumber int) ([]model.Agency, error) {
-	query := `
+	query := fmt.Sprintf(`
 		SELECT a.id, a.agency_name, a.short_name, a.slug, a.parent_id,
 		       a.total_word_count, a.regulation_count, a.checksum, a.updated_at
-		FROM agencies a
-		INNER JOIN agency_titles at ON a.id = at.agency_id
+		FROM %s AS a
+		INNER JOIN %s AS at ON a.id = at.agency_id
 		WHERE at.title_number = $1
 		ORDER BY a.agency_name
-	`
+	`, agenciesSource(s.asOf), agencyTitlesSource(s.asOf))
 
 	rows, err := s.db.QueryContext(ctx, query, titleNumber)
 	if err != nil {
@@ -416,8 +506,14 @@ func (s *TitleStore) GetAgenciesForTitle(ctx context.Context, titleNumber int) (
 
 // CountTitles returns the total number of titles
 func (s *TitleStore) CountTitles(ctx context.Context) (int, error) {
+	return cache.Get(ctx, s.cache, cacheKey(s.asOf, "TitleStore.CountTitles"), func() (int, error) {
+		return s.countTitles(ctx)
+	})
+}
+
+func (s *TitleStore) countTitles(ctx context.Context) (int, error) {
 	var count int
-	err := s.db.QueryRowContext(ctx, "SELECT COUNT(*) FROM titles").Scan(&count)
+	err := s.db.QueryRowContext(ctx, fmt.Sprintf("SELECT COUNT(*) FROM %s AS titles", titlesSource(s.asOf))).Scan(&count)
 	if err != nil {
 		return 0, fmt.Errorf("failed to count titles: %w", err)
 	}
@@ -426,8 +522,14 @@ func (s *TitleStore) CountTitles(ctx context.Context) (int, error) {
 
 // GetTotalWordCount returns the sum of all word counts
 func (s *TitleStore) GetTotalWordCount(ctx context.Context) (int, error) {
+	return cache.Get(ctx, s.cache, cacheKey(s.asOf, "TitleStore.GetTotalWordCount"), func() (int, error) {
+		return s.getTotalWordCount(ctx)
+	})
+}
+
+func (s *TitleStore) getTotalWordCount(ctx context.Context) (int, error) {
 	var total int
-	err := s.db.QueryRowContext(ctx, "SELECT COALESCE(SUM(word_count), 0) FROM titles").Scan(&total)
+	err := s.db.QueryRowContext(ctx, fmt.Sprintf("SELECT COALESCE(SUM(word_count), 0) FROM %s AS titles", titlesSource(s.asOf))).Scan(&total)
 	if err != nil {
 		return 0, fmt.Errorf("failed to get total word count: %w", err)
 	}
@@ -436,8 +538,14 @@ func (s *TitleStore) GetTotalWordCount(ctx context.Context) (int, error) {
 
 // GetAverageDensity returns the average regulatory density (words per section)
 func (s *TitleStore) GetAverageDensity(ctx context.Context) (float64, error) {
+	return cache.Get(ctx, s.cache, cacheKey(s.asOf, "TitleStore.GetAverageDensity"), func() (float64, error) {
+		return s.getAverageDensity(ctx)
+	})
+}
+
+func (s *TitleStore) getAverageDensity(ctx context.Context) (float64, error) {
 	var avg float64
-	query := `SELECT COALESCE(AVG(CASE WHEN section_count > 0 THEN word_count::float / section_count ELSE 0 END), 0) FROM titles`
+	query := fmt.Sprintf(`SELECT COALESCE(AVG(CASE WHEN section_count > 0 THEN CAST(word_count AS DOUBLE PRECISION) / section_count ELSE 0 END), 0) FROM %s AS titles`, titlesSource(s.asOf))
 	err := s.db.QueryRowContext(ctx, query).Scan(&avg)
 	if err != nil {
 		return 0, fmt.Errorf("failed to get average density: %w", err)
@@ -447,31 +555,20 @@ func (s *TitleStore) GetAverageDensity(ctx context.Context) (float64, error) {
 
 // GetAllSortedWithDensity retrieves all titles with density scores
 func (s *TitleStore) GetAllSortedWithDensity(ctx context.Context, sortBy, order string) ([]TitleWithDensity, error) {
-	// Whitelist valid sort columns to prevent SQL injection
-	validColumns := map[string]string{
-		"number":        "title_number",
-		"name":          "title_name",
-		"word_count":    "word_count",
-		"section_count": "section_count",
-		"last_amended":  "last_amended_date",
-	}
-
-	column, ok := validColumns[sortBy]
-	if !ok {
-		column = "title_number"
-	}
+	column, sortOrder := titleSort(sortBy, order)
+	return cache.Get(ctx, s.cache, cacheKey(s.asOf, "TitleStore.GetAllSortedWithDensity", column, sortOrder), func() ([]TitleWithDensity, error) {
+		return s.getAllSortedWithDensity(ctx, column, sortOrder)
+	})
+}
 
-	sortOrder := "ASC"
-	if order == "desc" {
-		sortOrder = "DESC"
-	}
+func (s *TitleStore) getAllSortedWithDensity(ctx context.Context, column, sortOrder string) ([]TitleWithDensity, error) {
 
 	query := fmt.Sprintf(`
 		SELECT id, title_number, title_name, word_count, section_count,
 		       checksum, last_amended_date, fetched_at, created_at
-		FROM titles
-		ORDER BY %s %s
-	`, column, sortOrder)
+		FROM %s AS titles
+		ORDER BY %s %s NULLS LAST, title_number
+	`, titlesSource(s.asOf), column, sortOrder)
 
 	rows, err := s.db.QueryContext(ctx, query)
 	if err != nil {
@@ -518,11 +615,11 @@ func (s *TitleStore) GetDensityScoreForTitle(ctx context.Context, title *model.T
 	titleDensity := float64(title.WordCount) / float64(title.SectionCount)
 
 	// Count how many titles have lower density
-	query := `
-		SELECT COUNT(*) FROM titles
+	query := fmt.Sprintf(`
+		SELECT COUNT(*) FROM %s AS titles
 		WHERE section_count > 0
-		AND (word_count::float / section_count::float) < $1
-	`
+		AND (CAST(word_count AS DOUBLE PRECISION) / section_count) < $1
+	`, titlesSource(s.asOf))
 	var lowerCount int
 	if err := s.db.QueryRowContext(ctx, query, titleDensity).Scan(&lowerCount); err != nil {
 		return 0, err
@@ -530,7 +627,7 @@ func (s *TitleStore) GetDensityScoreForTitle(ctx context.Context, title *model.T
 
 	// Count total titles with density
 	var totalCount int
-	if err := s.db.QueryRowContext(ctx, "SELECT COUNT(*) FROM titles WHERE section_count > 0").Scan(&totalCount); err != nil {
+	if err := s.db.QueryRowContext(ctx, fmt.Sprintf("SELECT COUNT(*) FROM %s AS titles WHERE section_count > 0", titlesSource(s.asOf))).Scan(&totalCount); err != nil {
 		return 0, err
 	}
 
@@ -543,7 +640,13 @@ func (s *TitleStore) GetDensityScoreForTitle(ctx context.Context, title *model.T
 
 // GetSnapshotDates returns all unique snapshot dates
 func (s *TitleStore) GetSnapshotDates(ctx context.Context) ([]time.Time, error) {
-	query := `SELECT DISTINCT snapshot_date FROM title_snapshots ORDER BY snapshot_date DESC`
+	return cache.Get(ctx, s.cache, cacheKey(s.asOf, "TitleStore.GetSnapshotDates"), func() ([]time.Time, error) {
+		return s.getSnapshotDates(ctx)
+	})
+}
+
+func (s *TitleStore) getSnapshotDates(ctx context.Context) ([]time.Time, error) {
+	query := fmt.Sprintf(`SELECT DISTINCT snapshot_date FROM title_snapshots WHERE %s ORDER BY snapshot_date DESC`, snapshotDateFilter(s.asOf))
 	rows, err := s.db.QueryContext(ctx, query)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get snapshot dates: %w", err)
@@ -561,3 +664,52 @@ func (s *TitleStore) GetSnapshotDates(ctx context.Context) ([]time.Time, error)
 
 	return dates, rows.Err()
 }
+
+// SnapshotTotals holds totals across all titles as they stood on a snapshot date
+type SnapshotTotals struct {
+	Date         time.Time
+	TitleCount   int
+	WordCount    int
+	SectionCount int
+}
+
+// GetSnapshotTotals reconstructs the title totals on each snapshot date from
+// each title's latest snapshot on or before that date, oldest first
+func (s *TitleStore) GetSnapshotTotals(ctx context.Context) ([]SnapshotTotals, error) {
+	return cache.Get(ctx, s.cache, cacheKey(s.asOf, "TitleStore.GetSnapshotTotals"), func() ([]SnapshotTotals, error) {
+		return s.getSnapshotTotals(ctx)
+	})
+}
+
+func (s *TitleStore) getSnapshotTotals(ctx context.Context) ([]SnapshotTotals, error) {
+	query := fmt.Sprintf(`
+		WITH dates AS (
+			SELECT DISTINCT snapshot_date FROM title_snapshots WHERE %s
+		)
+		SELECT d.snapshot_date, COUNT(*), COALESCE(SUM(ts.word_count), 0), COALESCE(SUM(ts.section_count), 0)
+		FROM dates d
+		INNER JOIN title_snapshots ts ON ts.snapshot_date = (
+			SELECT MAX(latest.snapshot_date) FROM title_snapshots latest
+			WHERE latest.title_number = ts.title_number AND latest.snapshot_date <= d.snapshot_date
+		)
+		GROUP BY d.snapshot_date
+		ORDER BY d.snapshot_date
+	`, snapshotDateFilter(s.asOf))
+
+	rows, err := s.db.QueryContext(ctx, query)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get snapshot totals: %w", err)
+	}
+	defer rows.Close()
+
+	var totals []SnapshotTotals
+	for rows.Next() {
+		var t SnapshotTotals
+		if err := rows.Scan(&t.Date, &t.TitleCount, &t.WordCount, &t.SectionCount); err != nil {
+			return nil, fmt.Errorf("failed to scan snapshot totals: %w", err)
+		}
+		totals = append(totals, t)
+	}
+
+	return totals, rows.Err()
+}
@@ -0,0 +1,319 @@
+package store
+
+import (
+	"context"
+	"crypto/md5"
+	"database/sql"
+	"encoding/hex"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/jjenkins/usds/internal/model"
+)
+
+// WatchlistStore handles the titles, agencies and sections each user watches
+type WatchlistStore struct {
+	db *sql.DB
+}
+
+// NewWatchlistStore creates a new WatchlistStore
+func NewWatchlistStore(db *sql.DB) *WatchlistStore {
+	return &WatchlistStore{db: db}
+}
+
+// watchTargetMatch matches the watchlist item "w" for the user and target
+// given by targetArgs as $1 to $5
+const watchTargetMatch = `
+	w.user_id = $1 AND w.entity_type = $2
+	AND COALESCE(w.title_number, 0) = $3
+	AND w.agency_id IS NOT DISTINCT FROM (SELECT id FROM agencies WHERE slug = NULLIF($4, ''))
+	AND COALESCE(w.section_identifier, '') = $5
+`
+
+func targetArgs(userID string, t model.WatchTarget) []any {
+	return []any{userID, t.EntityType, t.TitleNumber, t.AgencySlug, t.Section}
+}
+
+// watchlistItemsQuery selects user $1's watchlist items, filled in with the
+// current name, word count and checksum of watched titles and agencies and
+// their latest change event. Matching the latest event's ID picks one row
+// without a lateral join. Watched sections are filled in by sectionStates.
+const watchlistItemsQuery = `
+	SELECT w.id, w.entity_type, COALESCE(w.title_number, 0), COALESCE(a.slug, ''),
+	       COALESCE(w.section_identifier, ''),
+	       CASE w.entity_type
+	           WHEN 'title' THEN COALESCE('Title ' || t.title_number || ': ' || t.title_name, 'Title ' || w.title_number)
+	           WHEN 'agency' THEN COALESCE(a.agency_name, '')
+	           ELSE ''
+	       END AS name,
+	       COALESCE(CASE w.entity_type
+	           WHEN 'title' THEN t.word_count
+	           WHEN 'agency' THEN a.total_word_count
+	       END, 0) AS word_count,
+	       COALESCE(CASE w.entity_type
+	           WHEN 'title' THEN t.checksum
+	           WHEN 'agency' THEN a.checksum
+	       END, '') AS checksum,
+	       w.seen_word_count, w.seen_checksum, w.seen_at,
+	       ch.snapshot_date, ch.word_count - COALESCE(ch.previous_word_count, 0),
+	       w.created_at
+	FROM watchlist_items w
+	LEFT JOIN titles t ON w.entity_type = 'title' AND t.title_number = w.title_number
+	LEFT JOIN agencies a ON a.id = w.agency_id
+	LEFT JOIN change_events ch ON ch.id = (
+		SELECT e.id FROM change_events e
+		WHERE (w.entity_type = 'title' AND e.entity_type = 'title' AND e.title_number = w.title_number)
+		   OR (w.entity_type = 'agency' AND e.entity_type = 'agency' AND e.agency_id = w.agency_id)
+		ORDER BY e.snapshot_date DESC, e.id DESC
+		LIMIT 1
+	)
+	WHERE w.user_id = $1
+`
+
+// List returns the user's watchlist, entities that changed since the user
+// last looked first, then the most recently changed
+func (s *WatchlistStore) List(ctx context.Context, userID string) ([]model.WatchlistItem, error) {
+	items, err := s.currentItems(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	sort.SliceStable(items, func(i, j int) bool {
+		a, b := items[i], items[j]
+		if a.ChangedSinceSeen() != b.ChangedSinceSeen() {
+			return a.ChangedSinceSeen()
+		}
+		if a.LastChangedOn.Valid != b.LastChangedOn.Valid {
+			return a.LastChangedOn.Valid
+		}
+		if !a.LastChangedOn.Time.Equal(b.LastChangedOn.Time) {
+			return a.LastChangedOn.Time.After(b.LastChangedOn.Time)
+		}
+		return a.Name < b.Name
+	})
+	return items, nil
+}
+
+// currentItems returns the user's watchlist items in their current state
+func (s *WatchlistStore) currentItems(ctx context.Context, userID string) ([]model.WatchlistItem, error) {
+	rows, err := s.db.QueryContext(ctx, watchlistItemsQuery, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list watchlist: %w", err)
+	}
+	defer rows.Close()
+
+	var items []model.WatchlistItem
+	for rows.Next() {
+		var i model.WatchlistItem
+		err := rows.Scan(
+			&i.ID,
+			&i.EntityType,
+			&i.TitleNumber,
+			&i.AgencySlug,
+			&i.Section,
+			&i.Name,
+			&i.WordCount,
+			&i.Checksum,
+			&i.SeenWordCount,
+			&i.SeenChecksum,
+			&i.SeenAt,
+			&i.LastChangedOn,
+			&i.LastChange,
+			&i.CreatedAt,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan watchlist item: %w", err)
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to list watchlist: %w", err)
+	}
+	rows.Close()
+
+	for idx := range items {
+		if items[idx].EntityType == model.WatchEntitySection {
+			if err := s.sectionState(ctx, &items[idx]); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	return items, nil
+}
+
+// sectionState fills in a watched section's heading, word count and
+// checksum from its latest snapshot, and its latest change from the latest
+// snapshot whose text differs from the one before. Words are counted the way
+// the parser counts title words, splitting on whitespace, and the checksum
+// is the MD5 of the text.
+func (s *WatchlistStore) sectionState(ctx context.Context, i *model.WatchlistItem) error {
+	query := `
+		SELECT snapshot_date, COALESCE(heading, ''), text
+		FROM sections
+		WHERE title_number = $1 AND identifier = $2
+		ORDER BY snapshot_date
+	`
+
+	rows, err := s.db.QueryContext(ctx, query, i.TitleNumber, i.Section)
+	if err != nil {
+		return fmt.Errorf("failed to get versions of %s: %w", i.Key(), err)
+	}
+	defer rows.Close()
+
+	i.Name = i.Section
+	previous := -1
+	for rows.Next() {
+		var date time.Time
+		var heading, text string
+		if err := rows.Scan(&date, &heading, &text); err != nil {
+			return fmt.Errorf("failed to scan version of %s: %w", i.Key(), err)
+		}
+
+		hash := md5.Sum([]byte(text))
+		checksum := hex.EncodeToString(hash[:])
+		words := len(strings.Fields(text))
+		if previous >= 0 && checksum != i.Checksum {
+			i.LastChangedOn = sql.NullTime{Time: date, Valid: true}
+			i.LastChange = sql.NullInt64{Int64: int64(words - previous), Valid: true}
+		}
+
+		i.Name = heading
+		if heading == "" {
+			i.Name = i.Section
+		}
+		i.WordCount, i.Checksum, previous = words, checksum, words
+	}
+
+	return rows.Err()
+}
+
+// Watch adds the target to the user's watchlist, treating its current state
+// as seen. Watching an already watched target does nothing.
+func (s *WatchlistStore) Watch(ctx context.Context, userID string, t model.WatchTarget) error {
+	if t.EntityType == model.ChangeEntityAgency {
+		var exists bool
+		if err := s.db.QueryRowContext(ctx, `SELECT EXISTS (SELECT 1 FROM agencies WHERE slug = $1)`, t.AgencySlug).Scan(&exists); err != nil {
+			return fmt.Errorf("failed to get agency %s: %w", t.AgencySlug, err)
+		}
+		if !exists {
+			return fmt.Errorf("agency %s not found", t.AgencySlug)
+		}
+	}
+
+	query := `
+		INSERT INTO watchlist_items (user_id, entity_type, title_number, agency_id, section_identifier)
+		VALUES ($1, $2, NULLIF($3, 0), (SELECT id FROM agencies WHERE slug = NULLIF($4, '')), NULLIF($5, ''))
+		ON CONFLICT (user_id, entity_type, COALESCE(title_number, 0), COALESCE(agency_id, 0), COALESCE(section_identifier, ''))
+		DO NOTHING
+		RETURNING id
+	`
+
+	var id int
+	err := s.db.QueryRowContext(ctx, query, targetArgs(userID, t)...).Scan(&id)
+	if err == sql.ErrNoRows {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to watch %s: %w", t.Key(), err)
+	}
+
+	return s.MarkSeen(ctx, userID, t)
+}
+
+// Unwatch removes the target from the user's watchlist
+func (s *WatchlistStore) Unwatch(ctx context.Context, userID string, t model.WatchTarget) error {
+	query := `DELETE FROM watchlist_items AS w WHERE ` + watchTargetMatch
+	if _, err := s.db.ExecContext(ctx, query, targetArgs(userID, t)...); err != nil {
+		return fmt.Errorf("failed to unwatch %s: %w", t.Key(), err)
+	}
+	return nil
+}
+
+// IsWatching reports whether the target is on the user's watchlist
+func (s *WatchlistStore) IsWatching(ctx context.Context, userID string, t model.WatchTarget) (bool, error) {
+	query := `SELECT EXISTS (SELECT 1 FROM watchlist_items w WHERE ` + watchTargetMatch + `)`
+
+	var watching bool
+	if err := s.db.QueryRowContext(ctx, query, targetArgs(userID, t)...).Scan(&watching); err != nil {
+		return false, fmt.Errorf("failed to check watchlist for %s: %w", t.Key(), err)
+	}
+	return watching, nil
+}
+
+// WatchedKeys returns the WatchTarget keys of everything the user watches
+func (s *WatchlistStore) WatchedKeys(ctx context.Context, userID string) (map[string]bool, error) {
+	query := `
+		SELECT w.entity_type, COALESCE(w.title_number, 0), COALESCE(a.slug, ''), COALESCE(w.section_identifier, '')
+		FROM watchlist_items w
+		LEFT JOIN agencies a ON a.id = w.agency_id
+		WHERE w.user_id = $1
+	`
+
+	rows, err := s.db.QueryContext(ctx, query, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list watched keys: %w", err)
+	}
+	defer rows.Close()
+
+	keys := make(map[string]bool)
+	for rows.Next() {
+		var t model.WatchTarget
+		if err := rows.Scan(&t.EntityType, &t.TitleNumber, &t.AgencySlug, &t.Section); err != nil {
+			return nil, fmt.Errorf("failed to scan watched key: %w", err)
+		}
+		keys[t.Key()] = true
+	}
+
+	return keys, rows.Err()
+}
+
+// MarkSeen records the target's current state as seen by the user, if the
+// user watches it
+func (s *WatchlistStore) MarkSeen(ctx context.Context, userID string, t model.WatchTarget) error {
+	if err := s.markSeen(ctx, userID, t.Key()); err != nil {
+		return fmt.Errorf("failed to mark %s seen: %w", t.Key(), err)
+	}
+	return nil
+}
+
+// MarkAllSeen records the current state of everything the user watches as seen
+func (s *WatchlistStore) MarkAllSeen(ctx context.Context, userID string) error {
+	if err := s.markSeen(ctx, userID, ""); err != nil {
+		return fmt.Errorf("failed to mark watchlist seen: %w", err)
+	}
+	return nil
+}
+
+// markSeen stores the current state of the user's item with the given
+// target key as seen, or of every item when key is empty
+func (s *WatchlistStore) markSeen(ctx context.Context, userID, key string) error {
+	items, err := s.currentItems(ctx, userID)
+	if err != nil {
+		return err
+	}
+
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	query := `
+		UPDATE watchlist_items
+		SET seen_word_count = $2, seen_checksum = $3, seen_at = CURRENT_TIMESTAMP
+		WHERE id = $1
+	`
+	for _, i := range items {
+		if key != "" && i.Key() != key {
+			continue
+		}
+		if _, err := tx.ExecContext(ctx, query, i.ID, i.WordCount, i.Checksum); err != nil {
+			return err
+		}
+	}
+
+	return tx.Commit()
+}
@@ -0,0 +1,412 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/jjenkins/usds/internal/model"
+)
+
+// WebhookStore handles webhook subscriptions and their delivery queue
+type WebhookStore struct {
+	db *sql.DB
+}
+
+// NewWebhookStore creates a new WebhookStore
+func NewWebhookStore(db *sql.DB) *WebhookStore {
+	return &WebhookStore{db: db}
+}
+
+// CreateSubscription inserts a subscription and sets its ID. The agency is
+// given by AgencySlug and resolved to its ID.
+func (s *WebhookStore) CreateSubscription(ctx context.Context, sub *model.WebhookSubscription) error {
+	if sub.AgencySlug.Valid {
+		err := s.db.QueryRowContext(ctx, `SELECT id FROM agencies WHERE slug = $1`, sub.AgencySlug.String).Scan(&sub.AgencyID)
+		if err == sql.ErrNoRows {
+			return fmt.Errorf("agency %s not found", sub.AgencySlug.String)
+		}
+		if err != nil {
+			return fmt.Errorf("failed to get agency %s: %w", sub.AgencySlug.String, err)
+		}
+	}
+
+	query := `
+		INSERT INTO webhook_subscriptions (name, url, secret, title_number, agency_id, active)
+		VALUES ($1, $2, $3, $4, $5, $6)
+		RETURNING id, created_at
+	`
+
+	err := s.db.QueryRowContext(ctx, query,
+		sub.Name,
+		sub.URL,
+		sub.Secret,
+		sub.TitleNumber,
+		sub.AgencyID,
+		sub.Active,
+	).Scan(&sub.ID, &sub.CreatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to create webhook subscription: %w", err)
+	}
+
+	return nil
+}
+
+// ListSubscriptions returns all subscriptions, oldest first
+func (s *WebhookStore) ListSubscriptions(ctx context.Context) ([]model.WebhookSubscription, error) {
+	query := `
+		SELECT w.id, w.name, w.url, w.secret, w.title_number, w.agency_id, a.slug,
+		       w.active, w.created_at
+		FROM webhook_subscriptions w
+		LEFT JOIN agencies a ON a.id = w.agency_id
+		ORDER BY w.id
+	`
+
+	rows, err := s.db.QueryContext(ctx, query)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list webhook subscriptions: %w", err)
+	}
+	defer rows.Close()
+
+	var subs []model.WebhookSubscription
+	for rows.Next() {
+		var sub model.WebhookSubscription
+		err := rows.Scan(
+			&sub.ID,
+			&sub.Name,
+			&sub.URL,
+			&sub.Secret,
+			&sub.TitleNumber,
+			&sub.AgencyID,
+			&sub.AgencySlug,
+			&sub.Active,
+			&sub.CreatedAt,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan webhook subscription: %w", err)
+		}
+		subs = append(subs, sub)
+	}
+
+	return subs, rows.Err()
+}
+
+// SetSubscriptionActive pauses or resumes a subscription. Events recorded
+// while a subscription is paused are not queued for it.
+func (s *WebhookStore) SetSubscriptionActive(ctx context.Context, id int, active bool) error {
+	result, err := s.db.ExecContext(ctx, `UPDATE webhook_subscriptions SET active = $2 WHERE id = $1`, id, active)
+	if err != nil {
+		return fmt.Errorf("failed to update webhook subscription %d: %w", id, err)
+	}
+	if n, _ := result.RowsAffected(); n == 0 {
+		return fmt.Errorf("webhook subscription %d not found", id)
+	}
+	return nil
+}
+
+// DeleteSubscription removes a subscription and its delivery log
+func (s *WebhookStore) DeleteSubscription(ctx context.Context, id int) error {
+	result, err := s.db.ExecContext(ctx, `DELETE FROM webhook_subscriptions WHERE id = $1`, id)
+	if err != nil {
+		return fmt.Errorf("failed to delete webhook subscription %d: %w", id, err)
+	}
+	if n, _ := result.RowsAffected(); n == 0 {
+		return fmt.Errorf("webhook subscription %d not found", id)
+	}
+	return nil
+}
+
+// deliveryColumns selects a delivery joined as "d" with its subscription "w",
+// change event "e" and the event's agency "a"
+const deliveryColumns = `
+	d.id, d.subscription_id, w.name, w.url, w.secret,
+	e.id, e.entity_type, e.title_number, e.agency_id, a.slug, e.entity_name,
+	e.snapshot_date, e.previous_snapshot_date, e.previous_word_count,
+	e.word_count, e.checksum, e.created_at,
+	d.status, d.attempts, d.next_attempt_at, d.last_status_code, d.last_error,
+	d.delivered_at, d.created_at, d.updated_at
+`
+
+// ClaimDueDeliveries returns up to limit pending deliveries whose next attempt
+// is due, pushing their next attempt back by lease so that concurrent workers
+// skip them while they are in flight
+func (s *WebhookStore) ClaimDueDeliveries(ctx context.Context, limit int, lease time.Duration) ([]model.WebhookDelivery, error) {
+	if isSQLite(s.db) {
+		return s.claimDueDeliveriesSQLite(ctx, limit, lease)
+	}
+
+	query := fmt.Sprintf(`
+		WITH claimed AS (
+			UPDATE webhook_deliveries
+			SET next_attempt_at = CURRENT_TIMESTAMP + make_interval(secs => $2)
+			WHERE id IN (
+				SELECT d.id FROM webhook_deliveries d
+				INNER JOIN webhook_subscriptions w ON w.id = d.subscription_id
+				WHERE d.status = 'pending' AND d.next_attempt_at <= CURRENT_TIMESTAMP AND w.active
+				ORDER BY d.next_attempt_at, d.id
+				LIMIT $1
+				FOR UPDATE OF d SKIP LOCKED
+			)
+			RETURNING *
+		)
+		SELECT %s
+		FROM claimed d
+		INNER JOIN webhook_subscriptions w ON w.id = d.subscription_id
+		INNER JOIN change_events e ON e.id = d.change_event_id
+		LEFT JOIN agencies a ON a.id = e.agency_id
+		ORDER BY d.id
+	`, deliveryColumns)
+
+	return s.queryDeliveries(ctx, s.db, query, limit, lease.Seconds())
+}
+
+// claimDueDeliveriesSQLite is ClaimDueDeliveries for SQLite, which cannot
+// update in a WITH clause or skip locked rows. It needs neither: the
+// transaction holds SQLite's single write lock from its start, so claims
+// cannot interleave. datetime() compares timestamps written with any UTC
+// offset.
+func (s *WebhookStore) claimDueDeliveriesSQLite(ctx context.Context, limit int, lease time.Duration) ([]model.WebhookDelivery, error) {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	claim := `
+		UPDATE webhook_deliveries
+		SET next_attempt_at = datetime('now', '+' || $2 || ' seconds')
+		WHERE id IN (
+			SELECT d.id FROM webhook_deliveries d
+			INNER JOIN webhook_subscriptions w ON w.id = d.subscription_id
+			WHERE d.status = 'pending' AND datetime(d.next_attempt_at) <= datetime('now') AND w.active
+			ORDER BY datetime(d.next_attempt_at), d.id
+			LIMIT $1
+		)
+		RETURNING id
+	`
+	rows, err := tx.QueryContext(ctx, claim, limit, int(lease.Seconds()))
+	if err != nil {
+		return nil, fmt.Errorf("failed to claim webhook deliveries: %w", err)
+	}
+	var ids []string
+	for rows.Next() {
+		var id int
+		if err := rows.Scan(&id); err != nil {
+			rows.Close()
+			return nil, fmt.Errorf("failed to scan claimed webhook delivery: %w", err)
+		}
+		ids = append(ids, strconv.Itoa(id))
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to claim webhook deliveries: %w", err)
+	}
+	if len(ids) == 0 {
+		return nil, tx.Commit()
+	}
+
+	query := fmt.Sprintf(`
+		SELECT %s
+		FROM webhook_deliveries d
+		INNER JOIN webhook_subscriptions w ON w.id = d.subscription_id
+		INNER JOIN change_events e ON e.id = d.change_event_id
+		LEFT JOIN agencies a ON a.id = e.agency_id
+		WHERE d.id IN (%s)
+		ORDER BY d.id
+	`, deliveryColumns, strings.Join(ids, ", "))
+
+	deliveries, err := s.queryDeliveries(ctx, tx, query)
+	if err != nil {
+		return nil, err
+	}
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+	return deliveries, nil
+}
+
+// DeliveryFilter narrows the delivery log
+type DeliveryFilter struct {
+	Status         string // Empty for every status
+	SubscriptionID int    // Zero for every subscription
+	Limit          int
+}
+
+// ListDeliveries returns the delivery log, most recently updated first
+func (s *WebhookStore) ListDeliveries(ctx context.Context, f DeliveryFilter) ([]model.WebhookDelivery, error) {
+	query := fmt.Sprintf(`
+		SELECT %s
+		FROM webhook_deliveries d
+		INNER JOIN webhook_subscriptions w ON w.id = d.subscription_id
+		INNER JOIN change_events e ON e.id = d.change_event_id
+		LEFT JOIN agencies a ON a.id = e.agency_id
+		WHERE ($1 = '' OR d.status = $1)
+		  AND ($2 = 0 OR d.subscription_id = $2)
+		ORDER BY d.updated_at DESC, d.id DESC
+		LIMIT $3
+	`, deliveryColumns)
+
+	return s.queryDeliveries(ctx, s.db, query, f.Status, f.SubscriptionID, f.Limit)
+}
+
+// CountDeliveriesByStatus returns the number of deliveries in each status
+func (s *WebhookStore) CountDeliveriesByStatus(ctx context.Context) (map[string]int, error) {
+	rows, err := s.db.QueryContext(ctx, `SELECT status, COUNT(*) FROM webhook_deliveries GROUP BY status`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to count webhook deliveries: %w", err)
+	}
+	defer rows.Close()
+
+	counts := make(map[string]int)
+	for rows.Next() {
+		var status string
+		var count int
+		if err := rows.Scan(&status, &count); err != nil {
+			return nil, fmt.Errorf("failed to scan webhook delivery count: %w", err)
+		}
+		counts[status] = count
+	}
+
+	return counts, rows.Err()
+}
+
+func (s *WebhookStore) queryDeliveries(ctx context.Context, db dbtx, query string, args ...any) ([]model.WebhookDelivery, error) {
+	rows, err := db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query webhook deliveries: %w", err)
+	}
+	defer rows.Close()
+
+	var deliveries []model.WebhookDelivery
+	for rows.Next() {
+		var d model.WebhookDelivery
+		err := rows.Scan(
+			&d.ID,
+			&d.SubscriptionID,
+			&d.SubscriptionName,
+			&d.URL,
+			&d.Secret,
+			&d.Event.ID,
+			&d.Event.EntityType,
+			&d.Event.TitleNumber,
+			&d.Event.AgencyID,
+			&d.Event.AgencySlug,
+			&d.Event.EntityName,
+			&d.Event.SnapshotDate,
+			&d.Event.PreviousSnapshotDate,
+			&d.Event.PreviousWordCount,
+			&d.Event.WordCount,
+			&d.Event.Checksum,
+			&d.Event.CreatedAt,
+			&d.Status,
+			&d.Attempts,
+			&d.NextAttemptAt,
+			&d.LastStatusCode,
+			&d.LastError,
+			&d.DeliveredAt,
+			&d.CreatedAt,
+			&d.UpdatedAt,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan webhook delivery: %w", err)
+		}
+		deliveries = append(deliveries, d)
+	}
+
+	return deliveries, rows.Err()
+}
+
+// MarkDelivered records a successful delivery attempt
+func (s *WebhookStore) MarkDelivered(ctx context.Context, id int, statusCode int) error {
+	query := `
+		UPDATE webhook_deliveries
+		SET status = 'delivered', attempts = attempts + 1, last_status_code = $2,
+		    last_error = NULL, delivered_at = CURRENT_TIMESTAMP, updated_at = CURRENT_TIMESTAMP
+		WHERE id = $1
+	`
+	if _, err := s.db.ExecContext(ctx, query, id, statusCode); err != nil {
+		return fmt.Errorf("failed to mark webhook delivery %d delivered: %w", id, err)
+	}
+	return nil
+}
+
+// MarkFailed records a failed delivery attempt. The delivery is retried
+// retryIn from now, or moved to the dead-letter state if retryIn is zero.
+// The time is computed by the database, so retries are due on the same clock
+// ClaimDueDeliveries compares them with. statusCode is zero when no response
+// was received.
+func (s *WebhookStore) MarkFailed(ctx context.Context, id int, statusCode int, errMsg string, retryIn time.Duration) error {
+	status := model.DeliveryPending
+	if retryIn <= 0 {
+		status = model.DeliveryDead
+		retryIn = 0
+	}
+
+	nextAttempt, delay := "CURRENT_TIMESTAMP + make_interval(secs => $5)", any(retryIn.Seconds())
+	if isSQLite(s.db) {
+		nextAttempt, delay = "datetime('now', '+' || $5 || ' seconds')", int(retryIn.Seconds())
+	}
+	query := fmt.Sprintf(`
+		UPDATE webhook_deliveries
+		SET status = $2, attempts = attempts + 1, last_status_code = NULLIF($3, 0),
+		    last_error = $4, next_attempt_at = %s, updated_at = CURRENT_TIMESTAMP
+		WHERE id = $1
+	`, nextAttempt)
+	if _, err := s.db.ExecContext(ctx, query, id, status, statusCode, errMsg, delay); err != nil {
+		return fmt.Errorf("failed to mark webhook delivery %d failed: %w", id, err)
+	}
+	return nil
+}
+
+// RetryDelivery moves a dead-lettered delivery back onto the queue with a
+// fresh set of attempts
+func (s *WebhookStore) RetryDelivery(ctx context.Context, id int) error {
+	query := `
+		UPDATE webhook_deliveries
+		SET status = 'pending', attempts = 0, next_attempt_at = CURRENT_TIMESTAMP, updated_at = CURRENT_TIMESTAMP
+		WHERE id = $1 AND status = 'dead'
+	`
+	result, err := s.db.ExecContext(ctx, query, id)
+	if err != nil {
+		return fmt.Errorf("failed to retry webhook delivery %d: %w", id, err)
+	}
+	if n, _ := result.RowsAffected(); n == 0 {
+		return fmt.Errorf("webhook delivery %d not found or not dead", id)
+	}
+	return nil
+}
+
+// enqueueWebhookDeliveries queues a change event for every active
+// subscription it matches. Title subscriptions match their title's events;
+// agency subscriptions match the agency's events and those of its titles.
+// An event re-recorded with new content is queued afresh, even where its
+// earlier content was delivered or dead-lettered.
+func enqueueWebhookDeliveries(ctx context.Context, db dbtx, eventID int) error {
+	query := `
+		INSERT INTO webhook_deliveries (subscription_id, change_event_id)
+		SELECT w.id, e.id
+		FROM webhook_subscriptions w
+		CROSS JOIN change_events e
+		WHERE e.id = $1 AND w.active
+		  AND (
+			(w.title_number IS NULL AND w.agency_id IS NULL)
+			OR w.title_number = e.title_number
+			OR w.agency_id = e.agency_id
+			OR EXISTS (
+				SELECT 1 FROM agency_titles at
+				WHERE at.agency_id = w.agency_id AND at.title_number = e.title_number
+			)
+		  )
+		ON CONFLICT (subscription_id, change_event_id) DO UPDATE SET
+			status = 'pending', attempts = 0, next_attempt_at = CURRENT_TIMESTAMP,
+			last_status_code = NULL, last_error = NULL, delivered_at = NULL,
+			updated_at = CURRENT_TIMESTAMP
+	`
+	if _, err := db.ExecContext(ctx, query, eventID); err != nil {
+		return fmt.Errorf("failed to queue webhook deliveries for change event %d: %w", eventID, err)
+	}
+	return nil
+}
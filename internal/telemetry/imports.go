@@ -0,0 +1,71 @@
+package telemetry
+
+import (
+	"context"
+	"time"
+
+	"github.com/jjenkins/usds/internal/store"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// importQueryTimeout bounds the queries behind each scrape
+const importQueryTimeout = 5 * time.Second
+
+var (
+	lastImportDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, "import", "last_success_timestamp_seconds"),
+		"Unix time the most recent successful import finished, from any source.",
+		nil, nil,
+	)
+	titleCountDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, "", "titles"),
+		"Number of CFR titles stored.",
+		nil, nil,
+	)
+	scrapeErrorDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, "import", "scrape_error"),
+		"1 if the import gauges could not be read from the database.",
+		nil, nil,
+	)
+)
+
+// importCollector reads the import gauges from the database on each scrape,
+// so they cover runs by "usds import" and the daemon as well as this process
+type importCollector struct {
+	runStore   *store.ImportRunStore
+	titleStore store.TitleRepository
+}
+
+// RegisterImports exposes the last successful import time and title count
+func RegisterImports(runStore *store.ImportRunStore, titleStore store.TitleRepository) {
+	Registry.MustRegister(&importCollector{runStore: runStore, titleStore: titleStore})
+}
+
+func (c *importCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- lastImportDesc
+	ch <- titleCountDesc
+	ch <- scrapeErrorDesc
+}
+
+func (c *importCollector) Collect(ch chan<- prometheus.Metric) {
+	ctx, cancel := context.WithTimeout(context.Background(), importQueryTimeout)
+	defer cancel()
+
+	failed := 0.0
+
+	lastImport, err := c.runStore.LastSucceededAt(ctx)
+	if err != nil {
+		failed = 1
+	} else if lastImport.Valid {
+		ch <- prometheus.MustNewConstMetric(lastImportDesc, prometheus.GaugeValue, float64(lastImport.Time.Unix()))
+	}
+
+	count, err := c.titleStore.CountTitles(ctx)
+	if err != nil {
+		failed = 1
+	} else {
+		ch <- prometheus.MustNewConstMetric(titleCountDesc, prometheus.GaugeValue, float64(count))
+	}
+
+	ch <- prometheus.MustNewConstMetric(scrapeErrorDesc, prometheus.GaugeValue, failed)
+}
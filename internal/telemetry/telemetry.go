@@ -0,0 +1,125 @@
+package telemetry
+
+import (
+	"database/sql"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/gofiber/fiber/v2/middleware/adaptor"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+const namespace = "usds"
+
+// Registry holds every metric the server and importer expose, along with Go
+// runtime and process metrics
+var Registry = prometheus.NewRegistry()
+
+var (
+	httpRequestDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "http_request_duration_seconds",
+		Help:      "Time taken to serve HTTP requests by route.",
+		Buckets:   prometheus.DefBuckets,
+	}, []string{"method", "route", "status"})
+
+	ecfrFetchDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "ecfr_fetch_duration_seconds",
+		Help:      "Time taken by each eCFR API request attempt by endpoint.",
+		Buckets:   []float64{0.1, 0.25, 0.5, 1, 2.5, 5, 10, 30, 60, 120},
+	}, []string{"endpoint"})
+
+	ecfrRetries = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "ecfr_fetch_retries_total",
+		Help:      "eCFR API request attempts that failed and were retried, by endpoint and reason.",
+	}, []string{"endpoint", "reason"})
+
+	ecfrErrors = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "ecfr_fetch_errors_total",
+		Help:      "eCFR API requests that failed after every retry, by endpoint and reason of the last attempt.",
+	}, []string{"endpoint", "reason"})
+)
+
+// Reasons an eCFR API request attempt fails
+const (
+	ReasonTransport   = "transport"    // The request could not be sent or its body read
+	ReasonRateLimited = "rate_limited" // HTTP 429
+	ReasonStatus      = "status"       // Any other non-200 status
+)
+
+func init() {
+	Registry.MustRegister(
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+		httpRequestDuration,
+		ecfrFetchDuration,
+		ecfrRetries,
+		ecfrErrors,
+	)
+}
+
+// RegisterDB exposes the connection pool statistics from db.Stats()
+func RegisterDB(db *sql.DB) {
+	Registry.MustRegister(collectors.NewDBStatsCollector(db, namespace))
+}
+
+// ObserveFetch records how long one eCFR API request attempt took
+func ObserveFetch(endpoint string, d time.Duration) {
+	ecfrFetchDuration.WithLabelValues(endpoint).Observe(d.Seconds())
+}
+
+// CountRetry records an eCFR API request attempt that will be retried
+func CountRetry(endpoint, reason string) {
+	ecfrRetries.WithLabelValues(endpoint, reason).Inc()
+}
+
+// CountError records an eCFR API request that failed after every retry
+func CountError(endpoint, reason string) {
+	ecfrErrors.WithLabelValues(endpoint, reason).Inc()
+}
+
+// Middleware records the duration of each request under the route pattern
+// that served it, such as "/titles/:number", so label values stay bounded.
+// Requests no route matched are recorded under "unmatched".
+func Middleware() fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		start := time.Now()
+		err := c.Next()
+
+		status := c.Response().StatusCode()
+		if err != nil {
+			// The app's error handler has not written the response yet
+			status = fiber.StatusInternalServerError
+			if e, ok := err.(*fiber.Error); ok {
+				status = e.Code
+			}
+		}
+
+		// Without a matching route the request ends in the last middleware,
+		// which app.Use mounted at "/"
+		route := c.Route().Path
+		if route == "/" && c.Path() != "/" {
+			route = "unmatched"
+		}
+
+		httpRequestDuration.WithLabelValues(c.Method(), route, strconv.Itoa(status)).Observe(time.Since(start).Seconds())
+		return err
+	}
+}
+
+// HTTPHandler serves the metrics in the Prometheus text format
+func HTTPHandler() http.Handler {
+	return promhttp.HandlerFor(Registry, promhttp.HandlerOpts{})
+}
+
+// Handler serves the metrics from a Fiber app
+func Handler() fiber.Handler {
+	return adaptor.HTTPHandler(HTTPHandler())
+}